@@ -0,0 +1,56 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// protoc-gen-xservice-doc generates an API reference of the services of
+// proto packages in Markdown or HTML, e.g.
+// protoc --xservice-doc_out=format=html:. helloworld.proto
+package main
+
+import (
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/donutloop/xservice/generator/proto/doc"
+	"github.com/gogo/protobuf/proto"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+)
+
+func main() {
+	data, err := ioutil.ReadAll(os.Stdin)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	req := new(plugin.CodeGeneratorRequest)
+	if err = proto.Unmarshal(data, req); err != nil {
+		log.Fatal(err)
+	}
+
+	if len(req.FileToGenerate) == 0 {
+		log.Fatal("no files to generate")
+	}
+
+	resp, err := docproto.NewDocGenerator().Generate(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	data, err = proto.Marshal(resp)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err = os.Stdout.Write(data); err != nil {
+		log.Fatal(err)
+	}
+}
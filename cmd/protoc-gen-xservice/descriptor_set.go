@@ -0,0 +1,127 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GenerateDescriptorSet generates the files of the serialized
+// FileDescriptorSet at path, e.g. written by protoc's --descriptor_set_out
+// with --include_imports, and writes them below dir. This allows
+// regenerating code without protoc.
+//
+// files are the comma separated names of the files to generate. If it is
+// empty, the files which aren't imported by other files of the set are
+// generated. Existing scaffolds aren't overwritten.
+func GenerateDescriptorSet(g Generator, path, files, parameter, dir string) error {
+	req, err := readDescriptorSet(path, files, parameter)
+	if err != nil {
+		return err
+	}
+
+	resp, err := g.Generate(req)
+	if err != nil {
+		return err
+	}
+	if resp.Error != nil {
+		return errors.New(resp.GetError())
+	}
+
+	s, isScaffolder := g.(scaffolder)
+	for _, f := range resp.File {
+		name := filepath.Join(dir, filepath.FromSlash(f.GetName()))
+		if isScaffolder && s.IsScaffold(f.GetName()) {
+			if _, err := os.Stat(name); err == nil {
+				continue
+			}
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return errors.Wrapf(err, "could not create directory of %s", name)
+		}
+		if err := ioutil.WriteFile(name, []byte(f.GetContent()), 0644); err != nil {
+			return errors.Wrapf(err, "could not write %s", name)
+		}
+	}
+	return nil
+}
+
+// scaffolder is implemented by generators of scaffolds, files which are
+// generated once and then edited by hand.
+type scaffolder interface {
+	IsScaffold(name string) bool
+}
+
+// readDescriptorSet builds the CodeGeneratorRequest protoc would send for the
+// files of a descriptor set.
+func readDescriptorSet(path, files, parameter string) (*plugin.CodeGeneratorRequest, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read descriptor set")
+	}
+
+	set := new(descriptor.FileDescriptorSet)
+	if err := proto.Unmarshal(data, set); err != nil {
+		return nil, errors.Wrapf(err, "could not unmarshal descriptor set %s", path)
+	}
+
+	req := &plugin.CodeGeneratorRequest{
+		ProtoFile: set.File,
+	}
+	if parameter != "" {
+		req.Parameter = proto.String(parameter)
+	}
+
+	if files != "" {
+		for _, name := range strings.Split(files, ",") {
+			if !containsFile(set.File, name) {
+				return nil, errors.Errorf("file %s is not in descriptor set %s", name, path)
+			}
+			req.FileToGenerate = append(req.FileToGenerate, name)
+		}
+		return req, nil
+	}
+
+	imported := make(map[string]bool)
+	for _, f := range set.File {
+		for _, dep := range f.Dependency {
+			imported[dep] = true
+		}
+	}
+	for _, f := range set.File {
+		if !imported[f.GetName()] {
+			req.FileToGenerate = append(req.FileToGenerate, f.GetName())
+		}
+	}
+	if len(req.FileToGenerate) == 0 {
+		return nil, errors.Errorf("no files to generate in descriptor set %s", path)
+	}
+	return req, nil
+}
+
+func containsFile(files []*descriptor.FileDescriptorProto, name string) bool {
+	for _, f := range files {
+		if f.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
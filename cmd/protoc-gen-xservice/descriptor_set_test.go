@@ -0,0 +1,134 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/donutloop/xservice/generator/proto/go"
+	"github.com/golang/protobuf/proto"
+)
+
+const testDescriptorSet = "../../internal/xproto/typesmap/testdata/fileset.pb"
+
+const multiPackageDir = "../../integration_tests/api_multi_package"
+
+// multiPackageParameter are the parameters of the go:generate line of
+// integration_tests/api_multi_package.
+const multiPackageParameter = "Mgoogle/protobuf/empty.proto=github.com/gogo/protobuf/types," +
+	"Mgoogle/protobuf/timestamp.proto=github.com/gogo/protobuf/types," +
+	"Mshared/common/common.proto=github.com/donutloop/xservice/integration_tests/api_multi_package/shared/common"
+
+func TestGenerateDescriptorSet(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xservice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := GenerateDescriptorSet(goproto.NewAPIGenerator(), filepath.Join(multiPackageDir, "fileset.pb"), "", multiPackageParameter, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ioutil.ReadFile(filepath.Join(dir, "multi.proto.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ioutil.ReadFile(filepath.Join(multiPackageDir, "multi.proto.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != string(want) {
+		t.Errorf("generated code differs from %s:\n%s", filepath.Join(multiPackageDir, "multi.proto.go"), got)
+	}
+}
+
+func TestGenerateDescriptorSet_KeepsScaffolds(t *testing.T) {
+	dir, err := ioutil.TempDir("", "xservice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	scaffold := filepath.Join(dir, "cmd", "multi", "main.go")
+	if err := GenerateDescriptorSet(goproto.NewAPIGenerator(), filepath.Join(multiPackageDir, "fileset.pb"), "", multiPackageParameter+",scaffold=true", dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(scaffold); err != nil {
+		t.Fatalf("scaffold wasn't generated: %v", err)
+	}
+
+	// Edited scaffolds are kept, generated code is regenerated.
+	if err := ioutil.WriteFile(scaffold, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(dir, "multi.proto.go")); err != nil {
+		t.Fatal(err)
+	}
+	if err := GenerateDescriptorSet(goproto.NewAPIGenerator(), filepath.Join(multiPackageDir, "fileset.pb"), "", multiPackageParameter+",scaffold=true", dir); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ioutil.ReadFile(scaffold); err != nil || string(got) != "package main\n" {
+		t.Errorf("scaffold was overwritten: %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "multi.proto.go")); err != nil {
+		t.Errorf("generated code wasn't regenerated: %v", err)
+	}
+}
+
+func TestReadDescriptorSet(t *testing.T) {
+	req, err := readDescriptorSet(testDescriptorSet, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.FileToGenerate; len(got) != 1 || got[0] != "service.proto" {
+		t.Errorf("unexpected files to generate: %v", got)
+	}
+	if req.Parameter != nil {
+		t.Errorf("unexpected parameter: %q", req.GetParameter())
+	}
+
+	req, err = readDescriptorSet(testDescriptorSet, "root_pkg.proto,importer.proto", "messages=true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := req.FileToGenerate; len(got) != 2 || got[0] != "root_pkg.proto" || got[1] != "importer.proto" {
+		t.Errorf("unexpected files to generate: %v", got)
+	}
+	if req.GetParameter() != "messages=true" {
+		t.Errorf("unexpected parameter: %q", req.GetParameter())
+	}
+
+	if _, err := readDescriptorSet(testDescriptorSet, "missing.proto", ""); err == nil {
+		t.Error("expected an error for a file which isn't in the descriptor set")
+	}
+}
+
+func TestGeneratePluginResponse_RejectsScaffolds(t *testing.T) {
+	req, err := readDescriptorSet(filepath.Join(multiPackageDir, "fileset.pb"), "", multiPackageParameter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := generatePluginResponse(goproto.NewAPIGenerator(), req); err != nil {
+		t.Fatal(err)
+	}
+
+	req.Parameter = proto.String(multiPackageParameter + ",scaffold=true")
+	if _, err := generatePluginResponse(goproto.NewAPIGenerator(), req); err == nil {
+		t.Fatal("scaffold was generated for protoc")
+	}
+}
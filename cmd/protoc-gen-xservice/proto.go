@@ -18,6 +18,7 @@
 package main
 
 import (
+	"flag"
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
 	"io"
 	"io/ioutil"
@@ -25,11 +26,26 @@ import (
 
 	"github.com/donutloop/xservice/generator/proto/go"
 	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
 	"log"
 )
 
+var (
+	descriptorSet = flag.String("descriptor_set", "", "generate the files of a serialized FileDescriptorSet instead of reading a CodeGeneratorRequest from stdin")
+	out           = flag.String("out", ".", "directory of the generated files of -descriptor_set")
+	files         = flag.String("files", "", "comma separated names of the files of -descriptor_set to generate, by default those not imported by other files")
+	parameter     = flag.String("parameter", "", "parameters of the generator for -descriptor_set, e.g. messages=true,path_prefix=/api")
+)
+
 func main() {
+	flag.Parse()
 	g := goproto.NewAPIGenerator()
+	if *descriptorSet != "" {
+		if err := GenerateDescriptorSet(g, *descriptorSet, *files, *parameter, *out); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
 	Main(g)
 }
 
@@ -39,13 +55,30 @@ type Generator interface {
 
 func Main(g Generator) {
 	req := readGenRequest(os.Stdin)
-	resp, err := g.Generate(req)
+	resp, err := generatePluginResponse(g, req)
 	if err != nil {
 		log.Fatal(err)
 	}
 	writeResponse(os.Stdout, resp)
 }
 
+// generatePluginResponse generates the response to protoc. Scaffolds are
+// rejected, protoc would overwrite the edited scaffolds of earlier runs.
+func generatePluginResponse(g Generator, req *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
+	resp, err := g.Generate(req)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := g.(scaffolder); ok {
+		for _, f := range resp.File {
+			if s.IsScaffold(f.GetName()) {
+				return nil, errors.Errorf("scaffold %s: the scaffold parameter is only supported with -descriptor_set, which keeps existing scaffolds", f.GetName())
+			}
+		}
+	}
+	return resp, nil
+}
+
 func readGenRequest(r io.Reader) *plugin.CodeGeneratorRequest {
 	data, err := ioutil.ReadAll(os.Stdin)
 	if err != nil {
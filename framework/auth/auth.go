@@ -0,0 +1,124 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package auth authenticates requests of generated servers and injects
+// credentials into requests of generated clients.
+//
+// On the server side NewServerHooks runs a list of authenticators in the
+// RequestReceived hook. The first authenticator that finds its kind of
+// credentials on the request decides: either it stores a *Principal in the
+// context or the request fails with errors.Unauthenticated. With Anonymous
+// as the last authenticator, requests without credentials pass without a
+// principal and NewPolicyHooks decides which methods they may call.
+//
+// On the client side NewClient wraps the transport.HTTPClient passed to a
+// generated client constructor and adds credentials to every request.
+package auth
+
+import (
+	"context"
+	stderrors "errors"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// ErrNoCredentials is returned by an Authenticator if the request doesn't
+// carry its kind of credentials, so the next authenticator should be tried.
+var ErrNoCredentials = stderrors.New("no credentials")
+
+// Authenticator verifies the credentials of an incoming request.
+type Authenticator interface {
+	// Authenticate returns the principal the request was made by. It returns
+	// ErrNoCredentials if the request carries no credentials it understands
+	// and any other error if the credentials are invalid.
+	Authenticate(ctx context.Context, req *http.Request) (*Principal, error)
+}
+
+// Anonymous accepts every request without a principal. Put it last in the
+// authenticators of NewServerHooks to serve requests without credentials,
+// invalid credentials are still rejected by the authenticators before it.
+var Anonymous Authenticator = AuthenticatorFunc(func(ctx context.Context, req *http.Request) (*Principal, error) {
+	return nil, nil
+})
+
+// Challenger is implemented by authenticators of HTTP authentication
+// schemes. Rejected requests carry their challenges in the WWW-Authenticate
+// header of the response.
+type Challenger interface {
+	// Challenge returns the challenge of the scheme, e.g. "Bearer".
+	Challenge() string
+}
+
+// AuthenticatorFunc is an adapter to allow the use of ordinary functions as
+// Authenticator.
+type AuthenticatorFunc func(ctx context.Context, req *http.Request) (*Principal, error)
+
+// Authenticate calls f(ctx, req).
+func (f AuthenticatorFunc) Authenticate(ctx context.Context, req *http.Request) (*Principal, error) {
+	return f(ctx, req)
+}
+
+// NewServerHooks creates a *hooks.ServerHooks which authenticates every
+// request in the RequestReceived hook with the first of the authenticators
+// that finds credentials on the request. Requests without credentials or
+// with invalid credentials fail with errors.Unauthenticated. Clients only
+// learn that credentials are missing or invalid, why invalid credentials
+// were rejected is logged. An authenticator which returns a nil principal
+// without error, like Anonymous, lets the request pass unauthenticated.
+func NewServerHooks(authenticators ...Authenticator) *hooks.ServerHooks {
+	return &hooks.ServerHooks{
+		RequestReceived: func(ctx context.Context) (context.Context, error) {
+			req, ok := xcontext.HTTPRequest(ctx)
+			if !ok {
+				return ctx, errors.InternalError("auth: incoming request is missing in context")
+			}
+
+			for _, authenticator := range authenticators {
+				principal, err := authenticator.Authenticate(ctx, req)
+				if err == ErrNoCredentials {
+					continue
+				}
+				if err != nil {
+					log.Printf("auth: rejected credentials of %s %s: %v", req.Method, req.URL.Path, err)
+					return ctx, unauthenticated(ctx, "invalid credentials", authenticator)
+				}
+				if principal == nil {
+					return ctx, nil
+				}
+				return WithPrincipal(ctx, principal), nil
+			}
+
+			return ctx, unauthenticated(ctx, "missing credentials", authenticators...)
+		},
+	}
+}
+
+// unauthenticated returns the errors.Unauthenticated error of a request and
+// challenges the client with the schemes of authenticators.
+func unauthenticated(ctx context.Context, msg string, authenticators ...Authenticator) errors.Error {
+	var challenges []string
+	for _, authenticator := range authenticators {
+		if challenger, ok := authenticator.(Challenger); ok {
+			challenges = append(challenges, challenger.Challenge())
+		}
+	}
+	if len(challenges) > 0 {
+		// Ignoring the error is fine, it's only returned for reserved headers.
+		_ = xcontext.SetHTTPResponseHeader(ctx, "WWW-Authenticate", strings.Join(challenges, ", "))
+	}
+	return errors.NewError(errors.Unauthenticated, msg)
+}
@@ -0,0 +1,277 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func authenticate(t *testing.T, req *http.Request, authenticators ...Authenticator) (*Principal, error) {
+	ctx := context.Background()
+	ctx = xcontext.WithResponseWriter(ctx, httptest.NewRecorder())
+	ctx = xcontext.WithHTTPRequest(ctx, req)
+
+	ctx, err := NewServerHooks(authenticators...).RequestReceived(ctx)
+	if err != nil {
+		terr, ok := err.(errors.Error)
+		if !ok || terr.Code() != errors.Unauthenticated {
+			t.Fatalf("unexpected error (actual: %v, expected code: %s)", err, errors.Unauthenticated)
+		}
+		return nil, err
+	}
+
+	p, ok := PrincipalFromContext(ctx)
+	if !ok {
+		t.Fatal("principal is missing in context")
+	}
+	return p, nil
+}
+
+func TestAPIKeys(t *testing.T) {
+	keys := &APIKeys{Keys: map[string]*Principal{"secret": {Name: "alice"}}}
+
+	req := httptest.NewRequest("POST", "/", nil)
+	if _, err := authenticate(t, req, keys); err == nil {
+		t.Fatal("request without credentials was authenticated")
+	}
+
+	req.Header.Set(APIKeyHeader, "wrong")
+	if _, err := authenticate(t, req, keys); err == nil {
+		t.Fatal("request with invalid key was authenticated")
+	}
+
+	req.Header.Set(APIKeyHeader, "secret")
+	p, err := authenticate(t, req, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "alice" || p.Method != "api_key" {
+		t.Fatalf("unexpected principal (actual: %+v)", p)
+	}
+}
+
+func TestHMACBearer(t *testing.T) {
+	bearer := NewHMACBearer([]byte("shared secret"))
+	bearer.now = func() time.Time { return time.Unix(100, 0) }
+
+	token, err := bearer.Sign(Claims{Subject: "bob", Scopes: []string{"read"}, ExpiresAt: 200})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/", nil)
+	if err := BearerToken(token).Apply(req); err != nil {
+		t.Fatal(err)
+	}
+
+	// the api key authenticator finds no credentials and passes on
+	p, err := authenticate(t, req, &APIKeys{}, bearer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "bob" || !p.HasScope("read") || p.HasScope("write") {
+		t.Fatalf("unexpected principal (actual: %+v)", p)
+	}
+
+	forged := NewHMACBearer([]byte("other secret"))
+	token, _ = forged.Sign(Claims{Subject: "bob"})
+	BearerToken(token).Apply(req)
+	if _, err := authenticate(t, req, bearer); err == nil {
+		t.Fatal("token with invalid signature was authenticated")
+	}
+
+	bearer.now = func() time.Time { return time.Unix(200, 0) }
+	token, _ = bearer.Sign(Claims{Subject: "bob", ExpiresAt: 200})
+	BearerToken(token).Apply(req)
+	if _, err := authenticate(t, req, bearer); err == nil {
+		t.Fatal("expired token was authenticated")
+	}
+}
+
+func TestNewHMACBearer_EmptySecret(t *testing.T) {
+	for _, secret := range [][]byte{nil, {}} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("no panic for secret %q", secret)
+				}
+			}()
+			NewHMACBearer(secret)
+		}()
+	}
+}
+
+func TestAnonymous(t *testing.T) {
+	keys := &APIKeys{Keys: map[string]*Principal{"secret": {Name: "alice"}}}
+	policy := &Policy{
+		Package: "example",
+		Service: "Users",
+		Methods: map[string]Requirement{
+			"DeleteUser": {Roles: []string{"admin"}},
+		},
+	}
+	serverHooks := hooks.ChainHooks(NewServerHooks(keys, Anonymous), NewPolicyHooks(policy))
+
+	call := func(method string, header http.Header) error {
+		req := httptest.NewRequest("POST", "/", nil)
+		req.Header = header
+		ctx := context.Background()
+		ctx = xcontext.WithResponseWriter(ctx, httptest.NewRecorder())
+		ctx = xcontext.WithHTTPRequest(ctx, req)
+		ctx = xcontext.WithPackageName(ctx, "example")
+		ctx = xcontext.WithServiceName(ctx, "Users")
+		ctx = xcontext.WithMethodName(ctx, method)
+
+		ctx, err := serverHooks.RequestReceived(ctx)
+		if err != nil {
+			return err
+		}
+		if _, ok := PrincipalFromContext(ctx); ok {
+			t.Fatalf("%s: principal of anonymous request in context", method)
+		}
+		_, err = serverHooks.RequestRouted(ctx)
+		return err
+	}
+
+	if err := call("GetUser", http.Header{}); err != nil {
+		t.Fatalf("anonymous request of unrestricted method failed: %v", err)
+	}
+	if err := call("DeleteUser", http.Header{}); err == nil || err.(errors.Error).Code() != errors.Unauthenticated {
+		t.Fatalf("unexpected error of anonymous request of restricted method (actual: %v, expected code: %s)", err, errors.Unauthenticated)
+	}
+	invalid := http.Header{}
+	invalid.Set(APIKeyHeader, "wrong")
+	if err := call("GetUser", invalid); err == nil || err.(errors.Error).Code() != errors.Unauthenticated {
+		t.Fatalf("unexpected error of request with invalid key (actual: %v, expected code: %s)", err, errors.Unauthenticated)
+	}
+}
+
+func TestPolicyHooks(t *testing.T) {
+	policy := &Policy{
+		Package: "example",
+		Service: "Users",
+		Methods: map[string]Requirement{
+			"GetUser":    {},
+			"DeleteUser": {Scopes: []string{"users.write"}, Roles: []string{"admin", "owner"}},
+		},
+	}
+	serverHooks := NewPolicyHooks(policy)
+
+	route := func(method string, p *Principal) error {
+		ctx := context.Background()
+		ctx = xcontext.WithPackageName(ctx, "example")
+		ctx = xcontext.WithServiceName(ctx, "Users")
+		ctx = xcontext.WithMethodName(ctx, method)
+		if p != nil {
+			ctx = WithPrincipal(ctx, p)
+		}
+		_, err := serverHooks.RequestRouted(ctx)
+		return err
+	}
+
+	code := func(err error) errors.ErrorCode {
+		if err == nil {
+			return errors.NoError
+		}
+		return err.(errors.Error).Code()
+	}
+
+	tests := []struct {
+		method    string
+		principal *Principal
+		expected  errors.ErrorCode
+	}{
+		{"GetUser", nil, errors.NoError},
+		{"DeleteUser", nil, errors.Unauthenticated},
+		{"DeleteUser", &Principal{Name: "alice", Roles: []string{"admin"}}, errors.PermissionDenied},
+		{"DeleteUser", &Principal{Name: "alice", Scopes: []string{"users.write"}}, errors.PermissionDenied},
+		{"DeleteUser", &Principal{Name: "alice", Scopes: []string{"users.write"}, Roles: []string{"owner"}}, errors.NoError},
+	}
+
+	for _, test := range tests {
+		if actual := code(route(test.method, test.principal)); actual != test.expected {
+			t.Errorf("unexpected code for %s by %+v (actual: %q, expected: %q)", test.method, test.principal, actual, test.expected)
+		}
+	}
+}
+
+func TestChallenges(t *testing.T) {
+	keys := &APIKeys{Keys: map[string]*Principal{"secret": {Name: "alice"}}}
+	bearer := NewHMACBearer([]byte("shared secret"))
+
+	tests := []struct {
+		name           string
+		header         http.Header
+		authenticators []Authenticator
+		challenge      string
+		msg            string
+	}{
+		{
+			name:           "missing credentials",
+			authenticators: []Authenticator{keys, bearer, &TLSClientCert{}},
+			challenge:      `APIKey header="X-API-Key", Bearer`,
+			msg:            "missing credentials",
+		},
+		{
+			name:           "invalid api key",
+			header:         http.Header{APIKeyHeader: {"wrong"}},
+			authenticators: []Authenticator{keys, bearer},
+			challenge:      `APIKey header="X-API-Key"`,
+			msg:            "invalid credentials",
+		},
+		{
+			name:           "invalid bearer token",
+			header:         http.Header{"Authorization": {"Bearer forged"}},
+			authenticators: []Authenticator{keys, bearer},
+			challenge:      "Bearer",
+			msg:            "invalid credentials",
+		},
+		{
+			name:           "no challengers",
+			authenticators: []Authenticator{&TLSClientCert{}},
+			msg:            "missing credentials",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/", nil)
+			for k, v := range test.header {
+				req.Header.Set(k, v[0])
+			}
+			resp := httptest.NewRecorder()
+			ctx := xcontext.WithResponseWriter(context.Background(), resp)
+			ctx = xcontext.WithHTTPRequest(ctx, req)
+
+			_, err := NewServerHooks(test.authenticators...).RequestReceived(ctx)
+			terr, ok := err.(errors.Error)
+			if !ok || terr.Code() != errors.Unauthenticated {
+				t.Fatalf("unexpected error (actual: %v, expected code: %s)", err, errors.Unauthenticated)
+			}
+			if terr.Msg() != test.msg {
+				t.Fatalf(`unexpected message (actual: "%s", expected: "%s")`, terr.Msg(), test.msg)
+			}
+			if actual := resp.Header().Get("WWW-Authenticate"); actual != test.challenge {
+				t.Fatalf(`unexpected challenge (actual: "%s", expected: "%s")`, actual, test.challenge)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIKeyHeader is the default header API keys are sent in.
+const APIKeyHeader = "X-API-Key"
+
+// APIKeys authenticates requests by a static set of API keys.
+type APIKeys struct {
+	// Header the key is read from, defaults to APIKeyHeader.
+	Header string
+	// Keys maps API keys to the principal they belong to.
+	Keys map[string]*Principal
+}
+
+// Authenticate implements Authenticator.
+func (a *APIKeys) Authenticate(ctx context.Context, req *http.Request) (*Principal, error) {
+	key := req.Header.Get(a.header())
+	if key == "" {
+		return nil, ErrNoCredentials
+	}
+
+	// Compare every key in constant time, so the position of a key in the map
+	// doesn't leak through timing.
+	var found *Principal
+	for k, p := range a.Keys {
+		if subtle.ConstantTimeCompare([]byte(k), []byte(key)) == 1 {
+			found = p
+		}
+	}
+	if found == nil {
+		return nil, stderrors.New("invalid api key")
+	}
+
+	principal := *found
+	principal.Method = "api_key"
+	return &principal, nil
+}
+
+// Challenge implements Challenger, API keys have no standard scheme.
+func (a *APIKeys) Challenge() string {
+	return `APIKey header="` + a.header() + `"`
+}
+
+func (a *APIKeys) header() string {
+	if a.Header == "" {
+		return APIKeyHeader
+	}
+	return a.Header
+}
+
+// Claims are the signed content of a bearer token.
+type Claims struct {
+	Subject   string   `json:"sub"`
+	Scopes    []string `json:"scopes,omitempty"`
+	Roles     []string `json:"roles,omitempty"`
+	ExpiresAt int64    `json:"exp,omitempty"` // unix time in seconds, zero means no expiry
+}
+
+// HMACBearer authenticates requests by bearer tokens in the Authorization
+// header, which are signed with HMAC-SHA256 and a shared secret. A token
+// has the form base64url(json(claims)) + "." + base64url(signature).
+type HMACBearer struct {
+	secret []byte
+	now    func() time.Time
+}
+
+// NewHMACBearer creates a HMACBearer which signs and verifies tokens with
+// secret. It panics if secret is empty, anyone could sign tokens.
+func NewHMACBearer(secret []byte) *HMACBearer {
+	if len(secret) == 0 {
+		panic("auth: secret of a HMACBearer must not be empty")
+	}
+	return &HMACBearer{
+		secret: secret,
+		now:    time.Now,
+	}
+}
+
+// Sign creates a token for claims.
+func (a *HMACBearer) Sign(claims Claims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(a.sign(encoded)), nil
+}
+
+func (a *HMACBearer) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
+
+// Authenticate implements Authenticator.
+func (a *HMACBearer) Authenticate(ctx context.Context, req *http.Request) (*Principal, error) {
+	authorization := req.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(authorization) < len(prefix) || !strings.EqualFold(authorization[:len(prefix)], prefix) {
+		return nil, ErrNoCredentials
+	}
+	token := strings.TrimSpace(authorization[len(prefix):])
+
+	i := strings.IndexByte(token, '.')
+	if i < 0 {
+		return nil, stderrors.New("malformed bearer token")
+	}
+	payload, signature := token[:i], token[i+1:]
+
+	sig, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(sig, a.sign(payload)) {
+		return nil, stderrors.New("invalid bearer token signature")
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, stderrors.New("malformed bearer token")
+	}
+	var claims Claims
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, stderrors.New("malformed bearer token")
+	}
+
+	if claims.ExpiresAt != 0 && a.now().Unix() >= claims.ExpiresAt {
+		return nil, stderrors.New("bearer token expired")
+	}
+
+	return &Principal{
+		Name:   claims.Subject,
+		Method: "bearer",
+		Scopes: claims.Scopes,
+		Roles:  claims.Roles,
+	}, nil
+}
+
+// Challenge implements Challenger.
+func (a *HMACBearer) Challenge() string {
+	return "Bearer"
+}
+
+// TLSClientCert authenticates requests by the verified TLS client
+// certificate of the connection. The principal is named after the common
+// name of the certificate subject.
+//
+// The server has to verify client certificates, i.e. its tls.Config needs
+// ClientAuth set to tls.VerifyClientCertIfGiven or
+// tls.RequireAndVerifyClientCert, unverified certificates are ignored.
+type TLSClientCert struct {
+	// Roles optionally maps common names to the roles they are granted.
+	Roles map[string][]string
+}
+
+// Authenticate implements Authenticator.
+func (a *TLSClientCert) Authenticate(ctx context.Context, req *http.Request) (*Principal, error) {
+	if req.TLS == nil || len(req.TLS.VerifiedChains) == 0 || len(req.TLS.VerifiedChains[0]) == 0 {
+		return nil, ErrNoCredentials
+	}
+
+	subject := req.TLS.VerifiedChains[0][0].Subject
+	if subject.CommonName == "" {
+		return nil, stderrors.New("client certificate has no common name")
+	}
+
+	return &Principal{
+		Name:   subject.CommonName,
+		Method: "tls",
+		Roles:  a.Roles[subject.CommonName],
+	}, nil
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package auth
+
+import (
+	"github.com/donutloop/xservice/framework/transport"
+	"net/http"
+)
+
+// Credentials add authentication to an outgoing request.
+type Credentials interface {
+	Apply(req *http.Request) error
+}
+
+// CredentialsFunc is an adapter to allow the use of ordinary functions as
+// Credentials.
+type CredentialsFunc func(req *http.Request) error
+
+// Apply calls f(req).
+func (f CredentialsFunc) Apply(req *http.Request) error {
+	return f(req)
+}
+
+// BearerToken sends token in the Authorization header.
+func BearerToken(token string) Credentials {
+	return CredentialsFunc(func(req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// APIKey sends key in header, if header is empty APIKeyHeader is used.
+func APIKey(header, key string) Credentials {
+	if header == "" {
+		header = APIKeyHeader
+	}
+	return CredentialsFunc(func(req *http.Request) error {
+		req.Header.Set(header, key)
+		return nil
+	})
+}
+
+// NewClient wraps client, so credentials are added to every request. The
+// returned client can be passed to the constructors of generated clients:
+//
+//   client := pb.NewHelloWorldJSONClient(addr, auth.NewClient(&http.Client{}, auth.BearerToken(token)))
+//
+// TLS client certificates are configured on the tls.Config of the wrapped
+// *http.Client instead.
+func NewClient(client transport.HTTPClient, credentials Credentials) transport.HTTPClient {
+	// Generated constructors only disable redirects for *http.Client, so it
+	// has to happen before the client gets wrapped.
+	if httpClient, ok := client.(*http.Client); ok {
+		client = transport.WithoutRedirects(httpClient)
+	}
+	return &credentialsClient{
+		client:      client,
+		credentials: credentials,
+	}
+}
+
+type credentialsClient struct {
+	client      transport.HTTPClient
+	credentials Credentials
+}
+
+func (c *credentialsClient) Do(req *http.Request) (*http.Response, error) {
+	if err := c.credentials.Apply(req); err != nil {
+		return nil, err
+	}
+	return c.client.Do(req)
+}
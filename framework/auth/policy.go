@@ -0,0 +1,120 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package auth
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"strings"
+)
+
+// Requirement lists what a principal needs to call a method. A method
+// without scopes and roles can be called by anyone, even without being
+// authenticated if the server accepts requests without credentials, see
+// Anonymous.
+type Requirement struct {
+	// Scopes the principal needs to be granted all of.
+	Scopes []string
+	// Roles the principal needs to be granted at least one of.
+	Roles []string
+}
+
+// Policy maps the methods of a service to their requirements.
+//
+// protoc-gen-xservice generates a <Service>Policy for every service which
+// uses the authorization options of proto/xservice/options.proto.
+type Policy struct {
+	// Package is the protobuf package of the service.
+	Package string
+	// Service is the name of the service.
+	Service string
+	// Methods maps method names to their requirements.
+	Methods map[string]Requirement
+}
+
+// Check verifies that principal may call method. It returns
+// errors.Unauthenticated if the method has requirements but principal is
+// nil, and errors.PermissionDenied if a requirement isn't met.
+func (p *Policy) Check(principal *Principal, method string) error {
+	requirement, ok := p.Methods[method]
+	if !ok || (len(requirement.Scopes) == 0 && len(requirement.Roles) == 0) {
+		return nil
+	}
+
+	if principal == nil {
+		return errors.NewError(errors.Unauthenticated, "missing credentials")
+	}
+
+	for _, scope := range requirement.Scopes {
+		if !principal.HasScope(scope) {
+			err := errors.NewError(errors.PermissionDenied, "missing scope "+scope)
+			return err.WithMeta("missing_scope", scope)
+		}
+	}
+
+	if len(requirement.Roles) == 0 {
+		return nil
+	}
+	for _, role := range requirement.Roles {
+		if principal.HasRole(role) {
+			return nil
+		}
+	}
+	roles := strings.Join(requirement.Roles, ",")
+	err := errors.NewError(errors.PermissionDenied, "missing one of the roles "+roles)
+	return err.WithMeta("required_roles", roles)
+}
+
+// NewPolicyHooks creates a *hooks.ServerHooks which checks in the
+// RequestRouted hook, that the principal stored in the context by
+// NewServerHooks fulfills the requirements of the routed method. Methods of
+// services without a policy are not restricted.
+//
+// Chain it after the authentication hooks:
+//
+//   hooks.ChainHooks(auth.NewServerHooks(authenticators...), auth.NewPolicyHooks(pb.HelloWorldPolicy))
+//
+// Unauthenticated requests, e.g. accepted by Anonymous, fail with
+// errors.Unauthenticated for methods with requirements.
+func NewPolicyHooks(policies ...*Policy) *hooks.ServerHooks {
+	byService := make(map[string]*Policy, len(policies))
+	for _, p := range policies {
+		byService[qualifiedServiceName(p.Package, p.Service)] = p
+	}
+
+	return &hooks.ServerHooks{
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			pkg, _ := xcontext.PackageName(ctx)
+			service, _ := xcontext.ServiceName(ctx)
+			method, _ := xcontext.MethodName(ctx)
+
+			policy, ok := byService[qualifiedServiceName(pkg, service)]
+			if !ok {
+				return ctx, nil
+			}
+
+			principal, _ := PrincipalFromContext(ctx)
+			return ctx, policy.Check(principal, method)
+		},
+	}
+}
+
+func qualifiedServiceName(pkg, service string) string {
+	if pkg == "" {
+		return service
+	}
+	return pkg + "." + service
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package auth
+
+import "context"
+
+// Principal is the authenticated identity a request was made by.
+type Principal struct {
+	// Name identifies the principal, e.g. the owner of an API key, the
+	// subject of a bearer token or the common name of a client certificate.
+	Name string
+
+	// Method is the authentication method which identified the principal,
+	// like "api_key", "bearer" or "tls".
+	Method string
+
+	// Scopes and Roles granted to the principal.
+	Scopes []string
+	Roles  []string
+}
+
+// HasScope reports whether the principal was granted scope.
+func (p *Principal) HasScope(scope string) bool {
+	return contains(p.Scopes, scope)
+}
+
+// HasRole reports whether the principal was granted role.
+func (p *Principal) HasRole(role string) bool {
+	return contains(p.Roles, role)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// WithPrincipal stores the authenticated principal in a context.Context.
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext extracts the authenticated principal from the given
+// context. If the request wasn't authenticated, it returns (nil, false).
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
+// PrincipalName extracts the name of the authenticated principal from the
+// given context. It can be used as ratelimit.KeyFunc to limit requests per
+// principal.
+func PrincipalName(ctx context.Context) (string, bool) {
+	p, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return "", false
+	}
+	return p.Name, true
+}
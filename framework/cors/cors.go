@@ -0,0 +1,211 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package cors adds Cross-Origin Resource Sharing to generated servers, so
+// browsers can call them from other origins.
+//
+// Generated servers reject OPTIONS requests, the preflight requests of
+// browsers are therefore answered by the handler of this package before they
+// reach the server:
+//
+//   handler := cors.NewHandler(pb.NewHelloWorldServer(&HelloWorldServer{}, nil), cors.Config{
+//   	AllowedOrigins: []string{"https://app.example.com"},
+//   })
+package cors
+
+import (
+	"github.com/donutloop/xservice/framework/xhttp"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultAllowedHeaders are the request headers allowed if
+// Config.AllowedHeaders is empty. They cover the headers sent by generated
+// clients and the credentials of the auth package.
+var DefaultAllowedHeaders = []string{
+	"Accept",
+	"Authorization",
+	xhttp.ContentTypeHeader,
+	"X-API-Key",
+	xhttp.VersionHeader,
+}
+
+// Config configures the CORS handler.
+type Config struct {
+	// AllowedOrigins lists the origins which may call the server, e.g.
+	// "https://app.example.com". An origin may contain one wildcard, e.g.
+	// "https://*.example.com", a single "*" allows every origin.
+	AllowedOrigins []string
+	// AllowedHeaders lists the request headers browsers may send, defaults to
+	// DefaultAllowedHeaders.
+	AllowedHeaders []string
+	// ExposedHeaders lists the response headers browsers expose to scripts.
+	ExposedHeaders []string
+	// AllowCredentials allows browsers to send cookies and the Authorization
+	// header. The origin of the request is echoed instead of "*" then.
+	// Credentials are never allowed for origins which only match "*", every
+	// site could make credentialed calls otherwise.
+	AllowCredentials bool
+	// MaxAge is the duration browsers may cache the result of a preflight
+	// request, zero leaves it to the browser.
+	MaxAge time.Duration
+}
+
+// NewHandler wraps h, so responses carry CORS headers for allowed origins
+// and preflight requests are answered without calling h.
+func NewHandler(h http.Handler, config Config) http.Handler {
+	allowedHeaders := config.AllowedHeaders
+	if len(allowedHeaders) == 0 {
+		allowedHeaders = DefaultAllowedHeaders
+	}
+
+	c := &corsHandler{
+		next:             h,
+		allowedHeaders:   make(map[string]bool, len(allowedHeaders)),
+		allowHeaders:     strings.Join(allowedHeaders, ", "),
+		exposeHeaders:    strings.Join(config.ExposedHeaders, ", "),
+		allowCredentials: config.AllowCredentials,
+	}
+	for _, header := range allowedHeaders {
+		c.allowedHeaders[http.CanonicalHeaderKey(header)] = true
+	}
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			c.allowAll = true
+			continue
+		}
+		c.origins = append(c.origins, strings.ToLower(origin))
+	}
+	if config.MaxAge > 0 {
+		c.maxAge = strconv.Itoa(int(config.MaxAge / time.Second))
+	}
+	return c
+}
+
+var allowedMethods = map[string]bool{
+	http.MethodGet:    true,
+	http.MethodPost:   true,
+	http.MethodPut:    true,
+	http.MethodPatch:  true,
+	http.MethodDelete: true,
+}
+
+type corsHandler struct {
+	next             http.Handler
+	origins          []string
+	allowAll         bool
+	allowedHeaders   map[string]bool
+	allowHeaders     string
+	exposeHeaders    string
+	allowCredentials bool
+	maxAge           string
+}
+
+func (c *corsHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	origin := req.Header.Get("Origin")
+	preflight := req.Method == http.MethodOptions && req.Header.Get("Access-Control-Request-Method") != ""
+
+	// Responses depend on the origin, caches must not mix them up.
+	resp.Header().Add("Vary", "Origin")
+
+	allowed, explicit := c.originAllowed(origin)
+	if origin == "" || !allowed {
+		if preflight {
+			// The browser blocks the actual request, because the preflight
+			// response lacks the CORS headers.
+			resp.WriteHeader(http.StatusNoContent)
+			return
+		}
+		c.next.ServeHTTP(resp, req)
+		return
+	}
+
+	if preflight {
+		c.servePreflight(resp, req, origin, explicit)
+		return
+	}
+
+	c.setOrigin(resp, origin, explicit)
+	if c.exposeHeaders != "" {
+		resp.Header().Set("Access-Control-Expose-Headers", c.exposeHeaders)
+	}
+	c.next.ServeHTTP(resp, req)
+}
+
+func (c *corsHandler) servePreflight(resp http.ResponseWriter, req *http.Request, origin string, explicit bool) {
+	header := resp.Header()
+	header.Add("Vary", "Access-Control-Request-Method")
+	header.Add("Vary", "Access-Control-Request-Headers")
+
+	// Generated servers serve POST requests, GET requests for
+	// side-effect-free methods, and the methods of RESTful routes.
+	if !allowedMethods[req.Header.Get("Access-Control-Request-Method")] {
+		resp.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	for _, requested := range strings.Split(req.Header.Get("Access-Control-Request-Headers"), ",") {
+		requested = strings.TrimSpace(requested)
+		if requested != "" && !c.allowedHeaders[http.CanonicalHeaderKey(requested)] {
+			resp.WriteHeader(http.StatusNoContent)
+			return
+		}
+	}
+
+	c.setOrigin(resp, origin, explicit)
+	header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE")
+	header.Set("Access-Control-Allow-Headers", c.allowHeaders)
+	if c.maxAge != "" {
+		header.Set("Access-Control-Max-Age", c.maxAge)
+	}
+	resp.WriteHeader(http.StatusNoContent)
+}
+
+// setOrigin allows origin. Origins which only match "*" are allowed without
+// credentials.
+func (c *corsHandler) setOrigin(resp http.ResponseWriter, origin string, explicit bool) {
+	header := resp.Header()
+	if !explicit {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	if c.allowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+}
+
+// originAllowed reports whether origin is allowed, and whether it matches
+// one of AllowedOrigins other than "*".
+func (c *corsHandler) originAllowed(origin string) (allowed bool, explicit bool) {
+	lower := strings.ToLower(origin)
+	for _, pattern := range c.origins {
+		if matchOrigin(pattern, lower) {
+			return true, true
+		}
+	}
+	return c.allowAll, false
+}
+
+// matchOrigin reports whether origin matches pattern, which may contain one
+// wildcard.
+func matchOrigin(pattern, origin string) bool {
+	i := strings.IndexByte(pattern, '*')
+	if i < 0 {
+		return pattern == origin
+	}
+	prefix, suffix := pattern[:i], pattern[i+1:]
+	return len(origin) >= len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix)
+}
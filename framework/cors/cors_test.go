@@ -0,0 +1,119 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPreflight(t *testing.T) {
+	called := false
+	handler := NewHandler(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		called = true
+	}), Config{
+		AllowedOrigins:   []string{"https://*.example.com"},
+		AllowCredentials: true,
+		MaxAge:           time.Hour,
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/xservice/example.helloworld.HelloWorld/Hello", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "content-type, xservice-version")
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if called {
+		t.Fatal("preflight request was passed to the server")
+	}
+	if resp.Code != http.StatusNoContent {
+		t.Fatalf("unexpected status code (actual: %d, expected: %d)", resp.Code, http.StatusNoContent)
+	}
+	expected := map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST, PUT, PATCH, DELETE",
+		"Access-Control-Max-Age":           "3600",
+	}
+	for header, value := range expected {
+		if actual := resp.Header().Get(header); actual != value {
+			t.Errorf("unexpected %s header (actual: %q, expected: %q)", header, actual, value)
+		}
+	}
+
+	req.Header.Set("Origin", "https://evil.com")
+	resp = httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+	if resp.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatal("preflight request of a foreign origin was allowed")
+	}
+}
+
+func TestActualRequest(t *testing.T) {
+	handler := NewHandler(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.WriteHeader(http.StatusOK)
+	}), Config{AllowedOrigins: []string{"*"}})
+
+	req := httptest.NewRequest(http.MethodPost, "/", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("unexpected status code (actual: %d, expected: %d)", resp.Code, http.StatusOK)
+	}
+	if actual := resp.Header().Get("Access-Control-Allow-Origin"); actual != "*" {
+		t.Fatalf("unexpected Access-Control-Allow-Origin header (actual: %q, expected: %q)", actual, "*")
+	}
+}
+
+func TestWildcardWithCredentials(t *testing.T) {
+	handler := NewHandler(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.WriteHeader(http.StatusOK)
+	}), Config{
+		AllowedOrigins:   []string{"*", "https://app.example.com"},
+		AllowCredentials: true,
+	})
+
+	tests := []struct {
+		origin      string
+		allowOrigin string
+		credentials string
+	}{
+		{origin: "https://app.example.com", allowOrigin: "https://app.example.com", credentials: "true"},
+		{origin: "https://evil.com", allowOrigin: "*", credentials: ""},
+	}
+
+	for _, test := range tests {
+		for _, method := range []string{http.MethodPost, http.MethodOptions} {
+			req := httptest.NewRequest(method, "/", nil)
+			req.Header.Set("Origin", test.origin)
+			if method == http.MethodOptions {
+				req.Header.Set("Access-Control-Request-Method", "POST")
+			}
+			resp := httptest.NewRecorder()
+			handler.ServeHTTP(resp, req)
+
+			if actual := resp.Header().Get("Access-Control-Allow-Origin"); actual != test.allowOrigin {
+				t.Errorf("%s %s: unexpected Access-Control-Allow-Origin header (actual: %q, expected: %q)", method, test.origin, actual, test.allowOrigin)
+			}
+			if actual := resp.Header().Get("Access-Control-Allow-Credentials"); actual != test.credentials {
+				t.Errorf("%s %s: unexpected Access-Control-Allow-Credentials header (actual: %q, expected: %q)", method, test.origin, actual, test.credentials)
+			}
+		}
+	}
+}
@@ -130,6 +130,10 @@ const (
 	// etc.).
 	InvalidArgument ErrorCode = "invalid_argument"
 
+	// Malformed indicates an error occurred while decoding the client's request.
+	// It is returned by Twirp servers and only accepted for compatibility.
+	Malformed ErrorCode = "malformed"
+
 	// DeadlineExceeded means operation expired before completion. For operations
 	// that change the state of the system, this error may be returned even if the
 	// operation has completed successfully (timeout).
@@ -144,6 +148,14 @@ const (
 	// NotFound or Unimplemented.
 	BadRoute ErrorCode = "bad_route"
 
+	// UnsupportedMediaType indicates the Content-Type of the request isn't
+	// supported by the server. HTTP status 415.
+	UnsupportedMediaType ErrorCode = "unsupported_media_type"
+
+	// NotAcceptable indicates the server can't respond in any of the media
+	// types of the Accept header of the request. HTTP status 406.
+	NotAcceptable ErrorCode = "not_acceptable"
+
 	// AlreadyExists means an attempt to create an entity failed because one
 	// already exists.
 	AlreadyExists ErrorCode = "already_exists"
@@ -207,6 +219,33 @@ const (
 	NoError ErrorCode = ""
 )
 
+// ErrorCodes returns the valid error codes in the order of their declaration,
+// without NoError.
+func ErrorCodes() []ErrorCode {
+	return []ErrorCode{
+		Canceled,
+		Unknown,
+		InvalidArgument,
+		Malformed,
+		DeadlineExceeded,
+		NotFound,
+		BadRoute,
+		UnsupportedMediaType,
+		NotAcceptable,
+		AlreadyExists,
+		PermissionDenied,
+		Unauthenticated,
+		ResourceExhausted,
+		FailedPrecondition,
+		Aborted,
+		OutOfRange,
+		Unimplemented,
+		Internal,
+		Unavailable,
+		DataLoss,
+	}
+}
+
 // ServerHTTPStatusFromErrorCode maps a  error type into a similar HTTP
 // response status. It is used by the  server handler to set the HTTP
 // response status code. Returns 0 if the ErrorCode is invalid.
@@ -218,12 +257,18 @@ func ServerHTTPStatusFromErrorCode(code ErrorCode) int {
 		return http.StatusInternalServerError
 	case InvalidArgument:
 		return http.StatusBadRequest
+	case Malformed:
+		return http.StatusBadRequest
 	case DeadlineExceeded:
 		return http.StatusRequestTimeout
 	case NotFound:
 		return http.StatusNotFound
 	case BadRoute:
 		return http.StatusNotFound
+	case UnsupportedMediaType:
+		return http.StatusUnsupportedMediaType
+	case NotAcceptable:
+		return http.StatusNotAcceptable
 	case AlreadyExists:
 		return http.StatusConflict
 	case PermissionDenied:
@@ -0,0 +1,70 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"net"
+)
+
+// KeyFunc extracts the key a request is limited by, for example the client
+// address or the authenticated user. If no key can be extracted it returns
+// ("", false).
+type KeyFunc func(ctx context.Context) (string, bool)
+
+// ClientIP limits requests by the remote IP address of the connection.
+//
+// Behind a proxy or load balancer the remote address is the one of the proxy,
+// use Header with the header set by the proxy (e.g. "X-Real-IP") instead.
+func ClientIP() KeyFunc {
+	return func(ctx context.Context) (string, bool) {
+		req, ok := xcontext.HTTPRequest(ctx)
+		if !ok || req.RemoteAddr == "" {
+			return "", false
+		}
+		host, _, err := net.SplitHostPort(req.RemoteAddr)
+		if err != nil {
+			return req.RemoteAddr, true
+		}
+		return host, true
+	}
+}
+
+// Header limits requests by the value of the given request header, like an
+// API key or a client id.
+func Header(name string) KeyFunc {
+	return func(ctx context.Context) (string, bool) {
+		header, ok := xcontext.IncomingHTTPHeaders(ctx)
+		if !ok {
+			return "", false
+		}
+		value := header.Get(name)
+		return value, value != ""
+	}
+}
+
+// ContextValue limits requests by a value stored in the context under key,
+// like an authenticated principal set by a RequestReceived hook. The value is
+// formatted with fmt.Sprint, values implementing fmt.Stringer are used as is.
+func ContextValue(key interface{}) KeyFunc {
+	return func(ctx context.Context) (string, bool) {
+		value := ctx.Value(key)
+		if value == nil {
+			return "", false
+		}
+		return fmt.Sprint(value), true
+	}
+}
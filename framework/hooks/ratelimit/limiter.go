@@ -0,0 +1,182 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter decides whether a request identified by a key may be handled.
+type Limiter interface {
+	// Take tries to take a slot for key. If the request is allowed, Take
+	// returns (delay, true) and the request has to wait delay before it may
+	// proceed. If the request is rejected, Take returns (retryAfter, false)
+	// where retryAfter is the earliest time after which a retry may succeed.
+	Take(key string) (time.Duration, bool)
+}
+
+// sweepInterval is the number of Take calls after which idle keys are
+// removed, so the per-key state can't grow without bound.
+const sweepInterval = 1024
+
+// TokenBucket is a Limiter that allows bursts of up to burst requests per key
+// and refills at rate requests per second. Requests are either allowed
+// immediately or rejected.
+type TokenBucket struct {
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu      sync.Mutex
+	calls   int
+	buckets map[string]*tokenBucketState
+}
+
+type tokenBucketState struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket creates a TokenBucket which refills rate tokens per second
+// and holds at most burst tokens per key. It panics if rate isn't positive,
+// buckets would never be refilled.
+func NewTokenBucket(rate float64, burst int) *TokenBucket {
+	if !(rate > 0) {
+		panic("ratelimit: rate of a TokenBucket must be positive")
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		rate:    rate,
+		burst:   float64(burst),
+		now:     time.Now,
+		buckets: make(map[string]*tokenBucketState),
+	}
+}
+
+// Take implements Limiter.
+func (l *TokenBucket) Take(key string) (time.Duration, bool) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &tokenBucketState{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	}
+
+	b.tokens += now.Sub(b.last).Seconds() * l.rate
+	if b.tokens > l.burst {
+		b.tokens = l.burst
+	}
+	b.last = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0, true
+	}
+
+	missing := 1 - b.tokens
+	return time.Duration(missing / l.rate * float64(time.Second)), false
+}
+
+// sweep removes buckets which are full again, they are indistinguishable
+// from buckets that were never used.
+func (l *TokenBucket) sweep(now time.Time) {
+	l.calls++
+	if l.calls < sweepInterval {
+		return
+	}
+	l.calls = 0
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// LeakyBucket is a Limiter that smooths requests per key to a constant rate.
+// Requests arriving faster than rate are queued (delayed) until the queue
+// holds capacity requests, further requests are rejected.
+type LeakyBucket struct {
+	interval time.Duration
+	capacity int
+	now      func() time.Time
+
+	mu    sync.Mutex
+	calls int
+	next  map[string]time.Time
+}
+
+// NewLeakyBucket creates a LeakyBucket which lets rate requests per second
+// pass and queues at most capacity requests per key. It panics if rate isn't
+// positive, no request would ever pass.
+func NewLeakyBucket(rate float64, capacity int) *LeakyBucket {
+	if !(rate > 0) {
+		panic("ratelimit: rate of a LeakyBucket must be positive")
+	}
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &LeakyBucket{
+		interval: time.Duration(float64(time.Second) / rate),
+		capacity: capacity,
+		now:      time.Now,
+		next:     make(map[string]time.Time),
+	}
+}
+
+// Take implements Limiter.
+func (l *LeakyBucket) Take(key string) (time.Duration, bool) {
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+
+	next, ok := l.next[key]
+	if !ok || next.Before(now) {
+		next = now
+	}
+
+	wait := next.Sub(now)
+	limit := time.Duration(l.capacity) * l.interval
+	if wait > limit {
+		return wait - limit, false
+	}
+
+	l.next[key] = next.Add(l.interval)
+	return wait, true
+}
+
+// sweep removes keys whose queue has drained.
+func (l *LeakyBucket) sweep(now time.Time) {
+	l.calls++
+	if l.calls < sweepInterval {
+		return
+	}
+	l.calls = 0
+	for key, next := range l.next {
+		if next.Before(now) {
+			delete(l.next, key)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package ratelimit provides server hooks which limit the request rate of a
+// generated server per service and per method.
+//
+// A request is checked against the limiter of its method first and then
+// against the limiter of its service, so a request that passes the method
+// limit but is rejected by the service limit has still used up its slot of
+// the method limit.
+package ratelimit
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"math"
+	"strconv"
+	"time"
+)
+
+// RetryAfterHeader is the response header which tells a rejected client how
+// many seconds it should wait before retrying.
+const RetryAfterHeader = "Retry-After"
+
+// Config describes which limits are applied to which requests.
+type Config struct {
+	// Key extracts the key requests are limited by. If it's nil, or no key
+	// can be extracted, all requests share the same limit.
+	Key KeyFunc
+
+	// Default is applied to every method which has neither a service nor a
+	// method limit.
+	Default Limiter
+
+	// Services maps service names (e.g. "HelloWorld") to their limit. All
+	// methods of a service share this limit.
+	Services map[string]Limiter
+
+	// Methods maps method names qualified by their service
+	// (e.g. "HelloWorld.Hello") to their limit.
+	Methods map[string]Limiter
+}
+
+// NewServerHooks creates a *hooks.ServerHooks which limits requests with
+// the given config in the RequestRouted hook. Rejected requests fail with
+// errors.ResourceExhausted and the Retry-After response header set.
+// Requests delayed by a LeakyBucket fail with errors.Canceled or
+// errors.DeadlineExceeded if their context is done while they are waiting.
+func NewServerHooks(config Config) *hooks.ServerHooks {
+	return &hooks.ServerHooks{
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			return ctx, config.limit(ctx)
+		},
+	}
+}
+
+func (c Config) limit(ctx context.Context) error {
+	limiters := c.limiters(ctx)
+	if len(limiters) == 0 {
+		return nil
+	}
+
+	var key string
+	if c.Key != nil {
+		key, _ = c.Key(ctx)
+	}
+
+	var delay time.Duration
+	for _, limiter := range limiters {
+		d, ok := limiter.Take(key)
+		if !ok {
+			return rejected(ctx, d)
+		}
+		if d > delay {
+			delay = d
+		}
+	}
+
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return errors.NewError(errors.DeadlineExceeded, "deadline exceeded while waiting for rate limit")
+		}
+		return errors.NewError(errors.Canceled, "canceled while waiting for rate limit")
+	}
+}
+
+// limiters returns the limiters which apply to the routed method, the most
+// specific first.
+func (c Config) limiters(ctx context.Context) []Limiter {
+	serviceName, _ := xcontext.ServiceName(ctx)
+	methodName, _ := xcontext.MethodName(ctx)
+
+	limiters := make([]Limiter, 0, 2)
+	if l, ok := c.Methods[serviceName+"."+methodName]; ok && l != nil {
+		limiters = append(limiters, l)
+	}
+	if l, ok := c.Services[serviceName]; ok && l != nil {
+		limiters = append(limiters, l)
+	}
+	if len(limiters) == 0 && c.Default != nil {
+		limiters = append(limiters, c.Default)
+	}
+	return limiters
+}
+
+func rejected(ctx context.Context, retryAfter time.Duration) errors.Error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	retry := strconv.Itoa(seconds)
+
+	// Ignoring the error is fine, it's only returned for reserved headers.
+	_ = xcontext.SetHTTPResponseHeader(ctx, RetryAfterHeader, retry)
+
+	err := errors.NewError(errors.ResourceExhausted, "rate limit exceeded")
+	return err.WithMeta("retry_after", retry)
+}
@@ -0,0 +1,144 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package ratelimit
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"math"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type fakeClock struct {
+	t time.Time
+}
+
+func (c *fakeClock) now() time.Time { return c.t }
+
+func TestTokenBucket(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	l := NewTokenBucket(1, 2)
+	l.now = clock.now
+
+	for i := 0; i < 2; i++ {
+		if _, ok := l.Take("a"); !ok {
+			t.Fatalf("request %d within burst was rejected", i)
+		}
+	}
+
+	retry, ok := l.Take("a")
+	if ok {
+		t.Fatal("request exceeding burst was allowed")
+	}
+	if retry != time.Second {
+		t.Fatalf("unexpected retry after (actual: %v, expected: %v)", retry, time.Second)
+	}
+
+	if _, ok := l.Take("b"); !ok {
+		t.Fatal("request of other key was rejected")
+	}
+
+	clock.t = clock.t.Add(time.Second)
+	if _, ok := l.Take("a"); !ok {
+		t.Fatal("request after refill was rejected")
+	}
+}
+
+func TestLeakyBucket(t *testing.T) {
+	clock := &fakeClock{t: time.Unix(0, 0)}
+	l := NewLeakyBucket(10, 1)
+	l.now = clock.now
+
+	if delay, ok := l.Take("a"); !ok || delay != 0 {
+		t.Fatalf("first request was not allowed immediately (delay: %v, ok: %v)", delay, ok)
+	}
+
+	if delay, ok := l.Take("a"); !ok || delay != 100*time.Millisecond {
+		t.Fatalf("second request was not queued (delay: %v, ok: %v)", delay, ok)
+	}
+
+	retry, ok := l.Take("a")
+	if ok {
+		t.Fatal("request exceeding capacity was allowed")
+	}
+	if retry != 100*time.Millisecond {
+		t.Fatalf("unexpected retry after (actual: %v, expected: %v)", retry, 100*time.Millisecond)
+	}
+}
+
+func TestNewTokenBucket_InvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -1, math.NaN()} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("no panic for rate %v", rate)
+				}
+			}()
+			NewTokenBucket(rate, 1)
+		}()
+	}
+}
+
+func TestNewLeakyBucket_InvalidRate(t *testing.T) {
+	for _, rate := range []float64{0, -1, math.NaN()} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Errorf("no panic for rate %v", rate)
+				}
+			}()
+			NewLeakyBucket(rate, 1)
+		}()
+	}
+}
+
+func TestNewServerHooks(t *testing.T) {
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Header.Set("X-Client", "client-1")
+
+	ctx := context.Background()
+	ctx = xcontext.WithResponseWriter(ctx, resp)
+	ctx = xcontext.WithHTTPRequest(ctx, req)
+	ctx = xcontext.WithServiceName(ctx, "HelloWorld")
+	ctx = xcontext.WithMethodName(ctx, "Hello")
+
+	h := NewServerHooks(Config{
+		Key: Header("X-Client"),
+		Methods: map[string]Limiter{
+			"HelloWorld.Hello": NewTokenBucket(0.5, 1),
+		},
+	})
+
+	if _, err := h.RequestRouted(ctx); err != nil {
+		t.Fatalf("first request was rejected: %v", err)
+	}
+
+	_, err := h.RequestRouted(ctx)
+	if err == nil {
+		t.Fatal("second request was allowed")
+	}
+
+	terr, ok := err.(errors.Error)
+	if !ok || terr.Code() != errors.ResourceExhausted {
+		t.Fatalf("unexpected error (actual: %v, expected code: %s)", err, errors.ResourceExhausted)
+	}
+
+	if actual := resp.Header().Get(RetryAfterHeader); actual != "2" {
+		t.Fatalf(`unexpected Retry-After header (actual: "%s", expected: "2")`, actual)
+	}
+}
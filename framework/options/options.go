@@ -0,0 +1,32 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package options is the Go package of proto/xservice/options.proto, the
+// custom options understood by protoc-gen-xservice.
+//
+// The options are only read by the generator, generated code never needs
+// them at runtime. The package exists so imports of options.proto resolve
+// to a Go package, and to share the field numbers of the options.
+package options
+
+// Field numbers of the extensions of google.protobuf.ServiceOptions.
+const (
+	ServiceScopesField int32 = 52101
+	ServiceRolesField  int32 = 52102
+)
+
+// Field numbers of the extensions of google.protobuf.MethodOptions.
+const (
+	ScopesField int32 = 52111
+	RolesField  int32 = 52112
+)
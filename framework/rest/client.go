@@ -0,0 +1,181 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"github.com/gogo/protobuf/proto"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+)
+
+// DoRequest sends in to the RESTful route described by rule and decodes the
+// response into out. addr is the base URL of the server. Bodies are encoded
+// and responses decoded with the JSON codec of options, see
+// transport.ClientOptions.JSONCodec.
+//
+// Fields bound by path variables must be set, they are sent in the path.
+// The body is sent as configured by the rule and all other fields are sent
+// in the query string.
+func DoRequest(ctx context.Context, options *transport.ClientOptions, client transport.HTTPClient, addr string, rule Rule, in, out proto.Message) (err error) {
+	if options == nil {
+		options = transport.NewClientOptions()
+	}
+	codec := options.JSONCodec()
+
+	t, err := ParseTemplate(rule.Pattern)
+	if err != nil {
+		return errors.ClientError("invalid path template", err)
+	}
+
+	values, err := transport.EncodeQuery(in)
+	if err != nil {
+		return errors.ClientError("failed to encode request", err)
+	}
+
+	pathValues := make(map[string]string)
+	for _, fieldPath := range t.FieldPaths() {
+		pathValues[fieldPath] = values.Get(fieldPath)
+		values.Del(fieldPath)
+	}
+	path, err := t.Expand(pathValues)
+	if err != nil {
+		return errors.ClientError("failed to build request path", err)
+	}
+
+	var reqBody io.Reader
+	switch rule.Body {
+	case "":
+	case "*":
+		body, err := codec.Marshal(in)
+		if err != nil {
+			return errors.ClientError("failed to marshal json request", err)
+		}
+		reqBody = bytes.NewReader(body)
+		values = nil
+	default:
+		body, err := fieldJSON(codec, in, rule.Body)
+		if err != nil {
+			return errors.ClientError("failed to marshal json request", err)
+		}
+		reqBody = bytes.NewReader(body)
+		for key := range values {
+			if key == rule.Body || strings.HasPrefix(key, rule.Body+".") {
+				values.Del(key)
+			}
+		}
+	}
+
+	url := strings.TrimSuffix(transport.UrlBase(addr), "/") + path
+	if query := values.Encode(); query != "" {
+		url += "?" + query
+	}
+
+	if err = ctx.Err(); err != nil {
+		return errors.ClientError("aborted because context was done", err)
+	}
+
+	req, err := http.NewRequest(rule.Method, url, reqBody)
+	if err != nil {
+		return errors.ClientError("could not build request", err)
+	}
+	req = req.WithContext(ctx)
+	if customHeader := transport.CustomHTTPRequestHeaders(ctx); customHeader != nil {
+		req.Header = customHeader
+	}
+	if reqBody != nil {
+		req.Header.Set(xhttp.ContentTypeHeader, xhttp.ApplicationJson)
+	}
+	req.Header.Set("Accept", xhttp.ApplicationJson)
+	req.Header.Set(xhttp.VersionHeader, transport.ProtocolVersion)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		if terr, ok := err.(errors.Error); ok {
+			return terr
+		}
+		return errors.ClientError("failed to do request", err)
+	}
+
+	defer func() {
+		cerr := resp.Body.Close()
+		if err == nil && cerr != nil {
+			err = errors.ClientError("failed to close response body", cerr)
+		}
+	}()
+
+	if err = ctx.Err(); err != nil {
+		return errors.ClientError("aborted because context was done", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return transport.ErrorFromResponse(resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.ClientError("failed to read response body", err)
+	}
+	if err = codec.Unmarshal(body, out); err != nil {
+		return errors.ClientError("failed to unmarshal json response", err)
+	}
+	return nil
+}
+
+// fieldJSON returns the JSON value of the field named name of message,
+// encoded with the options of codec.
+func fieldJSON(codec *transport.JSONCodec, message proto.Message, name string) ([]byte, error) {
+	buff := new(bytes.Buffer)
+	marshaler := codec.Marshaler
+	marshaler.Indent = ""
+	if err := marshaler.Marshal(buff, message); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(buff.Bytes(), &fields); err != nil {
+		return nil, err
+	}
+	key := name
+	if !marshaler.OrigName {
+		key = jsonName(message, name)
+	}
+	if value, ok := fields[key]; ok {
+		return value, nil
+	}
+	// Unset fields are omitted by jsonpb.
+	return []byte("null"), nil
+}
+
+// jsonName returns the lowerCamelCase JSON name of the field named name of
+// message.
+func jsonName(message proto.Message, name string) string {
+	typ := reflect.TypeOf(message)
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return name
+	}
+	for _, prop := range proto.GetProperties(typ.Elem()).Prop {
+		if prop.OrigName == name && prop.JSONName != "" {
+			return prop.JSONName
+		}
+	}
+	return name
+}
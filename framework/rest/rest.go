@@ -0,0 +1,162 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package rest implements the RESTful routes of google.api.http annotations
+// for generated servers and clients.
+//
+// A method annotated with
+//
+//   rpc GetUser(GetUserReq) returns (User) {
+//     option (google.api.http) = { get: "/v1/users/{id}" };
+//   }
+//
+// is served at GET /v1/users/{id} in addition to its RPC route, the id field
+// of the request is bound to the path variable. Fields which are neither
+// bound by the path nor by the body are read from the query string, see
+// transport.DecodeQueryRequest. REST requests and responses are always JSON.
+package rest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/gogo/protobuf/proto"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+)
+
+// Rule is a single binding of a google.api.http annotation.
+type Rule struct {
+	// Method is the HTTP method, e.g. "GET".
+	Method string
+	// Pattern is the path template, e.g. "/v1/users/{id}".
+	Pattern string
+	// Body is empty if the request has no body, "*" if the body is the whole
+	// request message, or the name of the field the body is decoded into.
+	Body string
+}
+
+// Route binds a Rule to a method of a service.
+type Route struct {
+	// Name is the name of the method.
+	Name string
+	Rule Rule
+
+	template *Template
+}
+
+// Router matches requests against the routes of a service.
+type Router struct {
+	routes []*Route
+}
+
+// NewRouter creates a Router for routes. If more than one route matches a
+// request, routes with a verb and then routes with more literal segments
+// win, otherwise the first one.
+func NewRouter(routes ...Route) (*Router, error) {
+	r := &Router{}
+	for _, route := range routes {
+		t, err := ParseTemplate(route.Rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("route %s: %v", route.Name, err)
+		}
+		route := route
+		route.template = t
+		r.routes = append(r.routes, &route)
+	}
+
+	sort.SliceStable(r.routes, func(i, j int) bool {
+		ti, tj := r.routes[i].template, r.routes[j].template
+		if (ti.verb != "") != (tj.verb != "") {
+			return ti.verb != ""
+		}
+		return ti.literals() > tj.literals()
+	})
+	return r, nil
+}
+
+// MustNewRouter is like NewRouter but panics if a path template is invalid.
+// It's used by generated code.
+func MustNewRouter(routes ...Route) *Router {
+	r, err := NewRouter(routes...)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// Match returns the route for req and the values of its path variables.
+func (r *Router) Match(req *http.Request) (*Route, map[string]string, bool) {
+	path := req.URL.EscapedPath()
+	for _, route := range r.routes {
+		if route.Rule.Method != req.Method {
+			continue
+		}
+		if values, ok := route.template.Match(path); ok {
+			return route, values, true
+		}
+	}
+	return nil, nil, false
+}
+
+// NewRequestDecoder returns the DecodeRequestFunc for a request matched by
+// route. The message is decoded from the body as configured by the rule,
+// from the query string, and from the path variables, in that order.
+func NewRequestDecoder(route *Route, pathValues map[string]string) transport.DecodeRequestFunc {
+	return func(ctx context.Context, req *http.Request, message proto.Message) error {
+		if err := decodeBody(ctx, req, route.Rule.Body, message); err != nil {
+			return err
+		}
+
+		if route.Rule.Body != "*" {
+			if err := transport.DecodeQuery(req.URL.Query(), message); err != nil {
+				return err
+			}
+		}
+
+		values := make(url.Values, len(pathValues))
+		for fieldPath, value := range pathValues {
+			values.Set(fieldPath, value)
+		}
+		return transport.DecodeQuery(values, message)
+	}
+}
+
+func decodeBody(ctx context.Context, req *http.Request, body string, message proto.Message) error {
+	if body == "" {
+		return nil
+	}
+
+	b, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return transport.ReadError(ctx, err)
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil
+	}
+	if body != "*" {
+		// The body is the JSON value of a single field, wrapping it in an
+		// object lets jsonpb decode it into that field.
+		b = append(append([]byte("{"+strconv.Quote(body)+":"), b...), '}')
+	}
+
+	codec := transport.NewJSONCodec()
+	if err := codec.Unmarshal(b, message); err != nil {
+		return transport.DecodeError(codec, b, message, err)
+	}
+	return nil
+}
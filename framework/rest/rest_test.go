@@ -0,0 +1,96 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rest
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		values   map[string]string
+	}{
+		{"/v1/users/{id}", "/v1/users/42", map[string]string{"id": "42"}},
+		{"/v1/users/{id}", "/v1/users/a%2Fb", map[string]string{"id": "a/b"}},
+		{"/v1/{name=shelves/*/books/*}", "/v1/shelves/1/books/2", map[string]string{"name": "shelves/1/books/2"}},
+		{"/v1/{name=files/**}", "/v1/files/a/b/c", map[string]string{"name": "files/a/b/c"}},
+		{"/v1/{parent.id}/items:search", "/v1/7/items:search", map[string]string{"parent.id": "7"}},
+		{"/v1/users/{id}", "/v1/users/42/friends", nil},
+		{"/v1/users/{id}:ban", "/v1/users/42", nil},
+		{"/v1/users/*", "/v1/users/42", map[string]string{}},
+	}
+
+	for _, test := range tests {
+		tpl, err := ParseTemplate(test.template)
+		if err != nil {
+			t.Fatalf("could not parse %s: %v", test.template, err)
+		}
+
+		values, ok := tpl.Match(test.path)
+		if ok != (test.values != nil) || (ok && !reflect.DeepEqual(values, test.values)) {
+			t.Errorf("unexpected match of %s against %s (actual: %v, %v, expected: %v)", test.path, test.template, values, ok, test.values)
+			continue
+		}
+		if !ok || len(test.values) == 0 {
+			continue
+		}
+
+		path, err := tpl.Expand(values)
+		if err != nil {
+			t.Fatalf("could not expand %s: %v", test.template, err)
+		}
+		if path != test.path {
+			t.Errorf("unexpected expansion of %s (actual: %s, expected: %s)", test.template, path, test.path)
+		}
+	}
+}
+
+func TestParseTemplateInvalid(t *testing.T) {
+	for _, template := range []string{"v1/users", "/v1/{id", "/v1/**/users", "/v1//users", "/v1/{=x}"} {
+		if _, err := ParseTemplate(template); err == nil {
+			t.Errorf("invalid template %s was parsed", template)
+		}
+	}
+}
+
+func TestRouterPrecedence(t *testing.T) {
+	r := MustNewRouter(
+		Route{Name: "GetUser", Rule: Rule{Method: "GET", Pattern: "/v1/users/{id}"}},
+		Route{Name: "GetMe", Rule: Rule{Method: "GET", Pattern: "/v1/users/me"}},
+		Route{Name: "BanUser", Rule: Rule{Method: "POST", Pattern: "/v1/users/{id}:ban"}},
+	)
+
+	tests := []struct{ method, path, name string }{
+		{"GET", "/v1/users/me", "GetMe"},
+		{"GET", "/v1/users/42", "GetUser"},
+		{"POST", "/v1/users/42:ban", "BanUser"},
+		{"POST", "/v1/users/42", ""},
+	}
+	for _, test := range tests {
+		route, _, ok := r.Match(httptest.NewRequest(test.method, test.path, nil))
+		if !ok {
+			if test.name != "" {
+				t.Errorf("%s %s didn't match", test.method, test.path)
+			}
+			continue
+		}
+		if route.Name != test.name {
+			t.Errorf("unexpected route for %s %s (actual: %s, expected: %s)", test.method, test.path, route.Name, test.name)
+		}
+	}
+}
@@ -0,0 +1,293 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package rest
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Template is a parsed path template of a google.api.http rule, like
+// "/v1/{name=shelves/*/books/*}:publish".
+//
+// The grammar is:
+//
+//   Template = "/" Segments [ Verb ] ;
+//   Segments = Segment { "/" Segment } ;
+//   Segment  = "*" | "**" | LITERAL | Variable ;
+//   Variable = "{" FieldPath [ "=" Segments ] "}" ;
+//   FieldPath = IDENT { "." IDENT } ;
+//   Verb     = ":" LITERAL ;
+type Template struct {
+	raw       string
+	segments  []segment
+	variables []variable
+	verb      string
+}
+
+type segmentKind int
+
+const (
+	literalSegment segmentKind = iota
+	wildcardSegment
+	deepWildcardSegment
+)
+
+type segment struct {
+	kind    segmentKind
+	literal string
+}
+
+// variable binds the segments [start, end) to a field of the request, end
+// is -1 if the variable ends with "**".
+type variable struct {
+	fieldPath string
+	start     int
+	end       int
+}
+
+// ParseTemplate parses a path template.
+func ParseTemplate(template string) (*Template, error) {
+	if !strings.HasPrefix(template, "/") {
+		return nil, fmt.Errorf("path template %q doesn't start with /", template)
+	}
+
+	t := &Template{raw: template}
+	path := template[1:]
+
+	// The verb follows the last segment, colons in variables don't count.
+	if i := strings.LastIndex(path, ":"); i >= 0 && !strings.Contains(path[i:], "}") && !strings.Contains(path[i:], "/") {
+		t.verb = path[i+1:]
+		path = path[:i]
+		if t.verb == "" {
+			return nil, fmt.Errorf("path template %q has an empty verb", template)
+		}
+	}
+
+	for len(path) > 0 {
+		if path[0] == '{' {
+			end := strings.IndexByte(path, '}')
+			if end < 0 {
+				return nil, fmt.Errorf("path template %q has an unclosed variable", template)
+			}
+			if err := t.parseVariable(path[1:end]); err != nil {
+				return nil, fmt.Errorf("path template %q: %v", template, err)
+			}
+			path = path[end+1:]
+		} else {
+			end := strings.IndexByte(path, '/')
+			if end < 0 {
+				end = len(path)
+			}
+			if err := t.appendSegment(path[:end]); err != nil {
+				return nil, fmt.Errorf("path template %q: %v", template, err)
+			}
+			path = path[end:]
+		}
+
+		if len(path) > 0 {
+			if path[0] != '/' || len(path) == 1 {
+				return nil, fmt.Errorf("path template %q has a malformed segment", template)
+			}
+			path = path[1:]
+		}
+	}
+
+	if len(t.segments) == 0 {
+		return nil, fmt.Errorf("path template %q has no segments", template)
+	}
+	for i, s := range t.segments {
+		if s.kind == deepWildcardSegment && i != len(t.segments)-1 {
+			return nil, fmt.Errorf("path template %q: ** must be the last segment", template)
+		}
+	}
+	return t, nil
+}
+
+func (t *Template) parseVariable(v string) error {
+	fieldPath, pattern := v, "*"
+	if i := strings.IndexByte(v, '='); i >= 0 {
+		fieldPath, pattern = v[:i], v[i+1:]
+	}
+	if fieldPath == "" {
+		return fmt.Errorf("variable without field path")
+	}
+	for _, ident := range strings.Split(fieldPath, ".") {
+		if ident == "" {
+			return fmt.Errorf("variable %q has a malformed field path", fieldPath)
+		}
+	}
+
+	start := len(t.segments)
+	for _, s := range strings.Split(pattern, "/") {
+		if err := t.appendSegment(s); err != nil {
+			return err
+		}
+	}
+	end := len(t.segments)
+	if t.segments[end-1].kind == deepWildcardSegment {
+		end = -1
+	}
+
+	t.variables = append(t.variables, variable{fieldPath: fieldPath, start: start, end: end})
+	return nil
+}
+
+func (t *Template) appendSegment(s string) error {
+	switch {
+	case s == "":
+		return fmt.Errorf("empty segment")
+	case s == "*":
+		t.segments = append(t.segments, segment{kind: wildcardSegment})
+	case s == "**":
+		t.segments = append(t.segments, segment{kind: deepWildcardSegment})
+	case strings.ContainsAny(s, "{}*="):
+		return fmt.Errorf("malformed segment %q", s)
+	default:
+		t.segments = append(t.segments, segment{kind: literalSegment, literal: s})
+	}
+	return nil
+}
+
+// String returns the template as it was parsed.
+func (t *Template) String() string {
+	return t.raw
+}
+
+// FieldPaths returns the field paths bound by the variables of the template.
+func (t *Template) FieldPaths() []string {
+	paths := make([]string, 0, len(t.variables))
+	for _, v := range t.variables {
+		paths = append(paths, v.fieldPath)
+	}
+	return paths
+}
+
+// literals returns the number of literal segments, more literals make a
+// template more specific.
+func (t *Template) literals() int {
+	n := 0
+	for _, s := range t.segments {
+		if s.kind == literalSegment {
+			n++
+		}
+	}
+	return n
+}
+
+// Match matches an escaped URL path (see url.URL.EscapedPath) against the
+// template and returns the unescaped values of its variables.
+func (t *Template) Match(path string) (map[string]string, bool) {
+	if !strings.HasPrefix(path, "/") {
+		return nil, false
+	}
+	path = path[1:]
+
+	if t.verb != "" {
+		if !strings.HasSuffix(path, ":"+t.verb) {
+			return nil, false
+		}
+		path = path[:len(path)-len(t.verb)-1]
+	}
+
+	parts := strings.Split(path, "/")
+	last := t.segments[len(t.segments)-1]
+	if last.kind == deepWildcardSegment {
+		if len(parts) < len(t.segments)-1 {
+			return nil, false
+		}
+	} else if len(parts) != len(t.segments) {
+		return nil, false
+	}
+
+	for i, s := range t.segments {
+		switch s.kind {
+		case literalSegment:
+			if parts[i] != s.literal {
+				return nil, false
+			}
+		case wildcardSegment:
+			if parts[i] == "" {
+				return nil, false
+			}
+		}
+	}
+
+	values := make(map[string]string, len(t.variables))
+	for _, v := range t.variables {
+		end := v.end
+		if end < 0 {
+			end = len(parts)
+		}
+		unescaped := make([]string, 0, end-v.start)
+		for _, part := range parts[v.start:end] {
+			u, err := url.PathUnescape(part)
+			if err != nil {
+				return nil, false
+			}
+			unescaped = append(unescaped, u)
+		}
+		values[v.fieldPath] = strings.Join(unescaped, "/")
+	}
+	return values, true
+}
+
+// Expand builds an escaped URL path from the template by replacing its
+// variables with values. Values of variables spanning multiple segments keep
+// their slashes.
+func (t *Template) Expand(values map[string]string) (string, error) {
+	var b strings.Builder
+	next := 0
+	for _, v := range t.variables {
+		for ; next < v.start; next++ {
+			b.WriteString("/")
+			b.WriteString(t.segments[next].literal)
+		}
+
+		value, ok := values[v.fieldPath]
+		if !ok || value == "" {
+			return "", fmt.Errorf("missing value for path variable %s", v.fieldPath)
+		}
+		single := v.end == v.start+1 && t.segments[v.start].kind == wildcardSegment
+		if single {
+			value = url.PathEscape(value)
+		} else {
+			parts := strings.Split(value, "/")
+			for i, part := range parts {
+				parts[i] = url.PathEscape(part)
+			}
+			value = strings.Join(parts, "/")
+		}
+		b.WriteString("/")
+		b.WriteString(value)
+
+		next = v.end
+		if next < 0 {
+			next = len(t.segments)
+		}
+	}
+	for ; next < len(t.segments); next++ {
+		if t.segments[next].kind != literalSegment {
+			return "", fmt.Errorf("path template %q has a wildcard outside of a variable", t.raw)
+		}
+		b.WriteString("/")
+		b.WriteString(t.segments[next].literal)
+	}
+	if t.verb != "" {
+		b.WriteString(":")
+		b.WriteString(t.verb)
+	}
+	return b.String(), nil
+}
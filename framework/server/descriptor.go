@@ -0,0 +1,136 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"compress/gzip"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/pkg/errors"
+	"io/ioutil"
+	"log"
+	"sort"
+	"sync"
+)
+
+type serviceDescriptor struct {
+	fileDescriptor []byte
+	index          int
+}
+
+var registry = struct {
+	sync.RWMutex
+	services map[string]serviceDescriptor
+}{
+	services: make(map[string]serviceDescriptor),
+}
+
+// RegisterServiceDescriptor registers the gzipped FileDescriptorProto of a
+// service and the index of the service in it under the fully-qualified name
+// of the service (e.g. "example.helloworld.HelloWorld"). Generated code calls
+// it when the package is initialized. If the name is already registered,
+// e.g. by a package generated twice, the first registration is kept and the
+// conflict is logged.
+func RegisterServiceDescriptor(fullName string, fileDescriptor []byte, index int) {
+	registry.Lock()
+	defer registry.Unlock()
+
+	if _, ok := registry.services[fullName]; ok {
+		log.Printf("server: service %s is already registered, keeping the first registration", fullName)
+		return
+	}
+	registry.services[fullName] = serviceDescriptor{fileDescriptor: fileDescriptor, index: index}
+}
+
+// RegisteredServices returns the sorted fully-qualified names of all
+// registered services.
+func RegisteredServices() []string {
+	registry.RLock()
+	defer registry.RUnlock()
+
+	names := make([]string, 0, len(registry.services))
+	for name := range registry.services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DecompressFileDescriptor unzips and unmarshals a gzipped
+// FileDescriptorProto, as returned by ServiceDescriptor.
+func DecompressFileDescriptor(gz []byte) (*descriptor.FileDescriptorProto, error) {
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open gzip reader")
+	}
+	defer r.Close()
+
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to uncompress descriptor")
+	}
+
+	fd := new(descriptor.FileDescriptorProto)
+	if err := proto.Unmarshal(b, fd); err != nil {
+		return nil, errors.Wrap(err, "malformed FileDescriptorProto")
+	}
+	return fd, nil
+}
+
+// ExtractServiceDescriptor returns the descriptors of the file and of the
+// service of a generated server.
+func ExtractServiceDescriptor(s Server) (*descriptor.FileDescriptorProto, *descriptor.ServiceDescriptorProto, error) {
+	gz, index := s.ServiceDescriptor()
+	return serviceDescriptorAt(gz, index)
+}
+
+// LookupServiceDescriptor returns the descriptors of the file and of the
+// service registered under the fully-qualified name.
+func LookupServiceDescriptor(fullName string) (*descriptor.FileDescriptorProto, *descriptor.ServiceDescriptorProto, error) {
+	registry.RLock()
+	sd, ok := registry.services[fullName]
+	registry.RUnlock()
+	if !ok {
+		return nil, nil, errors.Errorf("service %s is not registered", fullName)
+	}
+	return serviceDescriptorAt(sd.fileDescriptor, sd.index)
+}
+
+// LookupMethodDescriptor returns the descriptor of a method of the service
+// registered under the fully-qualified name.
+func LookupMethodDescriptor(fullServiceName, methodName string) (*descriptor.MethodDescriptorProto, error) {
+	_, sd, err := LookupServiceDescriptor(fullServiceName)
+	if err != nil {
+		return nil, err
+	}
+	for _, method := range sd.Method {
+		if method.GetName() == methodName {
+			return method, nil
+		}
+	}
+	return nil, errors.Errorf("service %s has no method %s", fullServiceName, methodName)
+}
+
+func serviceDescriptorAt(gz []byte, index int) (*descriptor.FileDescriptorProto, *descriptor.ServiceDescriptorProto, error) {
+	fd, err := DecompressFileDescriptor(gz)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if index < 0 || index >= len(fd.Service) {
+		return nil, nil, errors.Errorf("service index %d out of range, %s has %d services", index, fd.GetName(), len(fd.Service))
+	}
+	return fd, fd.Service[index], nil
+}
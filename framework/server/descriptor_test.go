@@ -0,0 +1,42 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRegisterServiceDescriptor_Duplicate(t *testing.T) {
+	var logged bytes.Buffer
+	log.SetOutput(&logged)
+	defer log.SetOutput(os.Stderr)
+
+	const name = "example.duplicate.Duplicate"
+	RegisterServiceDescriptor(name, []byte("first"), 0)
+	RegisterServiceDescriptor(name, []byte("second"), 1)
+
+	registry.RLock()
+	sd := registry.services[name]
+	registry.RUnlock()
+	if string(sd.fileDescriptor) != "first" || sd.index != 0 {
+		t.Fatalf("unexpected registration (actual: %q, %d, expected: %q, %d)", sd.fileDescriptor, sd.index, "first", 0)
+	}
+	if !strings.Contains(logged.String(), name+" is already registered") {
+		t.Fatalf("conflict was not logged (actual: %q)", logged.String())
+	}
+}
@@ -24,6 +24,7 @@ type Server interface {
 	// ServiceDescriptor returns gzipped bytes describing the .proto file that
 	// this service was generated from. Once unzipped, the bytes can be
 	// unmarshalled as a github.com/golang/protobuf/protoc-gen-go/descriptor.FileDescriptorProto.
+	// The int is the index of the service in the file, see ExtractServiceDescriptor.
 	ServiceDescriptor() ([]byte, int)
 	// ProtocGenTwirpVersion is the semantic version string of the version of xservice
 	ProtocGenXServiceVersion() string
@@ -0,0 +1,396 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"github.com/gogo/protobuf/proto"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NewGetResponseEncoder returns the EncodeResponseFunc for a GET request of
+// a side-effect-free method. The response is encoded as protobuf if the
+// client accepts application/protobuf and as JSON otherwise.
+//
+// The response carries an ETag derived from its content, a request with a
+// matching If-None-Match header is answered with 304 Not Modified and an
+// empty body. Servers control caching by setting the Cache-Control header
+// with xcontext.SetHTTPResponseHeader.
+func NewGetResponseEncoder(req *http.Request) EncodeResponseFunc {
+	var codec Codec = NewJSONCodec()
+	if strings.Contains(req.Header.Get("Accept"), xhttp.ApplicationProtobuf) {
+		codec = ProtobufCodec{}
+	}
+	return NewGetResponseEncoderWith(req, codec)
+}
+
+// NewGetResponseEncoderWith is NewGetResponseEncoder with a response codec
+// negotiated by the caller, see ServerOptions.ResponseCodec.
+func NewGetResponseEncoderWith(req *http.Request, codec Codec) EncodeResponseFunc {
+	return func(ctx context.Context, resp http.ResponseWriter, content proto.Message) error {
+		respBytes, err := codec.Marshal(content)
+		if err != nil {
+			err = errors.WrapErr(err, "failed to marshal "+codecName(codec)+" response")
+			return errors.InternalErrorWith(err)
+		}
+
+		sum := sha256.Sum256(respBytes)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		resp.Header().Set("ETag", etag)
+		resp.Header().Add("Vary", "Accept, "+xhttp.JSONOptionsHeader)
+
+		if etagMatches(req.Header.Get("If-None-Match"), etag) {
+			return writeResponse(resp, http.StatusNotModified, nil)
+		}
+
+		resp.Header().Set(xhttp.ContentTypeHeader, codec.ContentType())
+		return writeResponse(resp, http.StatusOK, respBytes)
+	}
+}
+
+// etagMatches reports whether etag is listed in the value of an
+// If-None-Match header, using the weak comparison.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// DoIdempotentJSONRequest calls a side-effect-free method with a JSON
+// request. If the call was enabled with xcontext.WithHTTPGet, the request is
+// sent as GET with the message encoded in the query string, otherwise it
+// falls back to DoJSONRequest.
+func DoIdempotentJSONRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message) error {
+	return doIdempotentRequest(ctx, client, url, in, out, NewJSONCodec())
+}
+
+// DoIdempotentProtobufferRequest is the protobuf counterpart of
+// DoIdempotentJSONRequest.
+func DoIdempotentProtobufferRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message) error {
+	return doIdempotentRequest(ctx, client, url, in, out, ProtobufCodec{})
+}
+
+func doIdempotentRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message, codec Codec) error {
+	if !xcontext.HTTPGet(ctx) {
+		return doRequest(ctx, client, url, in, out, codec)
+	}
+	return doGetRequest(ctx, client, url, in, out, codec)
+}
+
+func doGetRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message, codec Codec) (err error) {
+	values, err := EncodeQuery(in)
+	if err != nil {
+		// Messages with fields which can't be encoded in a query are posted.
+		return doRequest(ctx, client, url, in, out, codec)
+	}
+	if query := values.Encode(); query != "" {
+		url += "?" + query
+	}
+
+	if err = ctx.Err(); err != nil {
+		return errors.ClientError("aborted because context was done", err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return errors.ClientError("could not build request", err)
+	}
+	req = req.WithContext(ctx)
+	if customHeader := CustomHTTPRequestHeaders(ctx); customHeader != nil {
+		req.Header = customHeader
+	}
+	req.Header.Set("Accept", codec.ContentType())
+	req.Header.Set(xhttp.VersionHeader, ProtocolVersion)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return doError(err)
+	}
+
+	defer func() {
+		cerr := resp.Body.Close()
+		if err == nil && cerr != nil {
+			err = errors.ClientError("failed to close response body", cerr)
+		}
+	}()
+
+	if err = ctx.Err(); err != nil {
+		return errors.ClientError("aborted because context was done", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return ErrorFromResponse(resp)
+	}
+
+	respBodyBytes, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return errors.ClientError("failed to read response body", err)
+	}
+
+	if err = codec.Unmarshal(respBodyBytes, out); err != nil {
+		return errors.ClientError("failed to unmarshal "+codecName(codec)+" response", err)
+	}
+	return nil
+}
+
+// NewCachingClient wraps client with a private HTTP cache for GET requests.
+// Responses are cached as long as their Cache-Control max-age allows, stale
+// responses with an ETag are revalidated with If-None-Match. Responses are
+// only shared between requests with the same credential headers and the same
+// values of the headers listed in their Vary header. At most maxEntries
+// responses are kept.
+//
+//   client := pb.NewHelloWorldJSONClient(addr, transport.NewCachingClient(&http.Client{}, 1024))
+//   resp, err := client.Hello(xcontext.WithHTTPGet(ctx), req)
+func NewCachingClient(client HTTPClient, maxEntries int) HTTPClient {
+	// Generated constructors only disable redirects for *http.Client, so it
+	// has to happen before the client gets wrapped.
+	if httpClient, ok := client.(*http.Client); ok {
+		client = WithoutRedirects(httpClient)
+	}
+	return &cachingClient{
+		client:     client,
+		maxEntries: maxEntries,
+		entries:    make(map[string]*cacheEntry),
+		now:        time.Now,
+	}
+}
+
+type cachingClient struct {
+	client     HTTPClient
+	maxEntries int
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+type cacheEntry struct {
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+	// vary are the values of the request headers listed in the Vary header
+	// of the response.
+	vary map[string]string
+}
+
+// credentialHeaders are the request headers which carry credentials, e.g.
+// auth.APIKeyHeader. Responses are only shared between requests with the
+// same credentials.
+var credentialHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "X-API-Key"}
+
+// cacheKey returns the key of the responses to req, the representation is
+// further selected by the Vary header of a response, see cacheEntry.matches.
+func cacheKey(req *http.Request) string {
+	key := req.URL.String() + "\x00" + req.Header.Get("Accept")
+	for _, name := range credentialHeaders {
+		key += "\x00" + strings.Join(req.Header[http.CanonicalHeaderKey(name)], ",")
+	}
+	return key
+}
+
+func (c *cachingClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return c.client.Do(req)
+	}
+
+	key := cacheKey(req)
+	c.mu.Lock()
+	entry := c.entries[key]
+	c.mu.Unlock()
+	if entry != nil && !entry.matches(req) {
+		entry = nil
+	}
+
+	if entry != nil {
+		if c.now().Before(entry.expires) {
+			return entry.response(req), nil
+		}
+		if entry.etag != "" {
+			req.Header.Set("If-None-Match", entry.etag)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		resp.Body.Close()
+		maxAge, cacheable := cacheLifetime(resp.Header)
+		if cacheable {
+			c.store(key, &cacheEntry{
+				header:  entry.header,
+				body:    entry.body,
+				etag:    entry.etag,
+				expires: c.now().Add(maxAge),
+				vary:    entry.vary,
+			})
+		} else {
+			c.mu.Lock()
+			delete(c.entries, key)
+			c.mu.Unlock()
+		}
+		return entry.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+
+	maxAge, cacheable := cacheLifetime(resp.Header)
+	etag := resp.Header.Get("ETag")
+	if !cacheable || (maxAge == 0 && etag == "") {
+		return resp, nil
+	}
+	vary := make(map[string]string)
+	for _, name := range varyHeaders(resp.Header) {
+		vary[name] = strings.Join(req.Header[name], ",")
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	entry = &cacheEntry{
+		header:  resp.Header,
+		body:    body,
+		etag:    etag,
+		expires: c.now().Add(maxAge),
+		vary:    vary,
+	}
+	c.store(key, entry)
+	return entry.response(req), nil
+}
+
+func (c *cachingClient) store(key string, entry *cacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= c.maxEntries {
+		// Evict expired entries first, an arbitrary one if none expired.
+		now := c.now()
+		for k, e := range c.entries {
+			if !now.Before(e.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.maxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	if c.maxEntries > 0 {
+		c.entries[key] = entry
+	}
+}
+
+// matches reports whether req has the values of the request headers which
+// the response of e varies by.
+func (e *cacheEntry) matches(req *http.Request) bool {
+	for name, value := range e.vary {
+		if strings.Join(req.Header[name], ",") != value {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	header := make(http.Header, len(e.header))
+	for k, v := range e.header {
+		header[k] = v
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(e.body)),
+		ContentLength: int64(len(e.body)),
+		Request:       req,
+	}
+}
+
+// cacheLifetime returns how long a response may be used without
+// revalidation according to its Cache-Control header, and whether it may be
+// stored at all. no-store wins over no-cache, which wins over max-age.
+func cacheLifetime(header http.Header) (time.Duration, bool) {
+	for _, name := range varyHeaders(header) {
+		if name == "*" {
+			return 0, false
+		}
+	}
+
+	var maxAge time.Duration
+	var noStore, noCache bool
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+		switch {
+		case directive == "no-store":
+			noStore = true
+		case directive == "no-cache":
+			noCache = true
+		case strings.HasPrefix(directive, "max-age="):
+			seconds, err := strconv.Atoi(directive[len("max-age="):])
+			if err == nil && seconds > 0 {
+				maxAge = time.Duration(seconds) * time.Second
+			}
+		}
+	}
+	switch {
+	case noStore:
+		return 0, false
+	case noCache:
+		return 0, true
+	}
+	return maxAge, true
+}
+
+// varyHeaders returns the canonical names of the request headers listed in
+// the Vary headers of a response.
+func varyHeaders(header http.Header) []string {
+	var names []string
+	for _, value := range header["Vary"] {
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
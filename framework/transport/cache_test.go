@@ -0,0 +1,140 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// countingClient answers every request with a cacheable response and counts
+// the requests.
+type countingClient struct {
+	header http.Header
+	calls  int
+}
+
+func (c *countingClient) Do(req *http.Request) (*http.Response, error) {
+	c.calls++
+	header := make(http.Header)
+	for k, v := range c.header {
+		header[k] = v
+	}
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       ioutil.NopCloser(bytes.NewReader([]byte("{}"))),
+		Request:    req,
+	}, nil
+}
+
+func TestCacheLifetime(t *testing.T) {
+	tests := []struct {
+		cacheControl string
+		vary         string
+		maxAge       time.Duration
+		cacheable    bool
+	}{
+		{cacheControl: "", cacheable: true},
+		{cacheControl: "max-age=60", maxAge: time.Minute, cacheable: true},
+		{cacheControl: "no-cache, max-age=60", cacheable: true},
+		{cacheControl: "no-store", cacheable: false},
+		{cacheControl: "no-cache, no-store", cacheable: false},
+		{cacheControl: "no-store, no-cache", cacheable: false},
+		{cacheControl: "max-age=60", vary: "Accept, *", cacheable: false},
+	}
+
+	for _, test := range tests {
+		header := http.Header{"Cache-Control": {test.cacheControl}}
+		if test.vary != "" {
+			header.Set("Vary", test.vary)
+		}
+		maxAge, cacheable := cacheLifetime(header)
+		if maxAge != test.maxAge || cacheable != test.cacheable {
+			t.Errorf("%q: unexpected lifetime (actual: %v, %t, expected: %v, %t)", test.cacheControl, maxAge, cacheable, test.maxAge, test.cacheable)
+		}
+	}
+}
+
+func TestCachingClientKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		header http.Header
+		first  http.Header
+		second http.Header
+		calls  int
+	}{
+		{
+			name:   "same request",
+			header: http.Header{"Cache-Control": {"max-age=60"}},
+			calls:  1,
+		},
+		{
+			name:   "different API keys",
+			header: http.Header{"Cache-Control": {"max-age=60"}},
+			first:  http.Header{"X-Api-Key": {"a"}},
+			second: http.Header{"X-Api-Key": {"b"}},
+			calls:  2,
+		},
+		{
+			name:   "different cookies",
+			header: http.Header{"Cache-Control": {"max-age=60"}},
+			first:  http.Header{"Cookie": {"session=a"}},
+			second: http.Header{"Cookie": {"session=b"}},
+			calls:  2,
+		},
+		{
+			name:   "varied header",
+			header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Accept, " + xhttp.JSONOptionsHeader}},
+			first:  http.Header{xhttp.JSONOptionsHeader: {"emit_defaults"}},
+			calls:  2,
+		},
+		{
+			name:   "same varied header",
+			header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"Accept, " + xhttp.JSONOptionsHeader}},
+			first:  http.Header{xhttp.JSONOptionsHeader: {"emit_defaults"}},
+			second: http.Header{xhttp.JSONOptionsHeader: {"emit_defaults"}},
+			calls:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend := &countingClient{header: test.header}
+			client := NewCachingClient(backend, 16)
+			for _, header := range []http.Header{test.first, test.second} {
+				req, err := http.NewRequest(http.MethodGet, "http://localhost/hello", nil)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for k, v := range header {
+					req.Header.Set(k, v[0])
+				}
+				resp, err := client.Do(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				resp.Body.Close()
+			}
+
+			if backend.calls != test.calls {
+				t.Fatalf("unexpected number of requests (actual: %d, expected: %d)", backend.calls, test.calls)
+			}
+		})
+	}
+}
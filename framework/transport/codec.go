@@ -0,0 +1,107 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"io/ioutil"
+	"net/http"
+)
+
+// Codec marshals and unmarshals messages of one content type. Generated
+// servers choose the codec by the Content-Type of the request, see
+// WithServerCodec.
+type Codec interface {
+	// ContentType is the media type handled by the codec, e.g. "application/json".
+	ContentType() string
+	Marshal(msg proto.Message) ([]byte, error)
+	Unmarshal(data []byte, msg proto.Message) error
+}
+
+// JSONCodec encodes messages as JSON with jsonpb.
+type JSONCodec struct {
+	Marshaler   jsonpb.Marshaler
+	Unmarshaler jsonpb.Unmarshaler
+}
+
+// NewJSONCodec returns the default JSON codec, which uses the original proto
+// field names and ignores unknown fields.
+func NewJSONCodec() *JSONCodec {
+	return &JSONCodec{
+		Marshaler:   jsonpb.Marshaler{OrigName: true},
+		Unmarshaler: jsonpb.Unmarshaler{AllowUnknownFields: true},
+	}
+}
+
+func (c *JSONCodec) ContentType() string { return xhttp.ApplicationJson }
+
+func (c *JSONCodec) Marshal(msg proto.Message) ([]byte, error) {
+	buff := new(bytes.Buffer)
+	if err := c.Marshaler.Marshal(buff, msg); err != nil {
+		return nil, err
+	}
+	return buff.Bytes(), nil
+}
+
+func (c *JSONCodec) Unmarshal(data []byte, msg proto.Message) error {
+	return c.Unmarshaler.Unmarshal(bytes.NewReader(data), msg)
+}
+
+// ProtobufCodec encodes messages in the protobuf wire format.
+type ProtobufCodec struct{}
+
+func (c ProtobufCodec) ContentType() string { return xhttp.ApplicationProtobuf }
+
+func (c ProtobufCodec) Marshal(msg proto.Message) ([]byte, error) {
+	return proto.Marshal(msg)
+}
+
+func (c ProtobufCodec) Unmarshal(data []byte, msg proto.Message) error {
+	return proto.Unmarshal(data, msg)
+}
+
+// DecodeRequestWith creates a DecodeRequestFunc which reads the request body
+// and unmarshals it with codec. Read errors are classified by ReadError,
+// bodies which can't be unmarshaled are invalid arguments, see DecodeError.
+func DecodeRequestWith(codec Codec) DecodeRequestFunc {
+	return func(ctx context.Context, req *http.Request, content proto.Message) error {
+		buff, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return ReadError(ctx, err)
+		}
+		if err := codec.Unmarshal(buff, content); err != nil {
+			return DecodeError(codec, buff, content, err)
+		}
+		return nil
+	}
+}
+
+// EncodeResponseWith creates an EncodeResponseFunc which marshals responses
+// with codec.
+func EncodeResponseWith(codec Codec) EncodeResponseFunc {
+	return func(ctx context.Context, resp http.ResponseWriter, content proto.Message) error {
+		respBytes, err := codec.Marshal(content)
+		if err != nil {
+			err = errors.WrapErr(err, "failed to marshal "+codecName(codec)+" response")
+			return errors.InternalErrorWith(err)
+		}
+		resp.Header().Set(xhttp.ContentTypeHeader, codec.ContentType())
+		return writeResponse(resp, http.StatusOK, respBytes)
+	}
+}
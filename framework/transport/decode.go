@@ -0,0 +1,212 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/gogo/protobuf/proto"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+// Meta keys of errors of requests which can't be decoded.
+const (
+	// JSONPathMetaKey is the path of the field which can't be decoded,
+	// e.g. "items[1].name".
+	JSONPathMetaKey = "json_path"
+	// JSONOffsetMetaKey is the byte offset of a JSON syntax error.
+	JSONOffsetMetaKey = "json_offset"
+	// FieldNumberMetaKey is the number of the top level field of a protobuf
+	// message which can't be decoded.
+	FieldNumberMetaKey = "field_number"
+)
+
+// ReadError classifies an error returned while reading a request body:
+// errors.Error, e.g. of a body limited by WithMaxRequestBodySize, are passed
+// through, a client which went away yields errors.Canceled and all other
+// errors are internal.
+func ReadError(ctx context.Context, err error) errors.Error {
+	if terr, ok := err.(errors.Error); ok {
+		return terr
+	}
+	if ctx.Err() != nil {
+		return errors.NewError(errors.Canceled, "request canceled while reading body: "+err.Error())
+	}
+	err = errors.WrapErr(err, "failed to read request body")
+	return errors.InternalErrorWith(err)
+}
+
+// decodeError marks the errors of DecodeError, Twirp reports them as
+// errors.Malformed.
+type decodeError struct {
+	terr errors.Error
+}
+
+func (e decodeError) Code() errors.ErrorCode     { return e.terr.Code() }
+func (e decodeError) Msg() string                { return e.terr.Msg() }
+func (e decodeError) Meta(key string) string     { return e.terr.Meta(key) }
+func (e decodeError) MetaMap() map[string]string { return e.terr.MetaMap() }
+func (e decodeError) Error() string              { return e.terr.Error() }
+func (e decodeError) WithMeta(key string, val string) errors.Error {
+	return decodeError{e.terr.WithMeta(key, val)}
+}
+
+// DecodeError returns the errors.InvalidArgument error of a request body data
+// which codec failed to decode into msg with err. The meta of the error
+// points at the offending field, see JSONPathMetaKey and FieldNumberMetaKey.
+func DecodeError(codec Codec, data []byte, msg proto.Message, err error) errors.Error {
+	return decodeError{newDecodeError(codec, data, msg, err)}
+}
+
+func newDecodeError(codec Codec, data []byte, msg proto.Message, err error) errors.Error {
+	terr := errors.NewError(errors.InvalidArgument, "failed to parse request "+codecName(codec)+": "+err.Error())
+	switch codec := codec.(type) {
+	case *JSONCodec:
+		// jsonpb reports syntax errors of field values as well, with offsets
+		// relative to the value.
+		var v interface{}
+		if syntaxErr, ok := json.Unmarshal(data, &v).(*json.SyntaxError); ok {
+			return terr.WithMeta(JSONOffsetMetaKey, strconv.FormatInt(syntaxErr.Offset, 10))
+		}
+		if path := jsonErrorPath(&codec.Unmarshaler, data, reflect.TypeOf(msg)); path != "" {
+			return terr.WithMeta(JSONPathMetaKey, path)
+		}
+	case ProtobufCodec:
+		if number, ok := protoErrorField(data, reflect.TypeOf(msg)); ok {
+			return terr.WithMeta(FieldNumberMetaKey, strconv.FormatInt(int64(number), 10))
+		}
+	}
+	return terr
+}
+
+// jsonErrorPath returns the path of the first field of the JSON object data
+// which fails to decode into a message of type typ, or "" if it can't be
+// determined. Fields are tried one by one in a new message, the path
+// descends into message fields and repeated message fields.
+func jsonErrorPath(u *jsonpb.Unmarshaler, data []byte, typ reflect.Type) string {
+	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		single, err := json.Marshal(map[string]json.RawMessage{key: fields[key]})
+		if err != nil || decodesJSON(u, single, typ) {
+			continue
+		}
+		field, ok := lookupQueryField(typ.Elem(), key)
+		if !ok {
+			return key
+		}
+		fieldType := typ.Elem().Field(field.index).Type
+		if fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Ptr {
+			var elems []json.RawMessage
+			if err := json.Unmarshal(fields[key], &elems); err != nil {
+				return key
+			}
+			for i, elem := range elems {
+				if !decodesJSON(u, elem, fieldType.Elem()) {
+					index := key + "[" + strconv.Itoa(i) + "]"
+					if path := jsonErrorPath(u, elem, fieldType.Elem()); path != "" {
+						return index + "." + path
+					}
+					return index
+				}
+			}
+			return key
+		}
+		if path := jsonErrorPath(u, fields[key], fieldType); path != "" {
+			return key + "." + path
+		}
+		return key
+	}
+	return ""
+}
+
+// decodesJSON reports whether data decodes into a new message of type typ.
+func decodesJSON(u *jsonpb.Unmarshaler, data []byte, typ reflect.Type) bool {
+	if typ.Kind() != reflect.Ptr {
+		return false
+	}
+	msg, ok := reflect.New(typ.Elem()).Interface().(proto.Message)
+	if !ok {
+		return false
+	}
+	return u.Unmarshal(bytes.NewReader(data), msg) == nil
+}
+
+// protoErrorField returns the number of the first top level field of data
+// which fails to decode into a message of type typ. A field which can't even
+// be delimited is reported as well.
+func protoErrorField(data []byte, typ reflect.Type) (int32, bool) {
+	if typ.Kind() != reflect.Ptr {
+		return 0, false
+	}
+	for offset := 0; offset < len(data); {
+		key, n := proto.DecodeVarint(data[offset:])
+		if n == 0 {
+			return 0, false
+		}
+		number := int32(key >> 3)
+		end, ok := skipProtoField(data, offset+n, int(key&7))
+		if !ok {
+			return number, true
+		}
+
+		msg, ok := reflect.New(typ.Elem()).Interface().(proto.Message)
+		if !ok {
+			return 0, false
+		}
+		if err := proto.Unmarshal(data[offset:end], msg); err != nil {
+			return number, true
+		}
+		offset = end
+	}
+	return 0, false
+}
+
+// skipProtoField returns the end of the value of wire type wireType at
+// offset, groups are deprecated and not supported.
+func skipProtoField(data []byte, offset int, wireType int) (int, bool) {
+	switch wireType {
+	case proto.WireVarint:
+		_, n := proto.DecodeVarint(data[offset:])
+		return offset + n, n > 0
+	case proto.WireFixed64:
+		return offset + 8, offset+8 <= len(data)
+	case proto.WireBytes:
+		length, n := proto.DecodeVarint(data[offset:])
+		if n == 0 || length > uint64(len(data)-offset-n) {
+			return 0, false
+		}
+		return offset + n + int(length), true
+	case proto.WireFixed32:
+		return offset + 4, offset+4 <= len(data)
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/gogo/protobuf/proto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type decodeMessage struct {
+	Child *jsonMessage   `protobuf:"bytes,1,opt,name=child,proto3" json:"child,omitempty"`
+	Items []*jsonMessage `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (m *decodeMessage) Reset()         { *m = decodeMessage{} }
+func (m *decodeMessage) String() string { return proto.CompactTextString(m) }
+func (*decodeMessage) ProtoMessage()    {}
+
+func TestDecodeJSONError(t *testing.T) {
+	tests := []struct {
+		body     string
+		metaKey  string
+		expected string
+	}{
+		{body: `{"child": {"count": "many"}}`, metaKey: JSONPathMetaKey, expected: "child.count"},
+		{body: `{"child": {}, "items": [{}, {"color": "COLOR_PURPLE"}]}`, metaKey: JSONPathMetaKey, expected: "items[1].color"},
+		{body: `{"items": {}}`, metaKey: JSONPathMetaKey, expected: "items"},
+		{body: `{"child": {]`, metaKey: JSONOffsetMetaKey, expected: "12"},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+		err := DecodeJSONRequest(req.Context(), req, new(decodeMessage))
+		terr, ok := err.(errors.Error)
+		if !ok || terr.Code() != errors.InvalidArgument {
+			t.Fatalf("%s: unexpected error (actual: %v, expected: invalid_argument)", test.body, err)
+		}
+		if actual := terr.Meta(test.metaKey); actual != test.expected {
+			t.Fatalf(`%s: unexpected %s (actual: "%s", expected: "%s")`, test.body, test.metaKey, actual, test.expected)
+		}
+	}
+}
+
+func TestDecodeProtoError(t *testing.T) {
+	// display_name = "Gopher" followed by count without its value.
+	body := "\x0a\x06Gopher\x18"
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	err := DecodePROTORequest(req.Context(), req, new(jsonMessage))
+	terr, ok := err.(errors.Error)
+	if !ok || terr.Code() != errors.InvalidArgument {
+		t.Fatalf("unexpected error (actual: %v, expected: invalid_argument)", err)
+	}
+	if actual := terr.Meta(FieldNumberMetaKey); actual != "3" {
+		t.Fatalf(`unexpected field number (actual: "%s", expected: "3")`, actual)
+	}
+}
+
+func TestReadError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if terr := ReadError(ctx, context.Canceled); terr.Code() != errors.Canceled {
+		t.Fatalf("unexpected error code (actual: %s, expected: canceled)", terr.Code())
+	}
+
+	passed := errors.NewError(errors.ResourceExhausted, "request body too large")
+	if terr := ReadError(context.Background(), passed); terr != passed {
+		t.Fatalf("error not passed through (actual: %v)", terr)
+	}
+
+	if terr := ReadError(context.Background(), http.ErrBodyReadAfterClose); terr.Code() != errors.Internal {
+		t.Fatalf("unexpected error code (actual: %s, expected: internal)", terr.Code())
+	}
+}
@@ -0,0 +1,47 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import "context"
+
+// Method is a call of a service method, request and response are the
+// generated message types of the method, e.g. *HelloReq and *HelloResp.
+type Method func(ctx context.Context, request interface{}) (interface{}, error)
+
+// Interceptor wraps a Method. It can inspect or modify the context and the
+// request before calling next, and the response or error afterwards. Unlike
+// hooks, interceptors have access to the messages.
+//
+//   func logging(next transport.Method) transport.Method {
+//       return func(ctx context.Context, request interface{}) (interface{}, error) {
+//           method, _ := xcontext.MethodName(ctx)
+//           response, err := next(ctx, request)
+//           log.Printf("%s: %v", method, err)
+//           return response, err
+//       }
+//   }
+type Interceptor func(next Method) Method
+
+// ChainInterceptors creates an Interceptor which calls interceptors in the
+// given order, the first one is the outermost.
+func ChainInterceptors(interceptors ...Interceptor) Interceptor {
+	return func(next Method) Method {
+		for i := len(interceptors) - 1; i >= 0; i-- {
+			if interceptors[i] != nil {
+				next = interceptors[i](next)
+			}
+		}
+		return next
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"github.com/gogo/protobuf/jsonpb"
+	"net/http"
+	"strings"
+)
+
+// JSONOptions configure the JSON encoding of messages. The zero value is the
+// default encoding: original proto field names, enums as strings, fields
+// with default values omitted and unknown fields ignored.
+type JSONOptions struct {
+	// EmitDefaults includes fields with default values, e.g. "count": 0.
+	EmitDefaults bool
+	// EnumsAsInts encodes enums by number instead of by name.
+	EnumsAsInts bool
+	// CamelCase uses the lowerCamelCase JSON names of fields instead of
+	// their original proto names.
+	CamelCase bool
+	// Indent indents the output by the given string per level.
+	Indent string
+	// DisallowUnknownFields fails decoding of messages with unknown fields.
+	DisallowUnknownFields bool
+}
+
+// Codec returns the JSON codec of the options.
+func (o JSONOptions) Codec() *JSONCodec {
+	return &JSONCodec{
+		Marshaler: jsonpb.Marshaler{
+			OrigName:     !o.CamelCase,
+			EnumsAsInts:  o.EnumsAsInts,
+			EmitDefaults: o.EmitDefaults,
+			Indent:       o.Indent,
+		},
+		Unmarshaler: jsonpb.Unmarshaler{AllowUnknownFields: !o.DisallowUnknownFields},
+	}
+}
+
+// JSONIndent is the indent used by the "indent" option of the
+// XService-JSON-Options header.
+const JSONIndent = "  "
+
+// ParseJSONOptions applies the comma separated options of an
+// XService-JSON-Options header, e.g. "emit_defaults, enums_as_ints", to base.
+// Options are "emit_defaults", "enums_as_ints", "camel_case", "indent" and
+// "strict". Options of the header can only be enabled, not disabled.
+func ParseJSONOptions(header string, base JSONOptions) (JSONOptions, error) {
+	options := base
+	for _, option := range strings.Split(header, ",") {
+		switch strings.ToLower(strings.TrimSpace(option)) {
+		case "":
+		case "emit_defaults":
+			options.EmitDefaults = true
+		case "enums_as_ints":
+			options.EnumsAsInts = true
+		case "camel_case":
+			options.CamelCase = true
+		case "indent":
+			options.Indent = JSONIndent
+		case "strict":
+			options.DisallowUnknownFields = true
+		default:
+			return base, errors.InvalidArgumentError(xhttp.JSONOptionsHeader, "has unknown option "+strings.TrimSpace(option))
+		}
+	}
+	return options, nil
+}
+
+// requestJSONOptions applies the XService-JSON-Options header of req to base.
+func requestJSONOptions(req *http.Request, base JSONOptions) (JSONOptions, error) {
+	header := req.Header.Get(xhttp.JSONOptionsHeader)
+	if header == "" {
+		return base, nil
+	}
+	return ParseJSONOptions(header, base)
+}
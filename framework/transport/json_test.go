@@ -0,0 +1,169 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"github.com/gogo/protobuf/proto"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files of testdata")
+
+type jsonColor int32
+
+const (
+	jsonColorUnknown jsonColor = 0
+	jsonColorRed     jsonColor = 1
+)
+
+var jsonColorName = map[int32]string{0: "COLOR_UNKNOWN", 1: "COLOR_RED"}
+
+var jsonColorValue = map[string]int32{"COLOR_UNKNOWN": 0, "COLOR_RED": 1}
+
+func (c jsonColor) String() string { return proto.EnumName(jsonColorName, int32(c)) }
+
+func init() {
+	proto.RegisterEnum("transport.jsonColor", jsonColorName, jsonColorValue)
+}
+
+// jsonMessage is a handwritten message covering the JSON options: a field
+// whose JSON name differs from its proto name, an enum and a field with a
+// default value.
+type jsonMessage struct {
+	DisplayName string    `protobuf:"bytes,1,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Color       jsonColor `protobuf:"varint,2,opt,name=color,proto3,enum=transport.jsonColor" json:"color,omitempty"`
+	Count       int32     `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (m *jsonMessage) Reset()         { *m = jsonMessage{} }
+func (m *jsonMessage) String() string { return proto.CompactTextString(m) }
+func (*jsonMessage) ProtoMessage()    {}
+
+func TestJSONOptionsGolden(t *testing.T) {
+	tests := []struct {
+		name    string
+		options JSONOptions
+	}{
+		{name: "default"},
+		{name: "emit_defaults", options: JSONOptions{EmitDefaults: true}},
+		{name: "enums_as_ints", options: JSONOptions{EnumsAsInts: true}},
+		{name: "camel_case", options: JSONOptions{CamelCase: true}},
+		{name: "indent", options: JSONOptions{Indent: JSONIndent}},
+	}
+
+	msg := &jsonMessage{DisplayName: "Gopher", Color: jsonColorRed}
+	for _, test := range tests {
+		actual, err := test.options.Codec().Marshal(msg)
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		golden := filepath.Join("testdata", "json", test.name+".golden")
+		if *update {
+			if err := ioutil.WriteFile(golden, actual, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		expected, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(actual, expected) {
+			t.Fatalf("%s: unexpected json (actual: %s, expected: %s)", test.name, actual, expected)
+		}
+
+		decoded := new(jsonMessage)
+		if err := test.options.Codec().Unmarshal(actual, decoded); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if !proto.Equal(decoded, msg) {
+			t.Fatalf("%s: unexpected message (actual: %v, expected: %v)", test.name, decoded, msg)
+		}
+	}
+}
+
+func TestJSONOptionsDisallowUnknownFields(t *testing.T) {
+	data := []byte(`{"display_name": "Gopher", "unknown": true}`)
+
+	if err := (JSONOptions{}).Codec().Unmarshal(data, new(jsonMessage)); err != nil {
+		t.Fatalf("unknown field rejected by default: %v", err)
+	}
+	if err := (JSONOptions{DisallowUnknownFields: true}).Codec().Unmarshal(data, new(jsonMessage)); err == nil {
+		t.Fatal("unknown field accepted")
+	}
+}
+
+func TestParseJSONOptions(t *testing.T) {
+	options, err := ParseJSONOptions("emit_defaults, Camel_Case,strict", JSONOptions{EnumsAsInts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := JSONOptions{EmitDefaults: true, EnumsAsInts: true, CamelCase: true, DisallowUnknownFields: true}
+	if options != expected {
+		t.Fatalf("unexpected options (actual: %+v, expected: %+v)", options, expected)
+	}
+
+	_, err = ParseJSONOptions("emit_defaults,unknown", JSONOptions{})
+	if terr, ok := err.(errors.Error); !ok || terr.Code() != errors.InvalidArgument {
+		t.Fatalf("unexpected error (actual: %v, expected: invalid_argument)", err)
+	}
+}
+
+func TestJSONOptionsHeader(t *testing.T) {
+	options := NewServerOptions(WithServerJSONOptions(JSONOptions{EmitDefaults: true}))
+
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"displayName": "Gopher"}`))
+	req.Header.Set(xhttp.ContentTypeHeader, xhttp.ApplicationJson+"; charset=utf-8")
+	req.Header.Set(xhttp.JSONOptionsHeader, "enums_as_ints,camel_case")
+
+	codec, err := options.RequestCodec(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := new(jsonMessage)
+	if err := DecodeRequestWith(codec)(context.Background(), req, msg); err != nil {
+		t.Fatal(err)
+	}
+
+	resp := httptest.NewRecorder()
+	ctx := xcontext.WithHTTPRequest(context.Background(), req)
+	if err := options.EncodeJSONResponse(ctx, resp, msg); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"displayName":"Gopher","color":0,"count":0}`
+	if body := resp.Body.String(); body != expected {
+		t.Fatalf(`unexpected body (actual: "%s", expected: "%s")`, body, expected)
+	}
+
+	req.Header.Set(xhttp.JSONOptionsHeader, "unknown")
+	if _, err := options.RequestCodec(req); err == nil {
+		t.Fatal("unknown option accepted")
+	}
+
+	req.Header.Set(xhttp.ContentTypeHeader, "text/plain")
+	if _, err := options.RequestCodec(req); err == nil {
+		t.Fatal("unexpected Content-Type accepted")
+	}
+}
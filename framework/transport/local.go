@@ -0,0 +1,120 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"fmt"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"github.com/gogo/protobuf/proto"
+	"net/http"
+	"reflect"
+)
+
+// WithLocalRoundTrip makes in-process clients, see
+// New<Service>LocalClientWithOptions, pass copies of requests and responses
+// which are encoded and decoded with codec. Callers and services don't share
+// messages then, which catches aliasing bugs and messages the codec can't
+// encode. Servers ignore it.
+func WithLocalRoundTrip(codec Codec) ServerOption {
+	return func(o *ServerOptions) {
+		o.localCodec = codec
+	}
+}
+
+// CallLocal calls a method of a service in-process, like a generated server
+// serves it: the package, service and method names are set in the context,
+// the hooks are called, panics are recovered and errors are returned as
+// errors.Error. The status code in the context of the hooks is the one a
+// server would respond. There is no HTTP request, so hooks and interceptors
+// don't find one in the context.
+//
+// Generated in-process clients call it, call is the method of the service.
+func (o *ServerOptions) CallLocal(ctx context.Context, packageName, serviceName, methodName string, in proto.Message, call Method) (out interface{}, err error) {
+	h := o.Hooks()
+	ctx = xcontext.WithPackageName(ctx, packageName)
+	ctx = xcontext.WithServiceName(ctx, serviceName)
+	defer func() {
+		if r := recover(); r != nil {
+			out, err = nil, o.localError(ctx, o.RecoverPanic(ctx, r), h)
+		}
+	}()
+
+	ctx, err = CallRequestReceived(ctx, h)
+	if err != nil {
+		return nil, o.localError(ctx, err, h)
+	}
+	ctx = xcontext.WithMethodName(ctx, methodName)
+	ctx, err = CallRequestRouted(ctx, h)
+	if err != nil {
+		return nil, o.localError(ctx, err, h)
+	}
+
+	req, err := o.localRoundTrip(in)
+	if err != nil {
+		return nil, o.localError(ctx, err, h)
+	}
+
+	resp, err := o.Intercept(call)(ctx, req)
+	if err != nil {
+		return nil, o.localError(ctx, err, h)
+	}
+	msg, ok := resp.(proto.Message)
+	if !ok || reflect.ValueOf(msg).IsNil() {
+		err := errors.InternalError(fmt.Sprintf("received a nil response and nil error while calling %s. nil responses are not supported", methodName))
+		return nil, o.localError(ctx, err, h)
+	}
+
+	ctx = CallResponsePrepared(ctx, h)
+	out, err = o.localRoundTrip(msg)
+	if err != nil {
+		return nil, o.localError(ctx, err, h)
+	}
+	CallResponseSent(xcontext.WithStatusCode(ctx, http.StatusOK), h)
+	return out, nil
+}
+
+// localRoundTrip returns msg, or a copy of msg encoded and decoded with the
+// codec of WithLocalRoundTrip.
+func (o *ServerOptions) localRoundTrip(msg proto.Message) (proto.Message, error) {
+	if o.localCodec == nil {
+		return msg, nil
+	}
+
+	data, err := o.localCodec.Marshal(msg)
+	if err != nil {
+		return nil, errors.InternalErrorWith(err)
+	}
+	decoded := reflect.New(reflect.TypeOf(msg).Elem()).Interface().(proto.Message)
+	if err := o.localCodec.Unmarshal(data, decoded); err != nil {
+		return nil, errors.InternalErrorWith(err)
+	}
+	return decoded, nil
+}
+
+// localError returns err as errors.Error and triggers the hooks like a
+// server which writes the error.
+func (o *ServerOptions) localError(ctx context.Context, err error, h *hooks.ServerHooks) errors.Error {
+	terr := toError(err)
+	statusFromErrorCode := errors.ServerHTTPStatusFromErrorCode
+	if o.twirp {
+		statusFromErrorCode = TwirpHTTPStatusFromErrorCode
+	}
+	ctx = xcontext.WithStatusCode(ctx, statusFromErrorCode(terr.Code()))
+	ctx = CallError(ctx, h, terr)
+	CallResponseSent(ctx, h)
+	return terr
+}
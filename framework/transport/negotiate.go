@@ -0,0 +1,174 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Meta keys of negotiation errors.
+const (
+	ContentTypeMetaKey = "content_type"
+	AcceptMetaKey      = "accept"
+)
+
+// mediaRange is an entry of an Accept header, e.g. "application/*;q=0.5".
+type mediaRange struct {
+	typ     string
+	subtype string
+	q       float64
+}
+
+// parseAccept parses the media ranges of an Accept header, ranges which
+// can't be parsed are ignored.
+func parseAccept(header string) []mediaRange {
+	var ranges []mediaRange
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		mediaType := strings.ToLower(strings.TrimSpace(params[0]))
+		i := strings.Index(mediaType, "/")
+		if i <= 0 || i == len(mediaType)-1 {
+			continue
+		}
+
+		r := mediaRange{typ: mediaType[:i], subtype: mediaType[i+1:], q: 1}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(strings.ToLower(param), "q=") {
+				continue
+			}
+			if q, err := strconv.ParseFloat(param[len("q="):], 64); err == nil && q >= 0 && q <= 1 {
+				r.q = q
+			}
+		}
+		ranges = append(ranges, r)
+	}
+	return ranges
+}
+
+// quality returns the q-value of contentType by the most specific of
+// ranges matching it, or 0 if none matches.
+func quality(ranges []mediaRange, contentType string) float64 {
+	i := strings.Index(contentType, "/")
+	if i < 0 {
+		return 0
+	}
+	typ, subtype := contentType[:i], contentType[i+1:]
+
+	q, specificity := 0.0, -1
+	for _, r := range ranges {
+		s := -1
+		switch {
+		case r.typ == typ && r.subtype == subtype:
+			s = 2
+		case r.typ == typ && r.subtype == "*":
+			s = 1
+		case r.typ == "*" && r.subtype == "*":
+			s = 0
+		}
+		if s > specificity {
+			q, specificity = r.q, s
+		}
+	}
+	return q
+}
+
+// mediaType returns the lower case media type of a Content-Type header
+// without parameters.
+func mediaType(header string) string {
+	if i := strings.Index(header, ";"); i != -1 {
+		header = header[:i]
+	}
+	return strings.TrimSpace(strings.ToLower(header))
+}
+
+// RequestCodec returns the codec for the Content-Type of req, requests
+// without Content-Type are JSON. The JSON codec honors the
+// XService-JSON-Options header of req. Unsupported content types fail with
+// errors.UnsupportedMediaType.
+func (o *ServerOptions) RequestCodec(req *http.Request) (Codec, error) {
+	header := req.Header.Get(xhttp.ContentTypeHeader)
+	contentType := mediaType(header)
+	if contentType == "" || contentType == xhttp.ApplicationJson {
+		return o.jsonCodec(req)
+	}
+	codec, ok := o.codecs[contentType]
+	if !ok {
+		err := errors.NewError(errors.UnsupportedMediaType, "unsupported Content-Type: "+strconv.Quote(header))
+		return nil, err.WithMeta(ContentTypeMetaKey, header)
+	}
+	return codec, nil
+}
+
+// ResponseCodec chooses the codec of the response by the Accept header of
+// req. The codec with the highest q-value wins, the request codec is
+// preferred over others of the same q-value, followed by JSON, protobuf and
+// the codecs of WithServerCodec. Requests without Accept header are answered
+// with requestCodec, or JSON if it is nil. If no codec is acceptable, it
+// fails with errors.NotAcceptable. Twirp has no Accept negotiation, servers
+// in Twirp compatibility mode ignore the header.
+func (o *ServerOptions) ResponseCodec(req *http.Request, requestCodec Codec) (Codec, error) {
+	header := req.Header.Get("Accept")
+	if strings.TrimSpace(header) == "" || o.twirp {
+		if requestCodec != nil {
+			return requestCodec, nil
+		}
+		return o.jsonCodec(req)
+	}
+
+	var best string
+	var bestQ float64
+	ranges := parseAccept(header)
+	for _, contentType := range o.responseContentTypes(requestCodec) {
+		if q := quality(ranges, contentType); q > bestQ {
+			best, bestQ = contentType, q
+		}
+	}
+
+	switch {
+	case best == "":
+		err := errors.NewError(errors.NotAcceptable, "no acceptable response Content-Type for Accept: "+strconv.Quote(header))
+		return nil, err.WithMeta(AcceptMetaKey, header)
+	case requestCodec != nil && best == requestCodec.ContentType():
+		return requestCodec, nil
+	case best == xhttp.ApplicationJson:
+		return o.jsonCodec(req)
+	default:
+		return o.codecs[best], nil
+	}
+}
+
+// responseContentTypes returns the content types of the response codecs in
+// the order of preference.
+func (o *ServerOptions) responseContentTypes(requestCodec Codec) []string {
+	var custom []string
+	for contentType := range o.codecs {
+		if contentType != xhttp.ApplicationJson && contentType != xhttp.ApplicationProtobuf {
+			custom = append(custom, contentType)
+		}
+	}
+	sort.Strings(custom)
+
+	contentTypes := make([]string, 0, len(custom)+3)
+	if requestCodec != nil {
+		contentTypes = append(contentTypes, requestCodec.ContentType())
+	}
+	contentTypes = append(contentTypes, xhttp.ApplicationJson, xhttp.ApplicationProtobuf)
+	return append(contentTypes, custom...)
+}
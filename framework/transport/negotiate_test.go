@@ -0,0 +1,73 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type textCodec struct{ ProtobufCodec }
+
+func (textCodec) ContentType() string { return "text/plain" }
+
+func TestNegotiation(t *testing.T) {
+	tests := []struct {
+		contentType string
+		accept      string
+		request     string
+		response    string
+		code        errors.ErrorCode
+	}{
+		{contentType: "", request: xhttp.ApplicationJson, response: xhttp.ApplicationJson},
+		{contentType: "Application/Protobuf; charset=utf-8", request: xhttp.ApplicationProtobuf, response: xhttp.ApplicationProtobuf},
+		{contentType: xhttp.ApplicationJson, accept: "application/protobuf", request: xhttp.ApplicationJson, response: xhttp.ApplicationProtobuf},
+		{contentType: xhttp.ApplicationProtobuf, accept: "*/*", request: xhttp.ApplicationProtobuf, response: xhttp.ApplicationProtobuf},
+		{contentType: xhttp.ApplicationProtobuf, accept: "application/*", request: xhttp.ApplicationProtobuf, response: xhttp.ApplicationProtobuf},
+		{contentType: xhttp.ApplicationProtobuf, accept: "application/json;q=0.9, application/protobuf;q=0.5", request: xhttp.ApplicationProtobuf, response: xhttp.ApplicationJson},
+		{contentType: xhttp.ApplicationJson, accept: "text/html, */*;q=0.1", request: xhttp.ApplicationJson, response: xhttp.ApplicationJson},
+		{contentType: xhttp.ApplicationJson, accept: "text/*", request: xhttp.ApplicationJson, response: "text/plain"},
+		{contentType: xhttp.ApplicationJson, accept: "*/*, application/json;q=0", request: xhttp.ApplicationJson, response: xhttp.ApplicationProtobuf},
+		{contentType: xhttp.ApplicationJson, accept: "image/png", code: errors.NotAcceptable},
+		{contentType: "application/xml", code: errors.UnsupportedMediaType},
+	}
+
+	options := NewServerOptions(WithServerCodec(textCodec{}))
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(xhttp.ContentTypeHeader, test.contentType)
+		req.Header.Set("Accept", test.accept)
+
+		requestCodec, err := options.RequestCodec(req)
+		var responseCodec Codec
+		if err == nil {
+			responseCodec, err = options.ResponseCodec(req, requestCodec)
+		}
+		if test.code != errors.NoError {
+			if terr, ok := err.(errors.Error); !ok || terr.Code() != test.code {
+				t.Fatalf("%q, %q: unexpected error (actual: %v, expected: %s)", test.contentType, test.accept, err, test.code)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%q, %q: %v", test.contentType, test.accept, err)
+		}
+		if requestCodec.ContentType() != test.request || responseCodec.ContentType() != test.response {
+			t.Fatalf("%q, %q: unexpected codecs (actual: %s, %s, expected: %s, %s)", test.contentType, test.accept, requestCodec.ContentType(), responseCodec.ContentType(), test.request, test.response)
+		}
+	}
+}
@@ -0,0 +1,483 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"github.com/gogo/protobuf/proto"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// DefaultPathPrefix is the route prefix of generated services, unless the
+// path_prefix parameter of protoc-gen-xservice or a path prefix option is
+// used.
+const DefaultPathPrefix = "/xservice"
+
+// ServicePathPrefix joins a route prefix like "/api/v2" and the fully
+// qualified name of a service to the prefix of its routes, e.g.
+// "/api/v2/example.HelloWorld/". An empty prefix puts the routes at the root.
+func ServicePathPrefix(prefix, fullServiceName string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return "/" + fullServiceName + "/"
+	}
+	return "/" + prefix + "/" + fullServiceName + "/"
+}
+
+// ServerOptions configure generated servers, see the
+// New<Service>ServerWithOptions constructors.
+type ServerOptions struct {
+	pathPrefix    string
+	hasPathPrefix bool
+	twirp         bool
+	hooks         []*hooks.ServerHooks
+	logErrorFunc  LogErrorFunc
+	codecs        map[string]Codec
+	jsonOptions   JSONOptions
+	interceptors  []Interceptor
+	maxBodySize   int64
+	panicHandler  PanicHandler
+	rePanic       bool
+
+	ignoreClientVersion bool
+
+	// Codec of the round trips of in-process clients, see
+	// WithLocalRoundTrip.
+	localCodec Codec
+}
+
+// ServerOption configures ServerOptions.
+type ServerOption func(*ServerOptions)
+
+// NewServerOptions applies opts to the default options.
+func NewServerOptions(opts ...ServerOption) *ServerOptions {
+	options := &ServerOptions{
+		logErrorFunc: log.Printf,
+		codecs: map[string]Codec{
+			xhttp.ApplicationProtobuf: ProtobufCodec{},
+		},
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithServerPathPrefix replaces the route prefix chosen at generation time,
+// e.g. "/twirp" or "/api/v2".
+func WithServerPathPrefix(prefix string) ServerOption {
+	return func(o *ServerOptions) {
+		o.pathPrefix = prefix
+		o.hasPathPrefix = true
+	}
+}
+
+// WithServerTwirpCompatibility makes the server speak Twirp v7's protocol:
+// routes below "/twirp" (unless WithServerPathPrefix is given as well),
+// Twirp's error status mapping and "twirp_invalid_route" meta, JSON
+// responses including fields with default values, and only POST requests.
+func WithServerTwirpCompatibility() ServerOption {
+	return func(o *ServerOptions) {
+		o.twirp = true
+	}
+}
+
+// WithServerHooks adds hooks to the server, hooks of several options are
+// chained in the given order, see hooks.ChainHooks.
+func WithServerHooks(hooks *hooks.ServerHooks) ServerOption {
+	return func(o *ServerOptions) {
+		if hooks != nil {
+			o.hooks = append(o.hooks, hooks)
+		}
+	}
+}
+
+// WithServerLogger replaces log.Printf as logger of critical errors.
+func WithServerLogger(logErrorFunc LogErrorFunc) ServerOption {
+	return func(o *ServerOptions) {
+		o.logErrorFunc = logErrorFunc
+	}
+}
+
+// WithServerCodec registers codec for requests with its content type,
+// replacing the default codec of "application/json" or
+// "application/protobuf", or adding a new content type. A JSON codec
+// replaces the encoding configured with WithServerJSONOptions and the
+// XService-JSON-Options header.
+func WithServerCodec(codec Codec) ServerOption {
+	return func(o *ServerOptions) {
+		o.codecs[codec.ContentType()] = codec
+	}
+}
+
+// WithServerJSONOptions configures the JSON encoding of requests and
+// responses. Clients can enable further options per request with the
+// XService-JSON-Options header, see ParseJSONOptions.
+func WithServerJSONOptions(options JSONOptions) ServerOption {
+	return func(o *ServerOptions) {
+		o.jsonOptions = options
+	}
+}
+
+// WithServerInterceptors adds interceptors around the calls of service
+// methods, see ChainInterceptors.
+func WithServerInterceptors(interceptors ...Interceptor) ServerOption {
+	return func(o *ServerOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithMaxRequestBodySize limits request bodies to size bytes, larger
+// requests fail with errors.ResourceExhausted. Bodies are not limited by
+// default.
+func WithMaxRequestBodySize(size int64) ServerOption {
+	return func(o *ServerOptions) {
+		o.maxBodySize = size
+	}
+}
+
+// WithServerPanicHandler sets a handler called with panics recovered while
+// serving a request, e.g. to report them.
+func WithServerPanicHandler(handler PanicHandler) ServerOption {
+	return func(o *ServerOptions) {
+		o.panicHandler = handler
+	}
+}
+
+// WithServerRePanic propagates recovered panics after the panic handler was
+// called, instead of responding with an internal error and continue
+// serving. It restores the behaviour of servers before panic recovery was
+// configurable and should only be used with a recovery further up, e.g. by
+// net/http.
+func WithServerRePanic() ServerOption {
+	return func(o *ServerOptions) {
+		o.rePanic = true
+	}
+}
+
+// WithServerIgnoreClientVersion accepts clients of all protocol versions,
+// see CheckVersion.
+func WithServerIgnoreClientVersion() ServerOption {
+	return func(o *ServerOptions) {
+		o.ignoreClientVersion = true
+	}
+}
+
+// ServicePathPrefix returns the prefix of the routes of the service named
+// fullServiceName, generated is the <Service>PathPrefix constant used if no
+// path prefix option was given.
+func (o *ServerOptions) ServicePathPrefix(generated, fullServiceName string) string {
+	return servicePathPrefix(o.pathPrefix, o.hasPathPrefix, o.twirp, generated, fullServiceName)
+}
+
+// Hooks returns the chained hooks of the server.
+func (o *ServerOptions) Hooks() *hooks.ServerHooks {
+	return hooks.ChainHooks(o.hooks...)
+}
+
+// Logger returns the logger of critical errors.
+func (o *ServerOptions) Logger() LogErrorFunc {
+	return o.logErrorFunc
+}
+
+// Codec returns the codec registered for contentType, which has to be
+// lower case without parameters.
+func (o *ServerOptions) Codec(contentType string) (Codec, bool) {
+	if codec, ok := o.codecs[contentType]; ok {
+		return codec, true
+	}
+	if contentType == xhttp.ApplicationJson {
+		return o.serverJSONOptions().Codec(), true
+	}
+	return nil, false
+}
+
+// jsonCodec returns the JSON codec for req, which may be nil.
+func (o *ServerOptions) jsonCodec(req *http.Request) (Codec, error) {
+	if codec, ok := o.codecs[xhttp.ApplicationJson]; ok {
+		return codec, nil
+	}
+	if req == nil {
+		return o.serverJSONOptions().Codec(), nil
+	}
+	options, err := requestJSONOptions(req, o.serverJSONOptions())
+	if err != nil {
+		return nil, err
+	}
+	return options.Codec(), nil
+}
+
+// Intercept wraps method with the interceptors of the server.
+func (o *ServerOptions) Intercept(method Method) Method {
+	if len(o.interceptors) == 0 {
+		return method
+	}
+	return ChainInterceptors(o.interceptors...)(method)
+}
+
+// LimitRequestBody limits the body of req to the size set with
+// WithMaxRequestBodySize.
+func (o *ServerOptions) LimitRequestBody(req *http.Request) {
+	if o.maxBodySize <= 0 || req.Body == nil {
+		return
+	}
+	req.Body = &limitedBody{ReadCloser: req.Body, remaining: o.maxBodySize, max: o.maxBodySize}
+}
+
+// CheckMethod returns errors.BadRoute for requests which are neither POST
+// nor, if allowGet is true, GET. Twirp servers only accept POST.
+//
+// Generated servers call it with allowGet if one of their methods can be
+// called with GET, see the idempotency_level option.
+func (o *ServerOptions) CheckMethod(req *http.Request, allowGet bool) error {
+	if req.Method == http.MethodPost {
+		return nil
+	}
+	if !allowGet || o.twirp {
+		msg := "unsupported method " + strconv.Quote(req.Method) + " (only POST is allowed)"
+		return errors.BadRouteError(msg, req.Method, req.URL.Path)
+	}
+	if req.Method != http.MethodGet {
+		msg := "unsupported method " + strconv.Quote(req.Method) + " (only POST and GET are allowed)"
+		return errors.BadRouteError(msg, req.Method, req.URL.Path)
+	}
+	return nil
+}
+
+// WriteError writes err in the response and triggers hooks, see
+// WriteErrorAndTriggerHooks and WriteTwirpErrorAndTriggerHooks.
+func (o *ServerOptions) WriteError(ctx context.Context, resp http.ResponseWriter, err error, hooks *hooks.ServerHooks) {
+	if o.twirp {
+		WriteTwirpErrorAndTriggerHooks(ctx, resp, err, hooks)
+		return
+	}
+	WriteErrorAndTriggerHooks(ctx, resp, err, hooks)
+}
+
+// serverJSONOptions returns the JSON options of the server, Twirp always
+// emits fields with default values.
+func (o *ServerOptions) serverJSONOptions() JSONOptions {
+	options := o.jsonOptions
+	if o.twirp {
+		options.EmitDefaults = true
+	}
+	return options
+}
+
+// EncodeJSONResponse is the EncodeResponseFunc of the JSON codec, it honors
+// the XService-JSON-Options header of the request stored in ctx.
+func (o *ServerOptions) EncodeJSONResponse(ctx context.Context, resp http.ResponseWriter, content proto.Message) error {
+	req, _ := xcontext.HTTPRequest(ctx)
+	codec, err := o.jsonCodec(req)
+	if err != nil {
+		return err
+	}
+	return EncodeResponseWith(codec)(ctx, resp, content)
+}
+
+// limitedBody fails with errors.ResourceExhausted once more than max bytes
+// are read.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	max       int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, b.err()
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return n + int(b.remaining), b.err()
+	}
+	return n, err
+}
+
+func (b *limitedBody) err() error {
+	err := errors.NewError(errors.ResourceExhausted, "request body too large")
+	return err.WithMeta("max_body_size", strconv.FormatInt(b.max, 10))
+}
+
+// ClientOptions configure generated clients, see the
+// New<Service><Encoding>ClientWithOptions constructors.
+type ClientOptions struct {
+	pathPrefix    string
+	hasPathPrefix bool
+	twirp         bool
+	interceptors  []Interceptor
+	jsonOptions   JSONOptions
+	versionCheck  bool
+	codec         Codec
+}
+
+// ClientOption configures ClientOptions.
+type ClientOption func(*ClientOptions)
+
+// NewClientOptions applies opts to the default options.
+func NewClientOptions(opts ...ClientOption) *ClientOptions {
+	options := &ClientOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithClientPathPrefix replaces the route prefix chosen at generation time,
+// it has to match the prefix of the server.
+func WithClientPathPrefix(prefix string) ClientOption {
+	return func(o *ClientOptions) {
+		o.pathPrefix = prefix
+		o.hasPathPrefix = true
+	}
+}
+
+// WithClientTwirpCompatibility makes the client talk to Twirp v7 servers:
+// routes below "/twirp" (unless WithClientPathPrefix is given as well) and
+// the Twirp-Version header instead of XService-Version.
+func WithClientTwirpCompatibility() ClientOption {
+	return func(o *ClientOptions) {
+		o.twirp = true
+	}
+}
+
+// WithClientInterceptors adds interceptors around the calls of the client,
+// see ChainInterceptors.
+func WithClientInterceptors(interceptors ...Interceptor) ClientOption {
+	return func(o *ClientOptions) {
+		o.interceptors = append(o.interceptors, interceptors...)
+	}
+}
+
+// WithClientJSONOptions configures the JSON encoding of requests and the
+// decoding of responses of JSON clients. It doesn't change the encoding of
+// responses, which is chosen by the server.
+func WithClientJSONOptions(options JSONOptions) ClientOption {
+	return func(o *ClientOptions) {
+		o.jsonOptions = options
+	}
+}
+
+// WithClientCodec replaces the encoding of the client's constructor: requests
+// are encoded and responses decoded with codec, e.g. a codec given to the
+// server with WithServerCodec.
+func WithClientCodec(codec Codec) ClientOption {
+	return func(o *ClientOptions) {
+		o.codec = codec
+	}
+}
+
+// WithClientVersionCheck fails calls fast with errors.FailedPrecondition if
+// the protocol version advertised by the server doesn't support the client.
+// Servers which don't advertise their version are accepted.
+func WithClientVersionCheck() ClientOption {
+	return func(o *ClientOptions) {
+		o.versionCheck = true
+	}
+}
+
+// ServicePathPrefix returns the prefix of the routes of the service named
+// fullServiceName, generated is the <Service>PathPrefix constant used if no
+// path prefix option was given.
+func (o *ClientOptions) ServicePathPrefix(generated, fullServiceName string) string {
+	return servicePathPrefix(o.pathPrefix, o.hasPathPrefix, o.twirp, generated, fullServiceName)
+}
+
+// HTTPClient prepares client for a generated client: redirects of an
+// *http.Client are disabled, see WithoutRedirects.
+func (o *ClientOptions) HTTPClient(client HTTPClient) HTTPClient {
+	if httpClient, ok := client.(*http.Client); ok {
+		client = WithoutRedirects(httpClient)
+	}
+	if o.versionCheck {
+		client = &versionCheckClient{client: client}
+	}
+	if o.twirp {
+		client = &twirpClient{client: client}
+	}
+	return client
+}
+
+// Intercept wraps method with the interceptors of the client.
+func (o *ClientOptions) Intercept(method Method) Method {
+	if len(o.interceptors) == 0 {
+		return method
+	}
+	return ChainInterceptors(o.interceptors...)(method)
+}
+
+// DoJSONRequest is DoJSONRequest with the JSON options or the codec of the
+// client.
+func (o *ClientOptions) DoJSONRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message) error {
+	return doRequest(ctx, client, url, in, out, o.clientCodec(o.jsonOptions.Codec()))
+}
+
+// DoIdempotentJSONRequest is DoIdempotentJSONRequest with the JSON options
+// or the codec of the client.
+func (o *ClientOptions) DoIdempotentJSONRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message) error {
+	return doIdempotentRequest(ctx, client, url, in, out, o.clientCodec(o.jsonOptions.Codec()))
+}
+
+// DoProtobufferRequest is DoProtobufferRequest with the codec of the client.
+func (o *ClientOptions) DoProtobufferRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message) error {
+	return doRequest(ctx, client, url, in, out, o.clientCodec(ProtobufCodec{}))
+}
+
+// DoIdempotentProtobufferRequest is DoIdempotentProtobufferRequest with the
+// codec of the client.
+func (o *ClientOptions) DoIdempotentProtobufferRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message) error {
+	return doIdempotentRequest(ctx, client, url, in, out, o.clientCodec(ProtobufCodec{}))
+}
+
+// JSONCodec returns the JSON codec of the client: the codec of
+// WithClientCodec if it is a *JSONCodec, otherwise the codec of the JSON
+// options.
+func (o *ClientOptions) JSONCodec() *JSONCodec {
+	if codec, ok := o.codec.(*JSONCodec); ok {
+		return codec
+	}
+	return o.jsonOptions.Codec()
+}
+
+// clientCodec returns the codec of WithClientCodec, or codec of the
+// encoding of the client's constructor.
+func (o *ClientOptions) clientCodec(codec Codec) Codec {
+	if o.codec != nil {
+		return o.codec
+	}
+	return codec
+}
+
+func servicePathPrefix(prefix string, hasPrefix, twirp bool, generated, fullServiceName string) string {
+	switch {
+	case hasPrefix:
+		return ServicePathPrefix(prefix, fullServiceName)
+	case twirp:
+		return ServicePathPrefix(TwirpPathPrefix, fullServiceName)
+	default:
+		return generated
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestChainInterceptors(t *testing.T) {
+	var calls []string
+	interceptor := func(name string) Interceptor {
+		return func(next Method) Method {
+			return func(ctx context.Context, request interface{}) (interface{}, error) {
+				calls = append(calls, name)
+				return next(ctx, request)
+			}
+		}
+	}
+
+	options := NewServerOptions(WithServerInterceptors(interceptor("first"), interceptor("second")), WithServerInterceptors(interceptor("third")))
+	response, err := options.Intercept(func(ctx context.Context, request interface{}) (interface{}, error) {
+		calls = append(calls, "method")
+		return request, nil
+	})(context.Background(), "request")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if response != "request" {
+		t.Fatalf(`unexpected response (actual: "%v", expected: "request")`, response)
+	}
+
+	expected := []string{"first", "second", "third", "method"}
+	if !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("unexpected calls (actual: %v, expected: %v)", calls, expected)
+	}
+}
+
+func TestLimitRequestBody(t *testing.T) {
+	tests := []struct {
+		body  string
+		limit int64
+		err   bool
+	}{
+		{body: "hello", limit: 0},
+		{body: "hello", limit: 5},
+		{body: "hello", limit: 4, err: true},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(http.MethodPost, "/", strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		NewServerOptions(WithMaxRequestBodySize(test.limit)).LimitRequestBody(req)
+		body, err := ioutil.ReadAll(req.Body)
+		if !test.err {
+			if err != nil || string(body) != test.body {
+				t.Fatalf(`unexpected result for limit %d (body: "%s", err: %v)`, test.limit, body, err)
+			}
+			continue
+		}
+
+		terr, ok := err.(errors.Error)
+		if !ok || terr.Code() != errors.ResourceExhausted {
+			t.Fatalf("unexpected error for limit %d (actual: %v)", test.limit, err)
+		}
+		if int64(len(body)) != test.limit {
+			t.Fatalf("unexpected number of bytes read (actual: %d, expected: %d)", len(body), test.limit)
+		}
+	}
+}
+
+func TestServerCodec(t *testing.T) {
+	options := NewServerOptions()
+	if _, ok := options.Codec("application/xml"); ok {
+		t.Fatal("unexpected codec for application/xml")
+	}
+
+	codec := NewJSONCodec()
+	codec.Marshaler.EmitDefaults = true
+	options = NewServerOptions(WithServerCodec(codec))
+	actual, ok := options.Codec("application/json")
+	if !ok || actual != codec {
+		t.Fatalf("unexpected codec (actual: %v)", actual)
+	}
+	if _, ok := options.Codec("application/protobuf"); !ok {
+		t.Fatal("default protobuf codec is missing")
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"fmt"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"net/http"
+	"runtime/debug"
+)
+
+// PanicMetaKey is the meta key of errors.Internal errors caused by a
+// recovered panic, its value is always "true". The panic itself isn't
+// exposed to clients.
+const PanicMetaKey = "panic"
+
+// Panic is a panic recovered while serving a request.
+type Panic struct {
+	// Value is the value passed to panic.
+	Value interface{}
+	// Stack is the stack trace of the panicking goroutine.
+	Stack []byte
+}
+
+func (p *Panic) String() string {
+	return fmt.Sprintf("panic: %v\n\n%s", p.Value, p.Stack)
+}
+
+// PanicHandler is called with panics recovered while serving a request, see
+// WithServerPanicHandler.
+type PanicHandler func(ctx context.Context, p *Panic)
+
+// PanicError is the error responded for recovered panics.
+func PanicError() errors.Error {
+	err := errors.InternalError("Internal service panic")
+	return err.WithMeta(PanicMetaKey, "true")
+}
+
+// RecoverPanic handles the value r recovered from a panic: the panic handler
+// is called with the stack trace and PanicError is returned, or the panic
+// is propagated if WithServerRePanic was given. http.ErrAbortHandler is
+// re-panicked unchanged, net/http aborts the response without logging it.
+//
+// Generated servers call it with the recovered panics of service methods and
+// interceptors.
+func (o *ServerOptions) RecoverPanic(ctx context.Context, r interface{}) error {
+	if r == http.ErrAbortHandler {
+		panic(r)
+	}
+
+	p, ok := r.(*Panic)
+	if ok {
+		// Propagated by an inner RecoverPanic, it was handled already.
+		panic(p)
+	}
+
+	p = &Panic{Value: r, Stack: debug.Stack()}
+	if o.panicHandler != nil {
+		o.panicHandler(ctx, p)
+	}
+	if o.rePanic {
+		panic(p)
+	}
+	return PanicError()
+}
+
+// HandlePanic handles the value r recovered from a panic like RecoverPanic
+// and writes the error in the response.
+//
+// Generated servers call it with recovered panics of hooks and of the
+// generated code itself.
+func (o *ServerOptions) HandlePanic(ctx context.Context, resp http.ResponseWriter, r interface{}, hooks *hooks.ServerHooks) {
+	err := o.RecoverPanic(ctx, r)
+	o.WriteError(ctx, resp, err, hooks)
+}
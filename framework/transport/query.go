@@ -0,0 +1,317 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/gogo/protobuf/proto"
+	golangproto "github.com/golang/protobuf/proto"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// DecodeQueryRequest decodes a request message from the query parameters of
+// a GET request.
+//
+// Parameters are named after the proto fields (the JSON names are accepted
+// too), fields of nested messages are addressed by dotted names like
+// "filter.author", and repeated fields by repeating the parameter. Enums can
+// be given by name or number and bytes as base64. Unknown parameters are
+// ignored, oneof and map fields can't be set.
+func DecodeQueryRequest(ctx context.Context, req *http.Request, message proto.Message) error {
+	return DecodeQuery(req.URL.Query(), message)
+}
+
+// DecodeQuery decodes query parameters into message, see DecodeQueryRequest
+// for the format.
+func DecodeQuery(values url.Values, message proto.Message) error {
+	root := reflect.ValueOf(message)
+	if root.Kind() != reflect.Ptr || root.Elem().Kind() != reflect.Struct {
+		return errors.InternalError(fmt.Sprintf("can't decode query into %T", message))
+	}
+
+	for key, vs := range values {
+		v := root.Elem()
+		path := strings.Split(key, ".")
+		var field queryField
+		found := true
+		for i, name := range path {
+			field, found = lookupQueryField(v.Type(), name)
+			if !found {
+				break
+			}
+			if i == len(path)-1 {
+				break
+			}
+
+			// Intermediate names have to address nested messages.
+			fv := v.Field(field.index)
+			if fv.Kind() != reflect.Ptr || fv.Type().Elem().Kind() != reflect.Struct {
+				found = false
+				break
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			v = fv.Elem()
+		}
+		if !found {
+			continue
+		}
+
+		if err := setQueryField(v.Field(field.index), field, vs); err != nil {
+			return errors.InvalidArgumentError(key, err.Error())
+		}
+	}
+	return nil
+}
+
+// EncodeQuery encodes message as query parameters, the inverse of
+// DecodeQuery. Fields with zero values are omitted.
+func EncodeQuery(message proto.Message) (url.Values, error) {
+	values := make(url.Values)
+	v := reflect.ValueOf(message)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return values, nil
+	}
+	if err := encodeQueryStruct(values, "", v.Elem()); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+func encodeQueryStruct(values url.Values, prefix string, v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, ok := parseQueryField(t.Field(i), i)
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+		key := prefix + field.name
+
+		switch {
+		case fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct:
+			if fv.IsNil() {
+				continue
+			}
+			if err := encodeQueryStruct(values, key+".", fv.Elem()); err != nil {
+				return err
+			}
+		case field.repeated:
+			for j := 0; j < fv.Len(); j++ {
+				s, err := formatQueryValue(fv.Index(j))
+				if err != nil {
+					return fmt.Errorf("field %s: %v", key, err)
+				}
+				values.Add(key, s)
+			}
+		default:
+			if isZeroQueryValue(fv) {
+				continue
+			}
+			s, err := formatQueryValue(fv)
+			if err != nil {
+				return fmt.Errorf("field %s: %v", key, err)
+			}
+			values.Set(key, s)
+		}
+	}
+	return nil
+}
+
+// queryField is a field of a generated message, which can be set by query
+// parameters.
+type queryField struct {
+	index    int
+	name     string
+	jsonName string
+	enum     string
+	repeated bool
+}
+
+func parseQueryField(f reflect.StructField, index int) (queryField, bool) {
+	tag := f.Tag.Get("protobuf")
+	// Oneofs and maps have different tags and are not supported.
+	if tag == "" || f.Tag.Get("protobuf_key") != "" {
+		return queryField{}, false
+	}
+
+	field := queryField{index: index}
+	for i, part := range strings.Split(tag, ",") {
+		switch {
+		case i == 2:
+			field.repeated = part == "rep"
+		case strings.HasPrefix(part, "name="):
+			field.name = part[len("name="):]
+		case strings.HasPrefix(part, "json="):
+			field.jsonName = part[len("json="):]
+		case strings.HasPrefix(part, "enum="):
+			field.enum = part[len("enum="):]
+		}
+	}
+	// repeated bytes are [][]byte, a single bytes field is []byte.
+	if field.repeated && f.Type.Kind() != reflect.Slice {
+		field.repeated = false
+	}
+	return field, field.name != ""
+}
+
+func lookupQueryField(t reflect.Type, name string) (queryField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field, ok := parseQueryField(t.Field(i), i)
+		if ok && (field.name == name || field.jsonName == name) {
+			return field, true
+		}
+	}
+	return queryField{}, false
+}
+
+func setQueryField(v reflect.Value, field queryField, values []string) error {
+	if field.repeated {
+		slice := reflect.MakeSlice(v.Type(), 0, len(values))
+		for _, s := range values {
+			elem := reflect.New(v.Type().Elem()).Elem()
+			if err := parseQueryValue(elem, field.enum, s); err != nil {
+				return err
+			}
+			slice = reflect.Append(slice, elem)
+		}
+		v.Set(reflect.AppendSlice(v, slice))
+		return nil
+	}
+
+	if v.Kind() == reflect.Ptr && v.Type().Elem().Kind() == reflect.Struct {
+		return fmt.Errorf("message can't be set directly, set its fields")
+	}
+	// The last value wins, like for repeated occurrences of a field in
+	// binary messages.
+	return parseQueryValue(v, field.enum, values[len(values)-1])
+}
+
+func parseQueryValue(v reflect.Value, enum string, s string) error {
+	// Fields of proto2 messages are pointers.
+	if v.Kind() == reflect.Ptr {
+		elem := reflect.New(v.Type().Elem())
+		if err := parseQueryValue(elem.Elem(), enum, s); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("invalid bool %q", s)
+		}
+		v.SetBool(b)
+	case reflect.Int32, reflect.Int64:
+		if enum != "" {
+			if n, ok := enumValue(enum, s); ok {
+				v.SetInt(int64(n))
+				return nil
+			}
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", s)
+		}
+		v.SetInt(n)
+	case reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid unsigned integer %q", s)
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("invalid number %q", s)
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			b, err = base64.URLEncoding.DecodeString(s)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid base64 %q", s)
+		}
+		v.SetBytes(b)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
+
+// enumValue looks up the number of an enum value by name. Generated messages
+// register their enums either with gogo/protobuf or golang/protobuf.
+func enumValue(enum, name string) (int32, bool) {
+	if m := proto.EnumValueMap(enum); m != nil {
+		n, ok := m[name]
+		return n, ok
+	}
+	n, ok := golangproto.EnumValueMap(enum)[name]
+	return n, ok
+}
+
+func formatQueryValue(v reflect.Value) (string, error) {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return base64.StdEncoding.EncodeToString(v.Bytes()), nil
+		}
+	}
+	return "", fmt.Errorf("unsupported type %s", v.Type())
+}
+
+func isZeroQueryValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Slice:
+		// Set proto2 fields are sent, even with zero values.
+		return v.IsNil() || (v.Kind() == reflect.Slice && v.Len() == 0)
+	default:
+		return v.Interface() == reflect.Zero(v.Type()).Interface()
+	}
+}
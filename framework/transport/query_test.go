@@ -0,0 +1,86 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"github.com/golang/protobuf/proto"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+type queryFilter struct {
+	Author string   `protobuf:"bytes,1,opt,name=author,json=author" json:"author,omitempty"`
+	Tags   []string `protobuf:"bytes,2,rep,name=tags" json:"tags,omitempty"`
+}
+
+func (m *queryFilter) Reset()         { *m = queryFilter{} }
+func (m *queryFilter) String() string { return proto.CompactTextString(m) }
+func (*queryFilter) ProtoMessage()    {}
+
+type queryRequest struct {
+	Query    string       `protobuf:"bytes,1,opt,name=query" json:"query,omitempty"`
+	PageSize int32        `protobuf:"varint,2,opt,name=page_size,json=pageSize" json:"page_size,omitempty"`
+	Exact    bool         `protobuf:"varint,3,opt,name=exact" json:"exact,omitempty"`
+	Ids      []uint64     `protobuf:"varint,4,rep,packed,name=ids" json:"ids,omitempty"`
+	Token    []byte       `protobuf:"bytes,5,opt,name=token,proto3" json:"token,omitempty"`
+	Filter   *queryFilter `protobuf:"bytes,6,opt,name=filter" json:"filter,omitempty"`
+}
+
+func (m *queryRequest) Reset()         { *m = queryRequest{} }
+func (m *queryRequest) String() string { return proto.CompactTextString(m) }
+func (*queryRequest) ProtoMessage()    {}
+
+func TestDecodeQuery(t *testing.T) {
+	values, err := url.ParseQuery("query=proto&pageSize=10&exact=true&ids=1&ids=2&token=AQI%3D&filter.author=bob&filter.tags=a&filter.tags=b&unknown=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actual := new(queryRequest)
+	if err := DecodeQuery(values, actual); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := &queryRequest{
+		Query:    "proto",
+		PageSize: 10,
+		Exact:    true,
+		Ids:      []uint64{1, 2},
+		Token:    []byte{1, 2},
+		Filter:   &queryFilter{Author: "bob", Tags: []string{"a", "b"}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Fatalf("unexpected message (actual: %v, expected: %v)", actual, expected)
+	}
+
+	encoded, err := EncodeQuery(expected)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip := new(queryRequest)
+	if err := DecodeQuery(encoded, roundTrip); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roundTrip, expected) {
+		t.Fatalf("unexpected message after round trip (actual: %v, expected: %v)", roundTrip, expected)
+	}
+}
+
+func TestDecodeQueryInvalidValue(t *testing.T) {
+	err := DecodeQuery(url.Values{"page_size": {"ten"}}, new(queryRequest))
+	if err == nil {
+		t.Fatal("invalid integer was decoded")
+	}
+}
@@ -0,0 +1,101 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"github.com/donutloop/xservice/framework/errors"
+	"net/http"
+	"strconv"
+)
+
+// ResponseWriter wraps the http.ResponseWriter of a generated server. It
+// tracks the status code and the number of bytes written, which hooks can
+// read with xcontext.StatusCode and xcontext.ResponseBytesWritten, and lets
+// the server detect that a response was already written.
+type ResponseWriter struct {
+	http.ResponseWriter
+	status       int
+	bytesWritten int64
+	// responseSent is set once the ResponseSent hook is called.
+	responseSent bool
+}
+
+// NewResponseWriter wraps w, unless it is a *ResponseWriter already.
+func NewResponseWriter(w http.ResponseWriter) *ResponseWriter {
+	if rw, ok := w.(*ResponseWriter); ok {
+		return rw
+	}
+	return &ResponseWriter{ResponseWriter: w}
+}
+
+// WriteHeader sends the header with the status code. Like
+// http.ResponseWriter, only the first call has an effect.
+func (w *ResponseWriter) WriteHeader(status int) {
+	if w.status != 0 {
+		return
+	}
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write writes b to the response body, the header is sent with
+// http.StatusOK before, if it wasn't sent yet.
+func (w *ResponseWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.bytesWritten += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher, if the wrapped ResponseWriter does.
+func (w *ResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.WriteHeader(http.StatusOK)
+		}
+		f.Flush()
+	}
+}
+
+// Status returns the status code sent, or 0 if the header wasn't sent yet.
+func (w *ResponseWriter) Status() int { return w.status }
+
+// BytesWritten returns the number of bytes of the response body written.
+func (w *ResponseWriter) BytesWritten() int64 { return w.bytesWritten }
+
+// Written reports whether the header was already sent, afterwards neither
+// the status code nor headers can be changed.
+func (w *ResponseWriter) Written() bool { return w.status != 0 }
+
+// responseWritten reports whether the header of resp was sent, which is only
+// known if resp is a *ResponseWriter.
+func responseWritten(resp http.ResponseWriter) bool {
+	rw, ok := resp.(*ResponseWriter)
+	return ok && rw.Written()
+}
+
+// writeResponse sends status and body.
+func writeResponse(resp http.ResponseWriter, status int, body []byte) error {
+	resp.WriteHeader(status)
+	if len(body) == 0 {
+		return nil
+	}
+	if _, err := resp.Write(body); err != nil {
+		err = errors.WrapErr(err, "error while writing response to client, but already sent response status code to "+strconv.Itoa(status))
+		return errors.InternalErrorWith(err)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestResponseWriter(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := NewResponseWriter(recorder)
+	if NewResponseWriter(resp) != resp {
+		t.Fatal("a *ResponseWriter is wrapped twice")
+	}
+
+	if resp.Written() {
+		t.Fatal("unexpected written response")
+	}
+	resp.Write([]byte("hello"))
+	resp.WriteHeader(http.StatusInternalServerError)
+
+	if resp.Status() != http.StatusOK || recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status code (actual: %d, recorded: %d, expected: %d)", resp.Status(), recorder.Code, http.StatusOK)
+	}
+	if resp.BytesWritten() != 5 {
+		t.Fatalf("unexpected number of bytes written (actual: %d, expected: 5)", resp.BytesWritten())
+	}
+}
+
+func TestWriteErrorAfterResponse(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	resp := NewResponseWriter(recorder)
+	if err := EncodeJSONResponse(context.Background(), resp, &queryFilter{Author: "a"}); err != nil {
+		t.Fatal(err)
+	}
+
+	WriteError(resp, errors.InternalError("too late"))
+
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status code (actual: %d, expected: %d)", recorder.Code, http.StatusOK)
+	}
+	if body := recorder.Body.String(); body != `{"author":"a"}` {
+		t.Fatalf("unexpected body (actual: %s)", body)
+	}
+}
+
+func TestWriteErrorAfterResponseHooks(t *testing.T) {
+	var calls []string
+	var status string
+	h := &hooks.ServerHooks{
+		Error: func(ctx context.Context, err errors.Error) context.Context {
+			calls = append(calls, "Error")
+			return ctx
+		},
+		ResponseSent: func(ctx context.Context) {
+			calls = append(calls, "ResponseSent")
+			status, _ = xcontext.StatusCode(ctx)
+		},
+	}
+
+	resp := NewResponseWriter(httptest.NewRecorder())
+	ctx := xcontext.WithResponseWriter(context.Background(), resp)
+	resp.WriteHeader(http.StatusAccepted)
+	WriteErrorAndTriggerHooks(ctx, resp, errors.InternalError("encoder failed"), h)
+
+	if expected := []string{"Error", "ResponseSent"}; !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("unexpected hook calls (actual: %v, expected: %v)", calls, expected)
+	}
+	if status != "202" {
+		t.Fatalf(`unexpected status code (actual: "%s", expected: "202")`, status)
+	}
+
+	// a ResponseSent hook which panicked isn't called again
+	calls = nil
+	resp = NewResponseWriter(httptest.NewRecorder())
+	ctx = xcontext.WithResponseWriter(context.Background(), resp)
+	resp.WriteHeader(http.StatusOK)
+	CallResponseSent(ctx, h)
+	WriteErrorAndTriggerHooks(ctx, resp, errors.InternalError("hook panic"), h)
+
+	if expected := []string{"ResponseSent", "Error"}; !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("unexpected hook calls (actual: %v, expected: %v)", calls, expected)
+	}
+}
@@ -26,7 +26,6 @@ import (
 	"github.com/donutloop/xservice/framework/hooks"
 	"github.com/donutloop/xservice/framework/xcontext"
 	"github.com/donutloop/xservice/framework/xhttp"
-	"github.com/gogo/protobuf/jsonpb"
 	"github.com/gogo/protobuf/proto"
 	"io"
 	"io/ioutil"
@@ -48,10 +47,10 @@ type HTTPClient interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// getCustomHTTPReqHeaders retrieves a copy of any headers that are set in
+// CustomHTTPRequestHeaders retrieves a copy of any headers that are set in
 // a context through the .WithHTTPRequestHeaders function.
 // If there are no headers set, or if they have the wrong type, nil is returned.
-func getCustomHTTPReqHeaders(ctx context.Context) http.Header {
+func CustomHTTPRequestHeaders(ctx context.Context) http.Header {
 	header, ok := xcontext.HTTPRequestHeaders(ctx)
 	if !ok || header == nil {
 		return nil
@@ -76,13 +75,33 @@ func WriteError(resp http.ResponseWriter, err error) {
 
 // writeError writes  errors in the response and triggers hooks.
 func WriteErrorAndTriggerHooks(ctx context.Context, resp http.ResponseWriter, err error, hooks *hooks.ServerHooks) {
-	// Non- errors are wrapped as Internal (default)
+	writeError(ctx, resp, toError(err), hooks, errors.ServerHTTPStatusFromErrorCode)
+}
+
+// toError wraps non- errors as Internal (default)
+func toError(err error) errors.Error {
 	terr, ok := err.(errors.Error)
 	if !ok {
 		terr = errors.InternalErrorWith(err)
 	}
+	return terr
+}
+
+func writeError(ctx context.Context, resp http.ResponseWriter, terr errors.Error, hooks *hooks.ServerHooks, statusFromErrorCode func(errors.ErrorCode) int) {
+	if responseWritten(resp) {
+		// The response can't be changed anymore, e.g. if an encoder failed
+		// while writing the body or a hook panicked afterwards. The hooks
+		// learn the status which was sent.
+		ctx = xcontext.WithStatusCode(ctx, resp.(*ResponseWriter).Status())
+		ctx = CallError(ctx, hooks, terr)
+		log.Printf("unable to send error message %q: response already written", terr)
+		if !resp.(*ResponseWriter).responseSent {
+			CallResponseSent(ctx, hooks)
+		}
+		return
+	}
 
-	statusCode := errors.ServerHTTPStatusFromErrorCode(terr.Code())
+	statusCode := statusFromErrorCode(terr.Code())
 	ctx = xcontext.WithStatusCode(ctx, statusCode)
 	ctx = CallError(ctx, hooks, terr)
 
@@ -129,11 +148,14 @@ func newRequest(ctx context.Context, url string, reqBody io.Reader, contentType
 		return nil, err
 	}
 	req = req.WithContext(ctx)
-	if customHeader := getCustomHTTPReqHeaders(ctx); customHeader != nil {
+	if customHeader := CustomHTTPRequestHeaders(ctx); customHeader != nil {
 		req.Header = customHeader
 	}
 	req.Header.Set("Content-Type", contentType)
-	req.Header.Set("XService-Version", "v0.1.0")
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", contentType)
+	}
+	req.Header.Set(xhttp.VersionHeader, ProtocolVersion)
 	return req, nil
 }
 
@@ -167,24 +189,35 @@ func marshalErrorToJSON(terr errors.Error) []byte {
 	return buf
 }
 
-// doProtobufRequest is common code to make a request to the remote  service.
-func DoProtobufferRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message) (err error) {
-	reqBodyBytes, err := proto.Marshal(in)
+// DoProtobufferRequest is common code to make a request to the remote  service.
+func DoProtobufferRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message) error {
+	return doRequest(ctx, client, url, in, out, ProtobufCodec{})
+}
+
+// DoJSONRequest is common code to make a request to the remote  service.
+func DoJSONRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message) error {
+	return doRequest(ctx, client, url, in, out, NewJSONCodec())
+}
+
+// doRequest posts in encoded with codec to url and decodes the response
+// into out.
+func doRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message, codec Codec) (err error) {
+	reqBodyBytes, err := codec.Marshal(in)
 	if err != nil {
-		return errors.ClientError("failed to marshal proto request", err)
+		return errors.ClientError("failed to marshal "+codecName(codec)+" request", err)
 	}
 	reqBody := bytes.NewBuffer(reqBodyBytes)
 	if err = ctx.Err(); err != nil {
 		return errors.ClientError("aborted because context was done", err)
 	}
 
-	req, err := newRequest(ctx, url, reqBody, "application/protobuf")
+	req, err := newRequest(ctx, url, reqBody, codec.ContentType())
 	if err != nil {
 		return errors.ClientError("could not build request", err)
 	}
 	resp, err := client.Do(req)
 	if err != nil {
-		return errors.ClientError("failed to do request", err)
+		return doError(err)
 	}
 
 	defer func() {
@@ -199,7 +232,7 @@ func DoProtobufferRequest(ctx context.Context, client HTTPClient, url string, in
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return errorFromResponse(resp)
+		return ErrorFromResponse(resp)
 	}
 
 	respBodyBytes, err := ioutil.ReadAll(resp.Body)
@@ -210,56 +243,23 @@ func DoProtobufferRequest(ctx context.Context, client HTTPClient, url string, in
 		return errors.ClientError("aborted because context was done", err)
 	}
 
-	if err = proto.Unmarshal(respBodyBytes, out); err != nil {
-		return errors.ClientError("failed to unmarshal proto response", err)
+	if err = codec.Unmarshal(respBodyBytes, out); err != nil {
+		return errors.ClientError("failed to unmarshal "+codecName(codec)+" response", err)
 	}
 	return nil
 }
 
-// doJSONRequest is common code to make a request to the remote  service.
-func DoJSONRequest(ctx context.Context, client HTTPClient, url string, in, out proto.Message) (err error) {
-
-	reqBody := new(bytes.Buffer)
-	marshaler := &jsonpb.Marshaler{OrigName: true}
-	if err = marshaler.Marshal(reqBody, in); err != nil {
-		return errors.ClientError("failed to marshal json request", err)
-	}
-	if err = ctx.Err(); err != nil {
-		return errors.ClientError("aborted because context was done", err)
-	}
-
-	req, err := newRequest(ctx, url, reqBody, "application/json")
-	if err != nil {
-		return errors.ClientError("could not build request", err)
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return errors.ClientError("failed to do request", err)
-	}
-
-	defer func() {
-		cerr := resp.Body.Close()
-		if err == nil && cerr != nil {
-			err = errors.ClientError("failed to close response body", cerr)
-		}
-	}()
-
-	if err = ctx.Err(); err != nil {
-		return errors.ClientError("aborted because context was done", err)
+// codecName returns the short name of the content type of codec used in
+// error messages, e.g. "json" for "application/json".
+func codecName(codec Codec) string {
+	switch contentType := codec.ContentType(); contentType {
+	case xhttp.ApplicationJson:
+		return "json"
+	case xhttp.ApplicationProtobuf:
+		return "proto"
+	default:
+		return contentType
 	}
-
-	if resp.StatusCode != http.StatusOK {
-		return errorFromResponse(resp)
-	}
-
-	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
-	if err = unmarshaler.Unmarshal(resp.Body, out); err != nil {
-		return errors.ClientError("failed to unmarshal json response", err)
-	}
-	if err = ctx.Err(); err != nil {
-		return errors.ClientError("aborted because context was done", err)
-	}
-	return nil
 }
 
 // The standard library will, by default, redirect requests (including POSTs) if it gets a 302 or
@@ -285,11 +285,11 @@ func WithoutRedirects(in *http.Client) *http.Client {
 	return &copy
 }
 
-// errorFromResponse builds a .Error from a non-200 HTTP response.
+// ErrorFromResponse builds a .Error from a non-200 HTTP response.
 // If the response has a valid serialized  error, then it's returned.
 // If not, the response status code is used to generate a similar
 // error. See ErrorFromIntermediary for more info on intermediary errors.
-func errorFromResponse(resp *http.Response) errors.Error {
+func ErrorFromResponse(resp *http.Response) errors.Error {
 	statusCode := resp.StatusCode
 	statusText := http.StatusText(statusCode)
 
@@ -386,8 +386,15 @@ func CallResponsePrepared(ctx context.Context, h *hooks.ServerHooks) context.Con
 	return h.ResponsePrepared(ctx)
 }
 
-// Call .ServerHooks.ResponseSent if the hook is available
+// Call .ServerHooks.ResponseSent if the hook is available. It is called at
+// most once per response of a *ResponseWriter in ctx.
 func CallResponseSent(ctx context.Context, h *hooks.ServerHooks) {
+	if rw, ok := ctx.Value(xcontext.ResponseWriterKey).(*ResponseWriter); ok {
+		if rw.responseSent {
+			return
+		}
+		rw.responseSent = true
+	}
 	if h == nil || h.ResponseSent == nil {
 		return
 	}
@@ -406,21 +413,7 @@ func CallError(ctx context.Context, h *hooks.ServerHooks, err errors.Error) cont
 type LogErrorFunc func(format string, args ...interface{})
 
 func EncodeJSONResponse(ctx context.Context, resp http.ResponseWriter, content proto.Message) error {
-	buff := new(bytes.Buffer)
-	marshaler := &jsonpb.Marshaler{OrigName: true}
-	if err := marshaler.Marshal(buff, content); err != nil {
-		err = errors.WrapErr(err, "failed to marshal json response")
-		return errors.InternalErrorWith(err)
-	}
-	respBytes := buff.Bytes()
-	resp.Header().Set(xhttp.ContentTypeHeader, xhttp.ApplicationJson)
-	if _, err := resp.Write(respBytes); err != nil {
-		err = errors.WrapErr(err, "error while writing response to client, but already sent response status code to 200")
-		return errors.InternalErrorWith(err)
-	}
-	resp.WriteHeader(http.StatusOK)
-	ctx = xcontext.WithStatusCode(ctx, http.StatusOK)
-	return nil
+	return EncodeResponseWith(NewJSONCodec())(ctx, resp, content)
 }
 
 // DecodeRequestFunc extracts a user-domain request object from an HTTP request object.
@@ -430,41 +423,13 @@ type DecodeRequestFunc func(ctx context.Context, resp *http.Request, content pro
 type EncodeResponseFunc func(ctx context.Context, resp http.ResponseWriter, content proto.Message) error
 
 func DecodeJSONRequest(ctx context.Context, req *http.Request, message proto.Message) error {
-	unmarshaler := jsonpb.Unmarshaler{AllowUnknownFields: true}
-	if err := unmarshaler.Unmarshal(req.Body, message); err != nil {
-		err = errors.WrapErr(err, "failed to parse request json")
-		terr := errors.InternalErrorWith(err)
-		return terr
-	}
-	return nil
+	return DecodeRequestWith(NewJSONCodec())(ctx, req, message)
 }
 
 func EncodePROTOResponse(ctx context.Context, resp http.ResponseWriter, content proto.Message) error {
-	respBytes, err := proto.Marshal(content)
-	if err != nil {
-		err = errors.WrapErr(err, "failed to marshal proto response")
-		return errors.InternalErrorWith(err)
-	}
-	resp.Header().Set(xhttp.ContentTypeHeader, xhttp.ApplicationProtobuf)
-	if _, err := resp.Write(respBytes); err != nil {
-		err = errors.WrapErr(err, "error while writing response to client, but already sent response status code to 200")
-		return errors.InternalErrorWith(err)
-	}
-	resp.WriteHeader(http.StatusOK)
-	ctx = xcontext.WithStatusCode(ctx, http.StatusOK)
-	return nil
+	return EncodeResponseWith(ProtobufCodec{})(ctx, resp, content)
 }
 
 func DecodePROTORequest(ctx context.Context, req *http.Request, content proto.Message) error {
-	buff, err := ioutil.ReadAll(req.Body)
-	if err != nil {
-		err = errors.WrapErr(err, "failed to read request proto")
-		return errors.InternalErrorWith(err)
-	}
-	err = proto.Unmarshal(buff, content)
-	if err != nil {
-		err = errors.WrapErr(err, "failed to parse request proto")
-		return errors.InternalErrorWith(err)
-	}
-	return nil
+	return DecodeRequestWith(ProtobufCodec{})(ctx, req, content)
 }
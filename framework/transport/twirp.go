@@ -0,0 +1,93 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"net/http"
+)
+
+const (
+	// TwirpPathPrefix is the route prefix used by Twirp v7 services.
+	TwirpPathPrefix = "/twirp"
+
+	// TwirpVersionHeader is sent by Twirp clients instead of xhttp.VersionHeader.
+	TwirpVersionHeader = "Twirp-Version"
+
+	// TwirpVersion is the Twirp protocol version spoken in compatibility mode.
+	TwirpVersion = "v7.1.0"
+)
+
+// TwirpHTTPStatusFromErrorCode maps an error type to the HTTP response status
+// used by Twirp v7. It differs from errors.ServerHTTPStatusFromErrorCode only
+// for resource_exhausted, which Twirp maps to 429 Too Many Requests.
+func TwirpHTTPStatusFromErrorCode(code errors.ErrorCode) int {
+	if code == errors.ResourceExhausted {
+		return http.StatusTooManyRequests
+	}
+	return errors.ServerHTTPStatusFromErrorCode(code)
+}
+
+// WriteTwirpErrorAndTriggerHooks writes err like a Twirp v7 server: with the
+// Twirp status mapping and route errors described by the
+// "twirp_invalid_route" meta key. Twirp has no negotiation errors, it
+// rejects unsupported content types as bad routes, and reports request
+// bodies which can't be decoded as malformed.
+func WriteTwirpErrorAndTriggerHooks(ctx context.Context, resp http.ResponseWriter, err error, hooks *hooks.ServerHooks) {
+	terr := toError(err)
+	if _, ok := terr.(decodeError); ok {
+		malformedErr := errors.NewError(errors.Malformed, terr.Msg())
+		for k, v := range terr.MetaMap() {
+			malformedErr = malformedErr.WithMeta(k, v)
+		}
+		terr = malformedErr
+	}
+	if terr.Code() == errors.UnsupportedMediaType || terr.Code() == errors.NotAcceptable {
+		routeErr := errors.NewError(errors.BadRoute, terr.Msg())
+		for k, v := range terr.MetaMap() {
+			routeErr = routeErr.WithMeta(k, v)
+		}
+		if req, ok := xcontext.HTTPRequest(ctx); ok {
+			routeErr = routeErr.WithMeta("xservice_invalid_route", req.Method+" "+req.URL.Path)
+		}
+		terr = routeErr
+	}
+	if route := terr.Meta("xservice_invalid_route"); route != "" {
+		meta := terr.MetaMap()
+		twirpErr := errors.NewError(terr.Code(), terr.Msg())
+		for k, v := range meta {
+			if k != "xservice_invalid_route" {
+				twirpErr = twirpErr.WithMeta(k, v)
+			}
+		}
+		terr = twirpErr.WithMeta("twirp_invalid_route", route)
+	}
+	writeError(ctx, resp, terr, hooks, TwirpHTTPStatusFromErrorCode)
+}
+
+// twirpClient sends the Twirp-Version header instead of XService-Version, so
+// Twirp servers and intermediaries see a regular Twirp client.
+type twirpClient struct {
+	client HTTPClient
+}
+
+func (c *twirpClient) Do(req *http.Request) (*http.Response, error) {
+	req.Header.Del(xhttp.VersionHeader)
+	req.Header.Set(TwirpVersionHeader, TwirpVersion)
+	return c.client.Do(req)
+}
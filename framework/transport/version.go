@@ -0,0 +1,131 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ProtocolVersion is the version of the protocol spoken by generated servers
+// and clients, they send it in the XService-Version header.
+const ProtocolVersion = "v0.1.0"
+
+// Meta keys of version errors.
+const (
+	ClientVersionMetaKey = "client_version"
+	ServerVersionMetaKey = "server_version"
+)
+
+// versionCompatibility is the compatibility matrix of protocol versions: a
+// server of the major and minor version of the key accepts clients of the
+// listed major and minor versions. Patch versions are always compatible,
+// versions missing in the matrix are only compatible with themselves.
+var versionCompatibility = map[string][]string{
+	"v0.1": {"v0.1"},
+}
+
+// minorVersion returns the major and minor version of a version like
+// "v1.2.3", e.g. "v1.2".
+func minorVersion(version string) (string, bool) {
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) != 3 || !strings.HasPrefix(version, "v") {
+		return "", false
+	}
+	for _, part := range parts {
+		if _, err := strconv.ParseUint(part, 10, 32); err != nil {
+			return "", false
+		}
+	}
+	return "v" + parts[0] + "." + parts[1], true
+}
+
+// VersionsCompatible reports whether a server speaking serverVersion accepts
+// clients speaking clientVersion, according to the compatibility matrix.
+// Versions which can't be parsed are incompatible.
+func VersionsCompatible(clientVersion, serverVersion string) bool {
+	client, ok := minorVersion(clientVersion)
+	if !ok {
+		return false
+	}
+	server, ok := minorVersion(serverVersion)
+	if !ok {
+		return false
+	}
+	if client == server {
+		return true
+	}
+	for _, compatible := range versionCompatibility[server] {
+		if compatible == client {
+			return true
+		}
+	}
+	return false
+}
+
+// versionError is the error of incompatible protocol versions.
+func versionError(msg, clientVersion, serverVersion string) errors.Error {
+	err := errors.NewError(errors.FailedPrecondition, msg)
+	return err.WithMeta(ClientVersionMetaKey, clientVersion).WithMeta(ServerVersionMetaKey, serverVersion)
+}
+
+// CheckVersion advertises the protocol version of the server in the
+// XService-Version header of resp and checks the version of the client
+// sent in the same header of req. Requests without version, e.g. of curl,
+// are accepted, requests of incompatible clients fail with
+// errors.FailedPrecondition. Twirp compatible servers don't check versions.
+func (o *ServerOptions) CheckVersion(resp http.ResponseWriter, req *http.Request) error {
+	if o.twirp {
+		return nil
+	}
+	resp.Header().Set(xhttp.VersionHeader, ProtocolVersion)
+
+	clientVersion := req.Header.Get(xhttp.VersionHeader)
+	if clientVersion == "" || o.ignoreClientVersion || VersionsCompatible(clientVersion, ProtocolVersion) {
+		return nil
+	}
+	return versionError("client protocol version "+clientVersion+" is not supported by the server", clientVersion, ProtocolVersion)
+}
+
+// versionCheckClient fails responses of servers whose protocol version
+// doesn't accept the version of the client.
+type versionCheckClient struct {
+	client HTTPClient
+}
+
+func (c *versionCheckClient) Do(req *http.Request) (*http.Response, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	serverVersion := resp.Header.Get(xhttp.VersionHeader)
+	if serverVersion == "" || VersionsCompatible(ProtocolVersion, serverVersion) {
+		return resp, nil
+	}
+	resp.Body.Close()
+	return nil, versionError("server protocol version "+serverVersion+" does not support the client", ProtocolVersion, serverVersion)
+}
+
+// doError is the error of a failed HTTPClient.Do, errors.Error, e.g. of
+// incompatible versions, are passed through.
+func doError(err error) errors.Error {
+	if terr, ok := err.(errors.Error); ok {
+		return terr
+	}
+	return errors.ClientError("failed to do request", err)
+}
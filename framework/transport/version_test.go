@@ -0,0 +1,102 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package transport
+
+import (
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/xhttp"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVersionsCompatible(t *testing.T) {
+	tests := []struct {
+		client     string
+		server     string
+		compatible bool
+	}{
+		{client: "v0.1.0", server: "v0.1.0", compatible: true},
+		{client: "v0.1.0", server: "v0.1.7", compatible: true},
+		{client: "v0.2.0", server: "v0.1.0", compatible: false},
+		{client: "v1.0.0", server: "v0.1.0", compatible: false},
+		{client: "0.1.0", server: "v0.1.0", compatible: false},
+		{client: "v0.1", server: "v0.1.0", compatible: false},
+	}
+
+	for _, test := range tests {
+		if actual := VersionsCompatible(test.client, test.server); actual != test.compatible {
+			t.Fatalf("unexpected compatibility of client %s and server %s (actual: %t, expected: %t)", test.client, test.server, actual, test.compatible)
+		}
+	}
+}
+
+func TestCheckVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		options []ServerOption
+		err     bool
+	}{
+		{version: ""},
+		{version: ProtocolVersion},
+		{version: "v1.0.0", err: true},
+		{version: "v1.0.0", options: []ServerOption{WithServerIgnoreClientVersion()}},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set(xhttp.VersionHeader, test.version)
+		resp := httptest.NewRecorder()
+
+		err := NewServerOptions(test.options...).CheckVersion(resp, req)
+		if test.err {
+			if terr, ok := err.(errors.Error); !ok || terr.Code() != errors.FailedPrecondition || terr.Meta(ClientVersionMetaKey) != test.version {
+				t.Fatalf("%q: unexpected error (actual: %v, expected: failed_precondition)", test.version, err)
+			}
+		} else if err != nil {
+			t.Fatalf("%q: %v", test.version, err)
+		}
+		if actual := resp.Header().Get(xhttp.VersionHeader); actual != ProtocolVersion {
+			t.Fatalf(`%q: unexpected server version (actual: "%s", expected: "%s")`, test.version, actual, ProtocolVersion)
+		}
+	}
+}
+
+func TestClientVersionCheck(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		resp.Header().Set(xhttp.VersionHeader, "v1.0.0")
+		resp.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	client := NewClientOptions(WithClientVersionCheck()).HTTPClient(&http.Client{})
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.Do(req)
+	if terr, ok := err.(errors.Error); !ok || terr.Code() != errors.FailedPrecondition || terr.Meta(ServerVersionMetaKey) != "v1.0.0" {
+		t.Fatalf("unexpected error (actual: %v, expected: failed_precondition)", err)
+	}
+
+	resp, err := NewClientOptions().HTTPClient(&http.Client{}).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if _, err := ioutil.ReadAll(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -19,6 +19,7 @@ package xcontext
 
 import (
 	"context"
+	"github.com/donutloop/xservice/framework/xhttp"
 	"github.com/pkg/errors"
 	"net/http"
 	"strconv"
@@ -33,6 +34,9 @@ const (
 	StatusCodeKey
 	RequestHeaderKey
 	ResponseWriterKey
+	RequestKey
+	HTTPGetKey
+	ResponseContentTypeKey
 )
 
 func WithMethodName(ctx context.Context, name string) context.Context {
@@ -55,6 +59,39 @@ func WithResponseWriter(ctx context.Context, w http.ResponseWriter) context.Cont
 	return context.WithValue(ctx, ResponseWriterKey, w)
 }
 
+// WithHTTPRequest stores the incoming *http.Request in a context.Context,
+// so hooks can inspect it (e.g. remote address or headers).
+func WithHTTPRequest(ctx context.Context, req *http.Request) context.Context {
+	return context.WithValue(ctx, RequestKey, req)
+}
+
+// WithHTTPGet enables GET requests for calls of generated clients with the
+// returned context. Only side-effect-free methods (idempotency_level =
+// NO_SIDE_EFFECTS) are sent as GET, with the request message encoded in the
+// query string, so HTTP caches can serve them.
+func WithHTTPGet(ctx context.Context) context.Context {
+	return context.WithValue(ctx, HTTPGetKey, true)
+}
+
+// WithResponseContentType stores the content type of the response, which
+// generated servers negotiate with the Accept header of the request.
+func WithResponseContentType(ctx context.Context, contentType string) context.Context {
+	return context.WithValue(ctx, ResponseContentTypeKey, contentType)
+}
+
+// ResponseContentType retrieves the negotiated content type of the response,
+// e.g. "application/json".
+func ResponseContentType(ctx context.Context) (string, bool) {
+	contentType, ok := ctx.Value(ResponseContentTypeKey).(string)
+	return contentType, ok
+}
+
+// HTTPGet reports whether GET requests were enabled with WithHTTPGet.
+func HTTPGet(ctx context.Context) bool {
+	get, _ := ctx.Value(HTTPGetKey).(bool)
+	return get
+}
+
 // MethodName extracts the name of the method being handled in the given
 // context. If it is not known, it returns ("", false).
 func MethodName(ctx context.Context) (string, bool) {
@@ -84,9 +121,72 @@ func PackageName(ctx context.Context) (string, bool) {
 // StatusCode retrieves the status code of the response (as string like "200").
 // If it is known returns (status, true).
 // If it is not known, it returns ("", false).
+//
+// Generated servers track the status code sent with their response writer,
+// it is used unless the status code was set with WithStatusCode.
 func StatusCode(ctx context.Context) (string, bool) {
 	code, ok := ctx.Value(StatusCodeKey).(string)
-	return code, ok
+	if ok {
+		return code, ok
+	}
+	if w, ok := ctx.Value(ResponseWriterKey).(interface{ Status() int }); ok && w.Status() != 0 {
+		return strconv.Itoa(w.Status()), true
+	}
+	return "", false
+}
+
+// ResponseBytesWritten retrieves the number of bytes of the response body
+// written so far by a generated server. If it is not known, it returns (0,
+// false).
+func ResponseBytesWritten(ctx context.Context) (int64, bool) {
+	w, ok := ctx.Value(ResponseWriterKey).(interface{ BytesWritten() int64 })
+	if !ok {
+		return 0, false
+	}
+	return w.BytesWritten(), true
+}
+
+// ResponseHeaders retrieves the headers of the response from a context
+// provided by a generated server. If they are not known, it returns (nil,
+// false). The returned header must not be modified, use
+// SetHTTPResponseHeader instead.
+func ResponseHeaders(ctx context.Context) (http.Header, bool) {
+	w, ok := ctx.Value(ResponseWriterKey).(http.ResponseWriter)
+	if !ok {
+		return nil, false
+	}
+	return w.Header(), true
+}
+
+// HTTPRequest retrieves the incoming *http.Request from a context provided
+// by a generated server. If it is not known, it returns (nil, false).
+func HTTPRequest(ctx context.Context) (*http.Request, bool) {
+	req, ok := ctx.Value(RequestKey).(*http.Request)
+	return req, ok
+}
+
+// ClientVersion retrieves the protocol version sent by the client in the
+// XService-Version header of the incoming request from a context provided
+// by a generated server. If the client didn't send it, it returns ("",
+// false).
+func ClientVersion(ctx context.Context) (string, bool) {
+	req, ok := HTTPRequest(ctx)
+	if !ok || req == nil {
+		return "", false
+	}
+	version := req.Header.Get(xhttp.VersionHeader)
+	return version, version != ""
+}
+
+// IncomingHTTPHeaders retrieves the headers of the incoming request from a
+// context provided by a generated server. If they are not known, it returns
+// (nil, false). The returned header must not be modified.
+func IncomingHTTPHeaders(ctx context.Context) (http.Header, bool) {
+	req, ok := HTTPRequest(ctx)
+	if !ok || req == nil {
+		return nil, false
+	}
+	return req.Header, true
 }
 
 // WithHTTPRequestHeaders stores an http.Header in a context.Context. When
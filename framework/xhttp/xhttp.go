@@ -5,3 +5,7 @@ const ContentTypeHeader string = "Content-Type"
 const ApplicationJson = "application/json"
 
 const ApplicationProtobuf = "application/protobuf"
+
+const VersionHeader = "XService-Version"
+
+const JSONOptionsHeader = "XService-JSON-Options"
@@ -0,0 +1,467 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package docproto
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	xerrors "github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/internal/xgenerator/types"
+	"github.com/donutloop/xservice/internal/xproto/typesmap"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatMarkdown string = "markdown"
+	FormatHTML     string = "html"
+)
+
+// Doc generates an API reference of the services, messages and enums of a
+// proto package, one Markdown or HTML file per package.
+type Doc struct {
+	reg *typemap.Registry
+
+	// Format of the generated files, set by the format parameter
+	format string
+	// Route prefix of the documented services, set by the path_prefix
+	// parameter.
+	pathPrefix string
+}
+
+func NewDocGenerator() *Doc {
+	return &Doc{
+		format:     FormatMarkdown,
+		pathPrefix: transport.DefaultPathPrefix,
+	}
+}
+
+func (d *Doc) Generate(in *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
+	if err := d.parseParameters(in.GetParameter()); err != nil {
+		return nil, err
+	}
+
+	d.reg = typemap.New(in.ProtoFile)
+
+	// Files of the same package are documented together, in the order of
+	// the files to generate.
+	var pkgs []string
+	filesByPkg := make(map[string][]*descriptor.FileDescriptorProto)
+	for _, name := range in.FileToGenerate {
+		for _, f := range in.ProtoFile {
+			if f.GetName() != name {
+				continue
+			}
+			pkg := f.GetPackage()
+			if pkg == "" {
+				pkg = types.BaseName(f.GetName())
+			}
+			if _, ok := filesByPkg[pkg]; !ok {
+				pkgs = append(pkgs, pkg)
+			}
+			filesByPkg[pkg] = append(filesByPkg[pkg], f)
+		}
+	}
+
+	resp := new(plugin.CodeGeneratorResponse)
+	for _, pkg := range pkgs {
+		doc, err := d.packageDoc(pkg, filesByPkg[pkg])
+		if err != nil {
+			return nil, err
+		}
+		content, err := d.render(doc)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not render the documentation of %s", pkg)
+		}
+		resp.File = append(resp.File, &plugin.CodeGeneratorResponse_File{
+			Name:    proto.String(d.fileName(pkg)),
+			Content: proto.String(content),
+		})
+	}
+	return resp, nil
+}
+
+// parseParameters parses the comma separated key=value parameters of the
+// plugin, e.g. --xservice-doc_out=format=html,path_prefix=/api/v2:.
+func (d *Doc) parseParameters(parameter string) error {
+	if parameter == "" {
+		return nil
+	}
+	for _, p := range strings.Split(parameter, ",") {
+		var key, value string
+		if i := strings.Index(p, "="); i < 0 {
+			key = p
+		} else {
+			key, value = p[:i], p[i+1:]
+		}
+
+		switch key {
+		case "format":
+			if value != FormatMarkdown && value != FormatHTML {
+				return errors.Errorf("invalid value %q of parameter format", value)
+			}
+			d.format = value
+		case "path_prefix":
+			d.pathPrefix = value
+		default:
+			return errors.Errorf("unknown parameter %q", key)
+		}
+	}
+	return nil
+}
+
+func (d *Doc) fileName(pkg string) string {
+	if d.format == FormatHTML {
+		return pkg + ".html"
+	}
+	return pkg + ".md"
+}
+
+func (d *Doc) render(doc *packageDoc) (string, error) {
+	var buf bytes.Buffer
+	var err error
+	if d.format == FormatHTML {
+		err = htmlTemplate.Execute(&buf, doc)
+	} else {
+		err = markdownTemplate.Execute(&buf, doc)
+	}
+	return buf.String(), err
+}
+
+// packageDoc is the documentation of a proto package.
+type packageDoc struct {
+	Name        string
+	Description string
+	Services    []*serviceDoc
+	Messages    []*messageDoc
+	Enums       []*enumDoc
+	Errors      []*errorDoc
+}
+
+type serviceDoc struct {
+	Name        string
+	Anchor      string
+	Description string
+	Deprecated  bool
+	Methods     []*methodDoc
+}
+
+type methodDoc struct {
+	Name            string
+	Anchor          string
+	Description     string
+	Deprecated      bool
+	HTTPMethods     string
+	Route           string
+	Request         *typeRef
+	Response        *typeRef
+	RequestExample  string
+	ResponseExample string
+}
+
+type typeRef struct {
+	Name   string
+	Anchor string
+}
+
+type messageDoc struct {
+	Name        string
+	Anchor      string
+	Description string
+	Deprecated  bool
+	Fields      []*fieldDoc
+	Example     string
+}
+
+type fieldDoc struct {
+	Name        string
+	Type        *typeRef
+	Description string
+	Deprecated  bool
+}
+
+type enumDoc struct {
+	Name        string
+	Anchor      string
+	Description string
+	Deprecated  bool
+	Values      []*enumValueDoc
+}
+
+type enumValueDoc struct {
+	Name        string
+	Number      int32
+	Description string
+	Deprecated  bool
+}
+
+type errorDoc struct {
+	Code       string
+	Status     int
+	StatusText string
+}
+
+func (d *Doc) packageDoc(pkg string, files []*descriptor.FileDescriptorProto) (*packageDoc, error) {
+	doc := &packageDoc{Name: pkg}
+
+	var descriptions []string
+	for _, f := range files {
+		comments, err := d.reg.FileComments(f)
+		if err != nil {
+			return nil, err
+		}
+		if description := protoDoc(comments); description != "" {
+			descriptions = append(descriptions, description)
+		}
+		for _, e := range f.EnumType {
+			doc.Enums = append(doc.Enums, d.enumDoc(d.reg.EnumDefinition(protoName(f, e.GetName()))))
+		}
+		for _, m := range f.MessageType {
+			d.addMessage(doc, d.reg.MessageDefinition(protoName(f, m.GetName())))
+		}
+	}
+	doc.Description = strings.Join(descriptions, "\n\n")
+
+	for _, f := range files {
+		for _, service := range f.Service {
+			s, err := d.serviceDoc(doc, f, service)
+			if err != nil {
+				return nil, err
+			}
+			doc.Services = append(doc.Services, s)
+		}
+	}
+
+	// Types of fields are linked if they're documented in this package, which
+	// is known once all messages are added.
+	for _, m := range doc.Messages {
+		for _, f := range m.Fields {
+			f.Type.Anchor = doc.anchor(f.Type.Anchor)
+		}
+	}
+
+	for _, code := range xerrors.ErrorCodes() {
+		status := xerrors.ServerHTTPStatusFromErrorCode(code)
+		doc.Errors = append(doc.Errors, &errorDoc{
+			Code:       string(code),
+			Status:     status,
+			StatusText: http.StatusText(status),
+		})
+	}
+	return doc, nil
+}
+
+// addMessage documents a message and its nested messages and enums. Map
+// entries are documented as the type of their fields.
+func (d *Doc) addMessage(doc *packageDoc, msg *typemap.MessageDefinition) {
+	if msg == nil || msg.Descriptor.GetOptions().GetMapEntry() {
+		return
+	}
+
+	m := &messageDoc{
+		Name:        strings.TrimPrefix(msg.ProtoName(), "."),
+		Anchor:      strings.TrimPrefix(msg.ProtoName(), "."),
+		Description: protoDoc(msg.Comments),
+		Deprecated:  msg.Descriptor.GetOptions().GetDeprecated(),
+		Example:     d.example(msg),
+	}
+	for _, field := range msg.Fields {
+		description := protoDoc(field.Comments)
+		if field.Oneof != nil {
+			description = joinParagraphs("Member of the oneof "+field.Oneof.Descriptor.GetName()+".", description)
+		}
+		m.Fields = append(m.Fields, &fieldDoc{
+			Name:        field.Descriptor.GetName(),
+			Type:        d.fieldType(field.Descriptor),
+			Description: description,
+			Deprecated:  field.Descriptor.GetOptions().GetDeprecated(),
+		})
+	}
+	doc.Messages = append(doc.Messages, m)
+
+	for _, e := range msg.Enums {
+		doc.Enums = append(doc.Enums, d.enumDoc(e))
+	}
+	for _, nested := range msg.Descriptor.NestedType {
+		d.addMessage(doc, d.reg.MessageDefinition(msg.ProtoName()+"."+nested.GetName()))
+	}
+}
+
+func (d *Doc) enumDoc(enum *typemap.EnumDefinition) *enumDoc {
+	e := &enumDoc{
+		Name:        strings.TrimPrefix(enum.ProtoName(), "."),
+		Anchor:      strings.TrimPrefix(enum.ProtoName(), "."),
+		Description: protoDoc(enum.Comments),
+		Deprecated:  enum.Descriptor.GetOptions().GetDeprecated(),
+	}
+	for _, value := range enum.Values {
+		e.Values = append(e.Values, &enumValueDoc{
+			Name:        value.Descriptor.GetName(),
+			Number:      value.Descriptor.GetNumber(),
+			Description: protoDoc(value.Comments),
+			Deprecated:  value.Descriptor.GetOptions().GetDeprecated(),
+		})
+	}
+	return e
+}
+
+func (d *Doc) serviceDoc(doc *packageDoc, file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto) (*serviceDoc, error) {
+	comments, err := d.reg.ServiceComments(file, service)
+	if err != nil {
+		return nil, err
+	}
+
+	name := fullServiceName(file, service)
+	s := &serviceDoc{
+		Name:        name,
+		Anchor:      name,
+		Description: protoDoc(comments),
+		Deprecated:  service.GetOptions().GetDeprecated(),
+	}
+
+	prefix := transport.ServicePathPrefix(d.pathPrefix, name)
+	for _, method := range service.Method {
+		if method.GetClientStreaming() || method.GetServerStreaming() {
+			return nil, errors.Errorf("%s.%s: streaming methods are not supported", name, method.GetName())
+		}
+
+		comments, err := d.reg.MethodComments(file, service, method)
+		if err != nil {
+			return nil, err
+		}
+
+		input := d.reg.MethodInputDefinition(method)
+		if input == nil {
+			return nil, errors.Errorf("could not find message for %s", method.GetInputType())
+		}
+		output := d.reg.MethodOutputDefinition(method)
+		if output == nil {
+			return nil, errors.Errorf("could not find message for %s", method.GetOutputType())
+		}
+
+		httpMethods := http.MethodPost
+		if method.GetOptions().GetIdempotencyLevel() == descriptor.MethodOptions_NO_SIDE_EFFECTS {
+			httpMethods += ", " + http.MethodGet
+		}
+
+		s.Methods = append(s.Methods, &methodDoc{
+			Name:            types.CamelCase(method.GetName()),
+			Anchor:          name + "." + types.CamelCase(method.GetName()),
+			Description:     protoDoc(comments),
+			Deprecated:      method.GetOptions().GetDeprecated() || s.Deprecated,
+			HTTPMethods:     httpMethods,
+			Route:           prefix + types.CamelCase(method.GetName()),
+			Request:         d.messageType(doc, input),
+			Response:        d.messageType(doc, output),
+			RequestExample:  d.example(input),
+			ResponseExample: d.example(output),
+		})
+	}
+	return s, nil
+}
+
+func (d *Doc) messageType(doc *packageDoc, msg *typemap.MessageDefinition) *typeRef {
+	name := strings.TrimPrefix(msg.ProtoName(), ".")
+	return &typeRef{Name: name, Anchor: doc.anchor(name)}
+}
+
+// fieldType describes the type of a field like in a proto file, e.g.
+// "repeated string" or "map<string, example.Item>". The anchor of message and
+// enum types is their name until the package is documented.
+func (d *Doc) fieldType(field *descriptor.FieldDescriptorProto) *typeRef {
+	if entry := d.mapEntry(field); entry != nil {
+		key, value := d.fieldType(entry.Fields[0].Descriptor), d.fieldType(entry.Fields[1].Descriptor)
+		return &typeRef{Name: "map<" + key.Name + ", " + value.Name + ">", Anchor: value.Anchor}
+	}
+
+	t := &typeRef{Name: strings.ToLower(strings.TrimPrefix(field.GetType().String(), "TYPE_"))}
+	if field.GetTypeName() != "" {
+		t.Name = strings.TrimPrefix(field.GetTypeName(), ".")
+		t.Anchor = t.Name
+	}
+	if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		t.Name = "repeated " + t.Name
+	}
+	return t
+}
+
+// mapEntry returns the entry message of a map field, or nil if the field
+// isn't a map.
+func (d *Doc) mapEntry(field *descriptor.FieldDescriptorProto) *typemap.MessageDefinition {
+	if field.GetType() != descriptor.FieldDescriptorProto_TYPE_MESSAGE || field.GetLabel() != descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		return nil
+	}
+	entry := d.reg.MessageDefinition(field.GetTypeName())
+	if entry == nil || !entry.Descriptor.GetOptions().GetMapEntry() || len(entry.Fields) != 2 {
+		return nil
+	}
+	return entry
+}
+
+// anchor returns the anchor of a message or enum documented in the package,
+// or "" if it's documented elsewhere.
+func (doc *packageDoc) anchor(name string) string {
+	for _, m := range doc.Messages {
+		if m.Name == name {
+			return m.Anchor
+		}
+	}
+	for _, e := range doc.Enums {
+		if e.Name == name {
+			return e.Anchor
+		}
+	}
+	return ""
+}
+
+// protoDoc joins the comments of a definition to paragraphs.
+func protoDoc(comments typemap.DefinitionComments) string {
+	return joinParagraphs(append(append([]string{}, comments.LeadingDetached...), comments.Leading, comments.Trailing)...)
+}
+
+// joinParagraphs joins the non-empty paragraphs, without the space which
+// follows the comment markers in proto files.
+func joinParagraphs(paragraphs ...string) string {
+	var nonEmpty []string
+	for _, p := range paragraphs {
+		lines := strings.Split(strings.TrimSpace(p), "\n")
+		for i, line := range lines {
+			lines[i] = strings.TrimRight(strings.TrimPrefix(line, " "), " \t")
+		}
+		if p = strings.Join(lines, "\n"); p != "" {
+			nonEmpty = append(nonEmpty, p)
+		}
+	}
+	return strings.Join(nonEmpty, "\n\n")
+}
+
+func protoName(file *descriptor.FileDescriptorProto, name string) string {
+	if pkg := file.GetPackage(); pkg != "" {
+		return "." + pkg + "." + name
+	}
+	return "." + name
+}
+
+func fullServiceName(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto) string {
+	name := types.CamelCase(service.GetName())
+	if pkg := file.GetPackage(); pkg != "" {
+		name = pkg + "." + name
+	}
+	return name
+}
@@ -0,0 +1,80 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package docproto
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestGolden documents testdata/catalog, the generated files are compared
+// with the golden files testdata/catalog/<file>.golden.
+func TestGolden(t *testing.T) {
+	data, err := ioutil.ReadFile(filepath.Join("testdata", "catalog", "fileset.pb"))
+	if err != nil {
+		t.Fatalf("could not read descriptor set: %v", err)
+	}
+	set := new(descriptor.FileDescriptorSet)
+	if err := proto.Unmarshal(data, set); err != nil {
+		t.Fatalf("could not unmarshal descriptor set: %v", err)
+	}
+
+	for _, parameter := range []string{"", "format=html,path_prefix=/api"} {
+		resp, err := NewDocGenerator().Generate(&plugin.CodeGeneratorRequest{
+			FileToGenerate: []string{"catalog.proto"},
+			Parameter:      proto.String(parameter),
+			ProtoFile:      set.File,
+		})
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", parameter, err)
+		}
+
+		for _, f := range resp.File {
+			golden := filepath.Join("testdata", "catalog", f.GetName()+".golden")
+			if *update {
+				if err := ioutil.WriteFile(golden, []byte(f.GetContent()), 0644); err != nil {
+					t.Fatal(err)
+				}
+				continue
+			}
+
+			want, err := ioutil.ReadFile(golden)
+			if err != nil {
+				t.Errorf("could not read golden file (run go test -update to create it): %v", err)
+				continue
+			}
+			if f.GetContent() != string(want) {
+				t.Errorf("%s differs from the generated documentation (run go test -update to update it)", golden)
+			}
+		}
+	}
+}
+
+func TestDoc_InvalidParameters(t *testing.T) {
+	for _, parameter := range []string{"format=pdf", "messages=true"} {
+		_, err := NewDocGenerator().Generate(&plugin.CodeGeneratorRequest{Parameter: proto.String(parameter)})
+		if err == nil || !strings.Contains(err.Error(), "parameter") {
+			t.Errorf("%q: expected a parameter error, got %v", parameter, err)
+		}
+	}
+}
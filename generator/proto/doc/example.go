@@ -0,0 +1,153 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package docproto
+
+import (
+	"bytes"
+	"strconv"
+
+	"github.com/donutloop/xservice/internal/xproto/typesmap"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// wellKnownExamples are the JSON examples of the well-known types, which
+// are encoded as scalars or special objects.
+var wellKnownExamples = map[string]string{
+	".google.protobuf.Any":         `{"@type": ""}`,
+	".google.protobuf.BoolValue":   `false`,
+	".google.protobuf.BytesValue":  `""`,
+	".google.protobuf.DoubleValue": `0`,
+	".google.protobuf.Duration":    `"0s"`,
+	".google.protobuf.Empty":       `{}`,
+	".google.protobuf.FieldMask":   `""`,
+	".google.protobuf.FloatValue":  `0`,
+	".google.protobuf.Int32Value":  `0`,
+	".google.protobuf.Int64Value":  `"0"`,
+	".google.protobuf.ListValue":   `[]`,
+	".google.protobuf.StringValue": `""`,
+	".google.protobuf.Struct":      `{}`,
+	".google.protobuf.Timestamp":   `"1970-01-01T00:00:00Z"`,
+	".google.protobuf.UInt32Value": `0`,
+	".google.protobuf.UInt64Value": `"0"`,
+	".google.protobuf.Value":       `null`,
+}
+
+// example renders an example JSON body of a message like the default JSON
+// encoding of the transport: with the original proto field names, enums by
+// name and 64-bit integers as strings. Every field is set to its default
+// value, repeated fields and maps have one element and only the first member
+// of a oneof is set. Recursive messages are empty at their second level.
+func (d *Doc) example(msg *typemap.MessageDefinition) string {
+	var buf bytes.Buffer
+	d.writeMessageExample(&buf, msg, "", make(map[string]bool))
+	return buf.String()
+}
+
+func (d *Doc) writeMessageExample(buf *bytes.Buffer, msg *typemap.MessageDefinition, indent string, seen map[string]bool) {
+	name := msg.ProtoName()
+	if example, ok := wellKnownExamples[name]; ok {
+		buf.WriteString(example)
+		return
+	}
+
+	var fields []*typemap.FieldDefinition
+	oneofs := make(map[*typemap.OneofDefinition]bool)
+	for _, field := range msg.Fields {
+		if field.Oneof != nil {
+			if oneofs[field.Oneof] {
+				continue
+			}
+			oneofs[field.Oneof] = true
+		}
+		fields = append(fields, field)
+	}
+	if len(fields) == 0 || seen[name] {
+		buf.WriteString("{}")
+		return
+	}
+
+	seen[name] = true
+	defer delete(seen, name)
+
+	buf.WriteString("{\n")
+	for i, field := range fields {
+		buf.WriteString(indent + "  " + strconv.Quote(field.Descriptor.GetName()) + ": ")
+		d.writeFieldExample(buf, field.Descriptor, indent+"  ", seen)
+		if i < len(fields)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+	buf.WriteString(indent + "}")
+}
+
+func (d *Doc) writeFieldExample(buf *bytes.Buffer, field *descriptor.FieldDescriptorProto, indent string, seen map[string]bool) {
+	if entry := d.mapEntry(field); entry != nil {
+		buf.WriteString("{\n" + indent + "  " + mapKeyExample(entry.Fields[0].Descriptor) + ": ")
+		d.writeValueExample(buf, entry.Fields[1].Descriptor, indent+"  ", seen)
+		buf.WriteString("\n" + indent + "}")
+		return
+	}
+	if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		buf.WriteString("[\n" + indent + "  ")
+		d.writeValueExample(buf, field, indent+"  ", seen)
+		buf.WriteString("\n" + indent + "]")
+		return
+	}
+	d.writeValueExample(buf, field, indent, seen)
+}
+
+func (d *Doc) writeValueExample(buf *bytes.Buffer, field *descriptor.FieldDescriptorProto, indent string, seen map[string]bool) {
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE, descriptor.FieldDescriptorProto_TYPE_GROUP:
+		msg := d.reg.MessageDefinition(field.GetTypeName())
+		if msg == nil {
+			buf.WriteString("{}")
+			return
+		}
+		d.writeMessageExample(buf, msg, indent, seen)
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		enum := d.reg.EnumDefinition(field.GetTypeName())
+		if enum == nil || len(enum.Values) == 0 {
+			buf.WriteString("0")
+			return
+		}
+		buf.WriteString(strconv.Quote(enum.Values[0].Descriptor.GetName()))
+	case descriptor.FieldDescriptorProto_TYPE_STRING, descriptor.FieldDescriptorProto_TYPE_BYTES:
+		buf.WriteString(`""`)
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		buf.WriteString("false")
+	case descriptor.FieldDescriptorProto_TYPE_INT64,
+		descriptor.FieldDescriptorProto_TYPE_UINT64,
+		descriptor.FieldDescriptorProto_TYPE_SINT64,
+		descriptor.FieldDescriptorProto_TYPE_FIXED64,
+		descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		buf.WriteString(`"0"`)
+	default:
+		buf.WriteString("0")
+	}
+}
+
+// mapKeyExample returns the example key of a map, JSON object keys are
+// always strings.
+func mapKeyExample(key *descriptor.FieldDescriptorProto) string {
+	switch key.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_STRING:
+		return `""`
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		return `"false"`
+	default:
+		return `"0"`
+	}
+}
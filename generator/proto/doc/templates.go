@@ -0,0 +1,247 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package docproto
+
+import (
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+)
+
+const errorExample = `{"code": "not_found", "msg": "order 1 not found", "meta": {"id": "1"}}`
+
+var templateFuncs = map[string]interface{}{
+	// cell escapes a text for a cell of a Markdown table.
+	"cell": func(s string) string {
+		return strings.NewReplacer("\n\n", "<br><br>", "\n", " ", "|", `\|`).Replace(s)
+	},
+	"paragraphs": func(s string) []string {
+		if s == "" {
+			return nil
+		}
+		return strings.Split(s, "\n\n")
+	},
+	"errorExample": func() string { return errorExample },
+}
+
+var markdownTemplate = template.Must(template.New("markdown").Funcs(templateFuncs).Parse(`# {{.Name}}
+{{- with .Description}}
+
+{{.}}
+{{- end}}
+{{- if .Services}}
+
+## Services
+{{- range $service := .Services}}
+
+<a name="{{.Anchor}}"></a>
+### {{.Name}}
+{{- if .Deprecated}}
+
+**Deprecated.**
+{{- end}}
+{{- with .Description}}
+
+{{.}}
+{{- end}}
+{{- range .Methods}}
+
+<a name="{{.Anchor}}"></a>
+#### {{$service.Name}}.{{.Name}}
+{{- if .Deprecated}}
+
+**Deprecated.**
+{{- end}}
+{{- with .Description}}
+
+{{.}}
+{{- end}}
+
+` + "`{{.HTTPMethods}} {{.Route}}`" + `
+
+| Request | Response |
+| --- | --- |
+| {{template "typeRef" .Request}} | {{template "typeRef" .Response}} |
+
+Request:
+
+` + "```json" + `
+{{.RequestExample}}
+` + "```" + `
+
+Response:
+
+` + "```json" + `
+{{.ResponseExample}}
+` + "```" + `
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Messages}}
+
+## Messages
+{{- range .Messages}}
+
+<a name="{{.Anchor}}"></a>
+### {{.Name}}
+{{- if .Deprecated}}
+
+**Deprecated.**
+{{- end}}
+{{- with .Description}}
+
+{{.}}
+{{- end}}
+{{- if .Fields}}
+
+| Field | Type | Description |
+| --- | --- | --- |
+{{- range .Fields}}
+| ` + "`{{.Name}}`" + ` | {{template "typeRef" .Type}} | {{if .Deprecated}}**Deprecated.**{{with .Description}} {{end}}{{end}}{{cell .Description}} |
+{{- end}}
+{{- end}}
+
+` + "```json" + `
+{{.Example}}
+` + "```" + `
+{{- end}}
+{{- end}}
+{{- if .Enums}}
+
+## Enums
+{{- range .Enums}}
+
+<a name="{{.Anchor}}"></a>
+### {{.Name}}
+{{- if .Deprecated}}
+
+**Deprecated.**
+{{- end}}
+{{- with .Description}}
+
+{{.}}
+{{- end}}
+
+| Value | Number | Description |
+| --- | --- | --- |
+{{- range .Values}}
+| ` + "`{{.Name}}`" + ` | {{.Number}} | {{if .Deprecated}}**Deprecated.**{{with .Description}} {{end}}{{end}}{{cell .Description}} |
+{{- end}}
+{{- end}}
+{{- end}}
+
+## Errors
+
+Errors are responded with their HTTP status and a JSON body of their code, a message and optional metadata:
+
+` + "```json" + `
+{{errorExample}}
+` + "```" + `
+
+| Code | HTTP status |
+| --- | --- |
+{{- range .Errors}}
+| ` + "`{{.Code}}`" + ` | {{.Status}} {{.StatusText}} |
+{{- end}}
+{{define "typeRef"}}{{if .Anchor}}[` + "`{{.Name}}`" + `](#{{.Anchor}}){{else}}` + "`{{.Name}}`" + `{{end}}{{end -}}
+`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("html").Funcs(templateFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+<style>
+body { font-family: sans-serif; max-width: 960px; margin: 0 auto; padding: 0 1em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+pre { background: #f6f8fa; padding: 0.6em; }
+.deprecated { color: #b00; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+{{- template "description" .}}
+{{- if .Services}}
+<h2>Services</h2>
+{{- range $service := .Services}}
+<h3 id="{{.Anchor}}">{{.Name}}</h3>
+{{- template "deprecated" .}}
+{{- template "description" .}}
+{{- range .Methods}}
+<h4 id="{{.Anchor}}">{{$service.Name}}.{{.Name}}</h4>
+{{- template "deprecated" .}}
+{{- template "description" .}}
+<p><code>{{.HTTPMethods}} {{.Route}}</code></p>
+<table>
+<tr><th>Request</th><th>Response</th></tr>
+<tr><td>{{template "typeRef" .Request}}</td><td>{{template "typeRef" .Response}}</td></tr>
+</table>
+<p>Request:</p>
+<pre><code>{{.RequestExample}}</code></pre>
+<p>Response:</p>
+<pre><code>{{.ResponseExample}}</code></pre>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Messages}}
+<h2>Messages</h2>
+{{- range .Messages}}
+<h3 id="{{.Anchor}}">{{.Name}}</h3>
+{{- template "deprecated" .}}
+{{- template "description" .}}
+{{- if .Fields}}
+<table>
+<tr><th>Field</th><th>Type</th><th>Description</th></tr>
+{{- range .Fields}}
+<tr><td><code>{{.Name}}</code></td><td>{{template "typeRef" .Type}}</td><td>{{if .Deprecated}}<span class="deprecated">Deprecated.</span>{{with .Description}} {{end}}{{end}}{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<pre><code>{{.Example}}</code></pre>
+{{- end}}
+{{- end}}
+{{- if .Enums}}
+<h2>Enums</h2>
+{{- range .Enums}}
+<h3 id="{{.Anchor}}">{{.Name}}</h3>
+{{- template "deprecated" .}}
+{{- template "description" .}}
+<table>
+<tr><th>Value</th><th>Number</th><th>Description</th></tr>
+{{- range .Values}}
+<tr><td><code>{{.Name}}</code></td><td>{{.Number}}</td><td>{{if .Deprecated}}<span class="deprecated">Deprecated.</span>{{with .Description}} {{end}}{{end}}{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+<h2>Errors</h2>
+<p>Errors are responded with their HTTP status and a JSON body of their code, a message and optional metadata:</p>
+<pre><code>{{errorExample}}</code></pre>
+<table>
+<tr><th>Code</th><th>HTTP status</th></tr>
+{{- range .Errors}}
+<tr><td><code>{{.Code}}</code></td><td>{{.Status}} {{.StatusText}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+{{define "description"}}{{range paragraphs .Description}}
+<p>{{.}}</p>
+{{- end}}{{end -}}
+{{define "deprecated"}}{{if .Deprecated}}
+<p class="deprecated">Deprecated.</p>
+{{- end}}{{end -}}
+{{define "typeRef"}}{{if .Anchor}}<a href="#{{.Anchor}}"><code>{{.Name}}</code></a>{{else}}<code>{{.Name}}</code>{{end}}{{end -}}
+`))
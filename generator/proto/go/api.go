@@ -20,7 +20,11 @@ package goproto
 import (
 	"bytes"
 	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"github.com/donutloop/xservice/framework/options"
+	"github.com/donutloop/xservice/framework/transport"
 	"github.com/donutloop/xservice/internal/xgenerator/types"
 	"github.com/donutloop/xservice/internal/xproto"
 	"github.com/donutloop/xservice/internal/xproto/typesmap"
@@ -30,6 +34,7 @@ import (
 	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
 	"github.com/pkg/errors"
 	"go/token"
+	"path"
 	"strconv"
 	"strings"
 )
@@ -37,6 +42,7 @@ import (
 const (
 	ServeJSON        string = "JSON"
 	ServeProtobuffer string = "Protobuffer"
+	ServeREST        string = "REST"
 )
 
 type API struct {
@@ -45,10 +51,26 @@ type API struct {
 
 	reg *typemap.Registry
 
-	// Map to record whether we've built each package
+	// Map of the Go import paths of dependencies to their package names
 	pkgs          map[string]string
 	pkgNamesInUse map[string]bool
 
+	// Go import paths of files given by M<file>=<import path> parameters,
+	// they take precedence over go_package options.
+	importMap map[string]string
+	// Go import paths of dependencies outside of the generated package
+	fileToGoImportPath map[*descriptor.FileDescriptorProto]string
+
+	// Whether messages and enums are generated, set by the messages parameter
+	genMessages bool
+	// Whether <Service>Unimplemented structs are generated, set by the
+	// unimplemented parameter, and the scaffolds of servers, set by the
+	// scaffold parameter.
+	genUnimplemented bool
+	genScaffold      bool
+	// Names of the generated scaffolds
+	scaffolds map[string]bool
+
 	// Package naming:
 	genPkgName          string // Name of the package that we're generating
 	fileToGoPackageName map[*descriptor.FileDescriptorProto]string
@@ -60,6 +82,10 @@ type API struct {
 	// Output buffer that holds the bytes we want to write out for a single file.
 	// Gets reset after working on a file.
 	output *bytes.Buffer
+
+	// Route prefix of the generated services, set by the path_prefix
+	// parameter.
+	pathPrefix string
 }
 
 func NewAPIGenerator() *API {
@@ -67,6 +93,10 @@ func NewAPIGenerator() *API {
 		pkgs:                make(map[string]string),
 		pkgNamesInUse:       make(map[string]bool),
 		fileToGoPackageName: make(map[*descriptor.FileDescriptorProto]string),
+		importMap:           make(map[string]string),
+		fileToGoImportPath:  make(map[*descriptor.FileDescriptorProto]string),
+		scaffolds:           make(map[string]bool),
+		pathPrefix:          transport.DefaultPathPrefix,
 	}
 	return gen
 }
@@ -88,6 +118,10 @@ Outer:
 }
 
 func (a *API) Generate(in *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorResponse, error) {
+	if err := a.parseParameters(in.GetParameter()); err != nil {
+		return nil, err
+	}
+
 	a.genFiles = FilesToGenerate(in)
 
 	// Collect information on types.
@@ -103,19 +137,45 @@ func (a *API) Generate(in *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorRe
 	}
 	a.genPkgName = genPkgName
 
+	// Names of the generated package and of the packages imported by
+	// generated code can't be used for dependencies.
+	a.pkgNamesInUse[a.genPkgName] = true
+	for _, name := range reservedPkgNames {
+		a.pkgNamesInUse[name] = true
+	}
+
 	// Next, we need to pick names for all the files that are dependencies.
+	genImportPath := a.goImportPath(a.genFiles[0])
 	for _, f := range in.ProtoFile {
 		if fileDescSliceContains(a.genFiles, f) {
 			// This is a file we are generating. It gets the shared package name.
 			a.fileToGoPackageName[f] = a.genPkgName
-		} else {
-			// This is a dependency. Use its package name.
+			continue
+		}
+
+		importPath := a.goImportPath(f)
+		switch {
+		case importPath == "":
+			// This is a dependency without known import path. Use its package name.
 			name := f.GetPackage()
 			if name == "" {
 				name = types.BaseName(f.GetName())
 			}
 			name = types.Identifier(name)
 			a.fileToGoPackageName[f] = name
+		case importPath == genImportPath:
+			// This is a file of the generated package, which isn't generated.
+			a.fileToGoPackageName[f] = a.genPkgName
+		default:
+			// This is a dependency in another package. Files of the same
+			// package share its name, names of different packages are unique.
+			name, ok := a.pkgs[importPath]
+			if !ok {
+				name = a.uniquePkgName(goPackageNameOf(f, importPath))
+				a.pkgs[importPath] = name
+			}
+			a.fileToGoPackageName[f] = name
+			a.fileToGoImportPath[f] = importPath
 		}
 	}
 
@@ -129,22 +189,76 @@ func (a *API) Generate(in *plugin.CodeGeneratorRequest) (*plugin.CodeGeneratorRe
 		if respFile != nil {
 			resp.File = append(resp.File, respFile)
 		}
+
+		if a.genScaffold && len(f.Service) > 0 {
+			scaffold, err := a.generateScaffold(f)
+			if err != nil {
+				return nil, err
+			}
+			resp.File = append(resp.File, scaffold)
+		}
 	}
 	return resp, nil
 }
 
+// parseParameters parses the comma separated key=value parameters of the
+// plugin, e.g. --xservice_out=path_prefix=/api/v2:.
+func (a *API) parseParameters(parameter string) error {
+	if parameter == "" {
+		return nil
+	}
+	for _, p := range strings.Split(parameter, ",") {
+		var key, value string
+		if i := strings.Index(p, "="); i < 0 {
+			key = p
+		} else {
+			key, value = p[:i], p[i+1:]
+		}
+
+		switch {
+		case key == "path_prefix":
+			a.pathPrefix = value
+		case key == "messages":
+			genMessages, err := strconv.ParseBool(value)
+			if err != nil {
+				return errors.Errorf("invalid value %q of parameter messages", value)
+			}
+			a.genMessages = genMessages
+		case key == "unimplemented":
+			genUnimplemented, err := strconv.ParseBool(value)
+			if err != nil {
+				return errors.Errorf("invalid value %q of parameter unimplemented", value)
+			}
+			a.genUnimplemented = genUnimplemented
+		case key == "scaffold":
+			genScaffold, err := strconv.ParseBool(value)
+			if err != nil {
+				return errors.Errorf("invalid value %q of parameter scaffold", value)
+			}
+			a.genScaffold = genScaffold
+		case strings.HasPrefix(key, "M"):
+			// Mfoo/bar.proto=example.com/foo/bar sets the import path of a file.
+			a.importMap[key[1:]] = value
+		default:
+			return errors.Errorf("unknown parameter %q", key)
+		}
+	}
+	return nil
+}
+
 func (a *API) generate(fileDescriptor *descriptor.FileDescriptorProto) (*plugin.CodeGeneratorResponse_File, error) {
 	resp := new(plugin.CodeGeneratorResponse_File)
-	if len(fileDescriptor.Service) == 0 {
+	if len(fileDescriptor.Service) == 0 && !a.genMessages {
 		return nil, nil
 	}
 
-	packageName := fileDescriptor.GetOptions().GetGoPackage()
-	if packageName == "" {
+	if fileDescriptor.GetOptions().GetGoPackage() == "" {
 		return nil, errors.New("go package property is empty")
 	}
 
-	goFile, err := types.NewGoFile(packageName, *fileDescriptor.Name)
+	// go_package may be an import path, the package is named like in the
+	// files generated by protoc-gen-go.
+	goFile, err := types.NewGoFile(a.genPkgName, *fileDescriptor.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -153,6 +267,12 @@ func (a *API) generate(fileDescriptor *descriptor.FileDescriptorProto) (*plugin.
 
 	a.generateAdditionalImports(fileDescriptor, goFile)
 
+	if a.genMessages {
+		if err := a.generateMessages(fileDescriptor, goFile); err != nil {
+			return nil, err
+		}
+	}
+
 	// For each service, generate client stubs and server
 	for i, service := range fileDescriptor.Service {
 		goFile, err = a.generateService(fileDescriptor, service, goFile, i)
@@ -218,6 +338,25 @@ func (a *API) generateAdditionalImports(file *descriptor.FileDescriptorProto, go
 		return
 	}
 
+	// Packages of request and response messages of other packages.
+	imported := make(map[string]bool)
+	for _, service := range file.Service {
+		for _, method := range service.Method {
+			for _, protoName := range []string{method.GetInputType(), method.GetOutputType()} {
+				def := a.reg.MessageDefinition(protoName)
+				if def == nil {
+					continue
+				}
+				importPath, ok := a.fileToGoImportPath[def.File]
+				if !ok || imported[importPath] {
+					continue
+				}
+				goFile.Import(a.goPackageName(def.File), importPath)
+				imported[importPath] = true
+			}
+		}
+	}
+
 	goFile.Import("", "github.com/donutloop/xservice/framework/transport")
 	goFile.Import("", "github.com/donutloop/xservice/framework/xcontext")
 	goFile.Import("", "github.com/donutloop/xservice/framework/errors")
@@ -228,6 +367,13 @@ func (a *API) generateAdditionalImports(file *descriptor.FileDescriptorProto, go
 
 func (a *API) generateService(fileDescriptor *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, goFile *types.FileGenerator, index int) (*types.FileGenerator, error) {
 
+	// Requests and responses are single messages of an HTTP request.
+	for _, method := range service.Method {
+		if method.GetClientStreaming() || method.GetServerStreaming() {
+			return nil, errors.Errorf("%s.%s: streaming methods are not supported", fullServiceName(fileDescriptor, service), method.GetName())
+		}
+	}
+
 	var err error
 
 	// interface
@@ -236,6 +382,14 @@ func (a *API) generateService(fileDescriptor *descriptor.FileDescriptorProto, se
 		return nil, err
 	}
 
+	// Scaffolds embed the <Service>Unimplemented structs.
+	if a.genUnimplemented || a.genScaffold {
+		goFile, err = a.generateUnimplemented(fileDescriptor, service, goFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// JSON Client
 	goFile, err = a.generateClient(ServeJSON, fileDescriptor, service, goFile)
 	if err != nil {
@@ -248,8 +402,32 @@ func (a *API) generateService(fileDescriptor *descriptor.FileDescriptorProto, se
 		return nil, err
 	}
 
+	// In-process client
+	goFile, err = a.generateLocalClient(fileDescriptor, service, goFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// REST Client, for the routes of google.api.http annotations
+	hasRules, err := hasHTTPRules(service)
+	if err != nil {
+		return nil, err
+	}
+	if hasRules {
+		goFile, err = a.generateClient(ServeREST, fileDescriptor, service, goFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Server
-	goFile, err = a.generateServer(fileDescriptor, service, goFile)
+	goFile, err = a.generateServer(fileDescriptor, service, goFile, index)
+	if err != nil {
+		return nil, err
+	}
+
+	// Authorization policy
+	goFile, err = a.generatePolicy(fileDescriptor, service, goFile)
 	if err != nil {
 		return nil, err
 	}
@@ -264,30 +442,19 @@ func (a *API) generatServiceInterface(file *descriptor.FileDescriptorProto, serv
 		return nil, err
 	}
 
-	comments, err := a.reg.ServiceComments(file, service)
-	if err == nil {
-		comment, err := prepareComment(comments)
-		if err != nil {
-			if err != EmptyComment {
-				return nil, err
-			}
-		} else {
-			serviceInterface.InterfaceMetadata.HeaderComment = comment
+	comment, err := prepareComment(a.serviceDoc(file, service))
+	if err != nil {
+		if err != EmptyComment {
+			return nil, err
 		}
+	} else {
+		serviceInterface.InterfaceMetadata.HeaderComment = comment
 	}
 
 	for _, method := range service.Method {
-		comments, err = a.reg.MethodComments(file, service, method)
-		var comment string
-		var err error
-		if err == nil {
-			comment, err = prepareComment(comments)
-			if err != nil {
-				if err != EmptyComment {
-					return nil, err
-				}
-			}
-		}
+		// Comments of prototypes are plain text, the interface renders
+		// their lines.
+		comment := a.methodDoc(file, service, method)
 
 		inputType, err := a.goTypeName(method.GetInputType())
 		if err != nil {
@@ -338,17 +505,29 @@ func (a *API) generateClient(name string, fileDescriptor *descriptor.FileDescrip
 	if err != nil {
 		return nil, err
 	}
-	structGenerator.StructMetaData.Comment = append(structGenerator.StructMetaData.Comment, fmt.Sprintf("%s wraps an http.client and sends %s objects", structName, name))
+	comment := fmt.Sprintf("%s wraps an http.client and sends %s objects", structName, name)
+	if name == ServeREST {
+		comment = fmt.Sprintf("%s wraps an http.client and calls the RESTful routes of the server", structName)
+	}
+	structGenerator.StructMetaData.Comment = append(structGenerator.StructMetaData.Comment, structGenerator.PrepareComment(withDoc(comment, a.serviceDoc(fileDescriptor, service)))...)
 
 	structGenerator.AddUnexportedField("client", types.NewUnsafeTypeReference("transport.HTTPClient"), "")
 	structGenerator.AddUnexportedField("urls", types.NewUnsafeTypeReference(fmt.Sprintf("[%s]string", methCnt)), "")
+	structGenerator.AddUnexportedField("options", types.NewUnsafeTypeReference("*transport.ClientOptions"), "")
+	if name == ServeREST {
+		structGenerator.AddUnexportedField("addr", types.String, "")
+	}
 
-	goFile, err = a.generateClientConstructor(newClientFunc, structGenerator.StructMetaData.Name, service, goFile)
+	goFile, err = a.generateClientConstructor(newClientFunc, structGenerator.StructMetaData.Name, fileDescriptor, service, name == ServeREST, goFile)
 	if err != nil {
 		return nil, err
 	}
 
-	structGenerator, err = a.generateClientEndpoints(name, fileDescriptor, service, structGenerator)
+	if name == ServeREST {
+		structGenerator, err = a.generateRESTClientEndpoints(fileDescriptor, service, structGenerator)
+	} else {
+		structGenerator, err = a.generateClientEndpoints(name, fileDescriptor, service, structGenerator)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -360,11 +539,13 @@ func (a *API) generateClient(name string, fileDescriptor *descriptor.FileDescrip
 	return goFile, nil
 }
 
-func (a *API) generateClientConstructor(newClientFuncName, structName string, service *descriptor.ServiceDescriptorProto, goFile *types.FileGenerator) (*types.FileGenerator, error) {
+func (a *API) generateClientConstructor(newClientFuncName, structName string, file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, withAddr bool, goFile *types.FileGenerator) (*types.FileGenerator, error) {
 
 	pathPrefixConst := serviceName(service) + "PathPrefix"
+	newClientWithOptionsFuncName := newClientFuncName + "WithOptions"
 
-	comment := fmt.Sprintf("%s constructs a new client, which wraps the http.client and implements %s", newClientFuncName, serviceName(service))
+	doc := a.serviceDoc(file, service)
+	comment := withDoc(fmt.Sprintf("%s constructs a new client, which wraps the http.client and implements %s", newClientFuncName, serviceName(service)), doc)
 	f, err := types.NewGoFunc(newClientFuncName, []*types.Parameter{
 		{
 			NameOfParameter: "addr",
@@ -382,46 +563,66 @@ func (a *API) generateClientConstructor(newClientFuncName, structName string, se
 		return nil, err
 	}
 
-	f.DefAssginCall([]string{"URLBase"}, types.NewUnsafeTypeReference("transport.UrlBase"), []string{"addr"})
-	f.DefOperation("prefix", "URLBase", token.ADD, pathPrefixConst)
+	f.Return([]string{newClientWithOptionsFuncName + "(addr, client)"})
 
-	urlsSlice, err := types.NewGoSliceLiteral("urls", types.String, len(service.Method))
-	if err != nil {
+	if err := goFile.Func(f); err != nil {
 		return nil, err
 	}
-	for _, method := range service.Method {
-		urlsSlice.Append(fmt.Sprintf(`prefix + "%s"`, methodName(method)))
-	}
 
-	if err := f.SliceLiteral(*urlsSlice); err != nil {
+	comment = withDoc(fmt.Sprintf("%s constructs a new client configured by opts, which wraps the http.client and implements %s", newClientWithOptionsFuncName, serviceName(service)), doc)
+	f, err = types.NewGoFunc(newClientWithOptionsFuncName, []*types.Parameter{
+		{
+			NameOfParameter: "addr",
+			Typ:             types.String,
+		},
+		{
+			NameOfParameter: "client",
+			Typ:             types.NewUnsafeTypeReference("transport.HTTPClient"),
+		},
+		{
+			NameOfParameter: "opts",
+			Typ:             types.NewUnsafeTypeReference("...transport.ClientOption"),
+		},
+	},
+		[]types.TypeReference{
+			types.NewUnsafeTypeReference(serviceName(service)),
+		}, comment)
+	if err != nil {
 		return nil, err
 	}
 
-	f.DefAssert([]string{"httpClient", "ok"}, "client", types.NewUnsafeTypeReference("*http.Client"))
-	f.DefIfBegin("ok", token.EQL, "true")
+	f.DefAssginCall([]string{"options"}, types.NewUnsafeTypeReference("transport.NewClientOptions"), []string{"opts..."})
+	// Services without methods need neither the address nor the prefix.
+	if len(service.Method) > 0 || withAddr {
+		f.DefAssginCall([]string{"URLBase"}, types.NewUnsafeTypeReference("transport.UrlBase"), []string{"addr"})
+	}
+	if len(service.Method) > 0 {
+		f.DefAssginCall([]string{"prefix"}, types.NewUnsafeTypeReference("URLBase + options.ServicePathPrefix"), []string{pathPrefixConst, strconv.Quote(fullServiceName(file, service))})
+	}
 
-	initStructGeneratorForHttpClient, err := types.NewInitGoStruct(structName)
+	urlsSlice, err := types.NewGoSliceLiteral("urls", types.String, len(service.Method))
 	if err != nil {
 		return nil, err
 	}
+	for _, method := range service.Method {
+		urlsSlice.Append(fmt.Sprintf(`prefix + "%s"`, methodName(method)))
+	}
 
-	f.DefCall([]string{"httpClient"}, types.NewUnsafeTypeReference("transport.WithoutRedirects"), []string{"httpClient"})
-	initStructGeneratorForHttpClient.AddUnexportedValueToField("client", "httpClient")
-	initStructGeneratorForHttpClient.AddUnexportedValueToField("urls", "urls")
-
-	if err := f.InitStruct("return", initStructGeneratorForHttpClient, true); err != nil {
+	if err := f.SliceLiteral(*urlsSlice); err != nil {
 		return nil, err
 	}
 
-	f.CloseIf()
-
 	initStructGenerator, err := types.NewInitGoStruct(structName)
 	if err != nil {
 		return nil, err
 	}
 
-	initStructGenerator.AddUnexportedValueToField("client", "client")
+	initStructGenerator.AddUnexportedValueToField("client", "options.HTTPClient(client)")
 	initStructGenerator.AddUnexportedValueToField("urls", "urls")
+	initStructGenerator.AddUnexportedValueToField("options", "options")
+	if withAddr {
+		initStructGenerator.AddUnexportedValueToField("addr", "URLBase")
+	}
 
 	if err := f.InitStruct("return", initStructGenerator, true); err != nil {
 		return nil, err
@@ -449,7 +650,7 @@ func (a *API) generateClientEndpoints(contentType string, fileDescriptor *descri
 			return nil, err
 		}
 
-		comment := fmt.Sprintf("%s sends an %s %s object to the server", methName, inputType, contentType)
+		comment := withDoc(fmt.Sprintf("%s sends an %s %s object to the server", methName, inputType, contentType), a.methodDoc(fileDescriptor, service, method))
 		method, err := types.NewGoMethod("c", fmt.Sprintf("*%s", structGenerator.StructMetaData.Name), methName, []*types.Parameter{
 			{
 				NameOfParameter: "ctx",
@@ -471,29 +672,73 @@ func (a *API) generateClientEndpoints(contentType string, fileDescriptor *descri
 		method.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithPackageName"), []string{"ctx", `"` + pkgName + `"`})
 		method.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithServiceName"), []string{"ctx", `"` + servName + `"`})
 		method.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithMethodName"), []string{"ctx", `"` + methName + `"`})
-		method.DefNew("out", types.NewUnsafeTypeReference(outputType))
-		method.DefAssginCall([]string{"err"}, types.NewUnsafeTypeReference(fmt.Sprintf("transport.Do%sRequest", contentType)), []string{"ctx", "c.client", fmt.Sprintf("c.urls[%s]", strconv.Itoa(i)), "in", "out"})
-		method.Return([]string{"out", "err"})
+		// Clients encode messages with the codec and JSON options of the
+		// client.
+		doRequest := fmt.Sprintf("c.options.Do%sRequest", contentType)
+		if sideEffectFree(service.Method[i]) {
+			doRequest = fmt.Sprintf("c.options.DoIdempotent%sRequest", contentType)
+		}
+		if err := generateInterceptedClientCall(&method.GoBlockGenerator, inputType, outputType, doRequest, "c.client", fmt.Sprintf("c.urls[%s]", strconv.Itoa(i))); err != nil {
+			return nil, err
+		}
 		structGenerator.AddMethod(method)
 	}
 
 	return structGenerator, nil
 }
 
-func (a *API) generateServer(fileDescriptor *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, goFile *types.FileGenerator) (*types.FileGenerator, error) {
+// generateInterceptedClientCall calls doRequest with the given target
+// arguments, wrapped by the interceptors of the client.
+func generateInterceptedClientCall(method *types.GoBlockGenerator, inputType, outputType, doRequest string, target ...string) error {
+	call, err := types.NewAnonymousGoFunc("call", []*types.Parameter{
+		{
+			NameOfParameter: "ctx",
+			Typ:             types.NewUnsafeTypeReference("context.Context"),
+		},
+		{
+			NameOfParameter: "req",
+			Typ:             types.NewUnsafeTypeReference("interface{}"),
+		},
+	}, []types.TypeReference{
+		types.NewUnsafeTypeReference("interface{}"),
+		types.NewUnsafeTypeReference("error"),
+	})
+	if err != nil {
+		return err
+	}
+
+	params := append([]string{"ctx"}, target...)
+	params = append(params, fmt.Sprintf("req.(*%s)", inputType), "out")
+	call.DefNew("out", types.NewUnsafeTypeReference(outputType))
+	call.DefAssginCall([]string{"err"}, types.NewUnsafeTypeReference(doRequest), params)
+	call.Return([]string{"out", "err"})
+
+	if err := method.AnonymousGoFunc(call); err != nil {
+		return err
+	}
+	method.DefAssginCall([]string{"resp", "err"}, types.NewUnsafeTypeReference("c.options.Intercept(call)"), []string{"ctx", "in"})
+	method.DefAssert([]string{"out", "_"}, "resp", types.NewUnsafeTypeReference("*"+outputType))
+	method.Return([]string{"out", "err"})
+	return nil
+}
+
+func (a *API) generateServer(fileDescriptor *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, goFile *types.FileGenerator, index int) (*types.FileGenerator, error) {
 	// Server implementation.
 	structGenerator, err := types.NewGoStruct(serviceStruct(service), true, false)
 	if err != nil {
 		return nil, err
 	}
 
-	structGenerator.StructMetaData.Comment = append(structGenerator.StructMetaData.Comment, fmt.Sprintf("%s wraps an endpoint and implements http.Handler.", serviceStruct(service)))
+	comment := withDoc(fmt.Sprintf("%s wraps an endpoint and implements http.Handler.", serviceStruct(service)), a.serviceDoc(fileDescriptor, service))
+	structGenerator.StructMetaData.Comment = append(structGenerator.StructMetaData.Comment, structGenerator.PrepareComment(comment)...)
 
 	structGenerator.Type(types.NewUnsafeTypeReference(serviceName(service)), "")
 	structGenerator.AddUnexportedField("hooks", types.NewUnsafeTypeReference("*hooks.ServerHooks"), "")
 	structGenerator.AddUnexportedField("logErrorFunc", types.NewUnsafeTypeReference("transport.LogErrorFunc"), "")
+	structGenerator.AddUnexportedField("options", types.NewUnsafeTypeReference("*transport.ServerOptions"), "")
+	structGenerator.AddUnexportedField("prefix", types.String, "")
 
-	goFile, err = a.generateServerConstructor(serviceName(service), structGenerator.StructMetaData.Name, goFile)
+	goFile, err = a.generateServerConstructor(fileDescriptor, service, structGenerator.StructMetaData.Name, goFile)
 	if err != nil {
 		return nil, err
 	}
@@ -510,13 +755,13 @@ func (a *API) generateServer(fileDescriptor *descriptor.FileDescriptorProto, ser
 
 	// Methods.
 	for _, method := range service.Method {
-		structGenerator, err = a.generateServerMethod(service, method, structGenerator)
+		structGenerator, err = a.generateServerMethod(fileDescriptor, service, method, structGenerator)
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	structGenerator, err = a.generateServiceMetadataAccessors(fileDescriptor, service, structGenerator)
+	structGenerator, err = a.generateServiceMetadataAccessors(fileDescriptor, index, structGenerator)
 	if err != nil {
 		return nil, err
 	}
@@ -528,11 +773,14 @@ func (a *API) generateServer(fileDescriptor *descriptor.FileDescriptorProto, ser
 	return goFile, nil
 }
 
-func (a *API) generateServerConstructor(serverName, serverStructName string, goFile *types.FileGenerator) (*types.FileGenerator, error) {
+func (a *API) generateServerConstructor(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, serverStructName string, goFile *types.FileGenerator) (*types.FileGenerator, error) {
 
+	serverName := serviceName(service)
 	constructorName := fmt.Sprintf("New%sServer", serverName)
+	constructorWithOptionsName := constructorName + "WithOptions"
 
-	comment := fmt.Sprintf("%s constructs a new server, and implements %s", constructorName, serverName)
+	doc := a.serviceDoc(file, service)
+	comment := withDoc(fmt.Sprintf("%s constructs a new server, and implements %s", constructorName, serverName), doc)
 	f, err := types.NewGoFunc(constructorName, []*types.Parameter{
 		{
 			NameOfParameter: "svc",
@@ -554,25 +802,52 @@ func (a *API) generateServerConstructor(serverName, serverStructName string, goF
 		return nil, err
 	}
 
+	f.DefAssginCall([]string{"opts"}, types.NewUnsafeTypeReference("make"), []string{"[]transport.ServerOption", "0", "2"})
+	f.DefAppend("opts", []string{"opts", "transport.WithServerHooks(hooks)"})
+	f.DefIfBegin("len(errorFunc)", token.EQL, "1")
+	f.DefAppend("opts", []string{"opts", "transport.WithServerLogger(errorFunc[0])"})
+	f.CloseIf()
+	f.Return([]string{constructorWithOptionsName + "(svc, opts...)"})
+
+	if err := goFile.Func(f); err != nil {
+		return nil, err
+	}
+
+	comment = withDoc(fmt.Sprintf("%s constructs a new server configured by opts, and implements %s", constructorWithOptionsName, serverName), doc)
+	f, err = types.NewGoFunc(constructorWithOptionsName, []*types.Parameter{
+		{
+			NameOfParameter: "svc",
+			Typ:             types.NewUnsafeTypeReference(serverName),
+		},
+		{
+			NameOfParameter: "opts",
+			Typ:             types.NewUnsafeTypeReference("...transport.ServerOption"),
+		},
+	},
+		[]types.TypeReference{
+			types.NewUnsafeTypeReference("server.Server"),
+		}, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	f.DefAssginCall([]string{"options"}, types.NewUnsafeTypeReference("transport.NewServerOptions"), []string{"opts..."})
+
 	initStructGenerator, err := types.NewInitGoStruct(serverStructName)
 	if err != nil {
 		return nil, err
 	}
 
 	initStructGenerator.AddExportedValueToField(serverName, "svc")
-	initStructGenerator.AddUnexportedValueToField("hooks", "hooks")
+	initStructGenerator.AddUnexportedValueToField("hooks", "options.Hooks()")
+	initStructGenerator.AddUnexportedValueToField("logErrorFunc", "options.Logger()")
+	initStructGenerator.AddUnexportedValueToField("options", "options")
+	initStructGenerator.AddUnexportedValueToField("prefix", fmt.Sprintf("options.ServicePathPrefix(%sPathPrefix, %s)", serverName, strconv.Quote(fullServiceName(file, service))))
 
-	if err := f.InitStruct("server :=", initStructGenerator, true); err != nil {
+	if err := f.InitStruct("return", initStructGenerator, true); err != nil {
 		return nil, err
 	}
 
-	f.DefIfBegin("len(errorFunc)", token.EQL, "1")
-	f.StructAssignment("server", "logErrorFunc", "errorFunc[0]")
-	f.Else()
-	f.StructAssignment("server", "logErrorFunc", "log.Printf")
-	f.CloseIf()
-	f.Return([]string{"server"})
-
 	if err := goFile.Func(f); err != nil {
 		return nil, err
 	}
@@ -600,7 +875,7 @@ func (a *API) generateServerWriteError(structGenerator *types.StructGenerator) (
 		return nil, err
 	}
 	// todo error handling
-	method.Caller(types.NewUnsafeTypeReference("transport.WriteErrorAndTriggerHooks"), []string{"ctx", "resp", "err", "s.hooks"})
+	method.Caller(types.NewUnsafeTypeReference("s.options.WriteError"), []string{"ctx", "resp", "err", "s.hooks"})
 	structGenerator.AddMethod(method)
 	return structGenerator, nil
 }
@@ -615,8 +890,14 @@ func (a *API) generateServerRouting(file *descriptor.FileDescriptorProto, servic
 	commentGenerator.Pf("%s is used for all URL paths on a %s server.", pathPrefixConst, servName)
 	commentGenerator.Pf("Requests are always: POST %s /method", pathPrefixConst)
 	commentGenerator.P("It can be used in an HTTP mux to route requests")
+	if doc := a.serviceDoc(file, service); doc != "" {
+		commentGenerator.P("")
+		for _, line := range strings.Split(doc, "\n") {
+			commentGenerator.P(strings.TrimPrefix(line, " "))
+		}
+	}
 
-	constGenerator, err := types.NewGoConst(pathPrefixConst, types.String, strconv.Quote(pathPrefix(file, service)), commentGenerator)
+	constGenerator, err := types.NewGoConst(pathPrefixConst, types.String, strconv.Quote(a.servicePathPrefix(file, service)), commentGenerator)
 	if err != nil {
 		return nil, err
 	}
@@ -641,21 +922,56 @@ func (a *API) generateServerRouting(file *descriptor.FileDescriptorProto, servic
 		return nil, err
 	}
 
+	// The wrapper tracks the status code and prevents double writes.
+	method.DefCall([]string{"resp"}, types.NewUnsafeTypeReference("transport.NewResponseWriter"), []string{"resp"})
 	method.DefAssginCall([]string{"ctx"}, types.NewUnsafeTypeReference("req.Context"), nil)
 	method.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithPackageName"), []string{"ctx", `"` + pkgName + `"`})
 	method.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithServiceName"), []string{"ctx", `"` + servName + `"`})
 	method.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithResponseWriter"), []string{"ctx", "resp"})
+	method.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithHTTPRequest"), []string{"ctx", "req"})
+	method.Caller(types.NewUnsafeTypeReference("s.options.LimitRequestBody"), []string{"req"})
+
+	// Recovers panics of hooks and of the generated code.
+	recoverWrapper, err := types.NewAnonymousGoFunc("recoverWrapper", nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	s, _ := recoverWrapper.SCallWithDefVar([]string{"r"}, types.NewUnsafeTypeReference("recover"), nil)
+	recoverWrapper.DefIfWithOwnScopeBegin(s, "r", token.NEQ, "nil")
+	recoverWrapper.Caller(types.NewUnsafeTypeReference("s.options.HandlePanic"), []string{"ctx", "resp", "r", "s.hooks"})
+	recoverWrapper.CloseIf()
+	method.AnonymousGoFunc(recoverWrapper)
+	method.Defer(types.NewUnsafeTypeReference("recoverWrapper"), nil)
+
 	method.DefLongVar("err", "error")
 	method.DefCall([]string{"ctx", "err"}, types.NewUnsafeTypeReference("transport.CallRequestReceived"), []string{"ctx", "s.hooks"})
 	method.DefIfBegin("err", token.NEQ, "nil")
 	method.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "err"})
 	method.Return()
 	method.CloseIf()
+	method.DefCall([]string{"err"}, types.NewUnsafeTypeReference("s.options.CheckVersion"), []string{"resp", "req"})
+	method.DefIfBegin("err", token.NEQ, "nil")
+	method.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "err"})
+	method.Return()
+	method.CloseIf()
+
+	hasRules, err := hasHTTPRules(service)
+	if err != nil {
+		return nil, err
+	}
+	if hasRules {
+		// RESTful routes are matched first, they may use any HTTP method.
+		s, _ := method.SCallWithDefVar([]string{"route", "values", "ok"}, types.NewUnsafeTypeReference(restRouterVarName(service)+".Match"), []string{"req"})
+		method.DefIfWithOwnScopeBegin(s, "ok", token.EQL, "true")
+		method.Caller(types.NewUnsafeTypeReference("s.serveREST"), []string{"ctx", "resp", "req", "route", "values"})
+		method.Return()
+		method.CloseIf()
+	}
 
-	method.DefIfBegin("req.Method", token.NEQ, "http.MethodPost")
-	method.DefAssginCall([]string{"msg"}, types.NewUnsafeTypeReference("fmt.Sprintf"), []string{`"unsupported method %q (only POST is allowed)"`, "req.Method"})
-	method.DefAssginCall([]string{"terr"}, types.NewUnsafeTypeReference("errors.BadRouteError"), []string{"msg", "req.Method", "req.URL.Path"})
-	method.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "terr"})
+	// The methods decide themselves, whether they can be called with GET.
+	method.DefCall([]string{"err"}, types.NewUnsafeTypeReference("s.options.CheckMethod"), []string{"req", strconv.FormatBool(hasSideEffectFreeMethods(service))})
+	method.DefIfBegin("err", token.NEQ, "nil")
+	method.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "err"})
 	method.Return()
 	method.CloseIf()
 
@@ -665,9 +981,8 @@ func (a *API) generateServerRouting(file *descriptor.FileDescriptorProto, servic
 	}
 
 	for _, method := range service.Method {
-		path := pathFor(file, service, method)
 		methName := "serve" + types.CamelCase(method.GetName())
-		caseGenerator, err := types.NewCaseGenerator(strconv.Quote(path))
+		caseGenerator, err := types.NewCaseGenerator("s.prefix + " + strconv.Quote(methodName(method)))
 		if err != nil {
 			return nil, err
 		}
@@ -692,14 +1007,22 @@ func (a *API) generateServerRouting(file *descriptor.FileDescriptorProto, servic
 	}
 
 	structGenerator.AddMethod(method)
+
+	if hasRules {
+		structGenerator, err = a.generateServerREST(file, service, structGenerator, goFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return structGenerator, nil
 }
 
-func (a *API) generateServerMethod(service *descriptor.ServiceDescriptorProto, method *descriptor.MethodDescriptorProto, structGenerator *types.StructGenerator) (*types.StructGenerator, error) {
+func (a *API) generateServerMethod(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, method *descriptor.MethodDescriptorProto, structGenerator *types.StructGenerator) (*types.StructGenerator, error) {
 	methName := types.CamelCase(method.GetName())
 	methNameServe := fmt.Sprintf("serve%s", methName)
 
-	comment := fmt.Sprintf("%s is used to set an decoder and encoder for a given content type", methNameServe)
+	comment := withDoc(fmt.Sprintf("%s is used to set an decoder and encoder for a given content type", methNameServe), a.methodDoc(file, service, method))
 	dispatcherMethod, err := types.NewGoMethod("s", fmt.Sprintf("*%s", structGenerator.StructMetaData.Name), methNameServe, []*types.Parameter{
 		{
 			NameOfParameter: "ctx",
@@ -719,30 +1042,42 @@ func (a *API) generateServerMethod(service *descriptor.ServiceDescriptorProto, m
 		return nil, err
 	}
 
-	dispatcherMethod.DefAssginCall([]string{"header"}, types.NewUnsafeTypeReference("req.Header.Get"), []string{"xhttp.ContentTypeHeader"})
-	dispatcherMethod.DefAssginCall([]string{"i"}, types.NewUnsafeTypeReference("strings.Index"), []string{"header", `";"`})
-	dispatcherMethod.DefIfBegin("i", token.EQL, "-1")
-	dispatcherMethod.DefCall([]string{"i"}, types.NewUnsafeTypeReference("len"), []string{"header"})
-	dispatcherMethod.CloseIf()
-
-	dispatcherMethod.DefAssginCall([]string{"modifiedHeader"}, types.NewUnsafeTypeReference("strings.ToLower"), []string{"header[:i]"})
-	dispatcherMethod.DefCall([]string{"modifiedHeader"}, types.NewUnsafeTypeReference("strings.TrimSpace"), []string{"modifiedHeader"})
-
-	dispatcherMethod.DefIfBegin("modifiedHeader", token.EQL, `xhttp.ApplicationJson`)
-	dispatcherMethod.Caller(types.NewUnsafeTypeReference(fmt.Sprintf("s.serve%sContent", methName)), []string{"ctx", "resp", "req", "transport.DecodeJSONRequest", "transport.EncodeJSONResponse"})
+	if sideEffectFree(method) {
+		dispatcherMethod.DefIfBegin("req.Method", token.EQL, "http.MethodGet")
+		dispatcherMethod.DefAssginCall([]string{"codec", "err"}, types.NewUnsafeTypeReference("s.options.ResponseCodec"), []string{"req", "nil"})
+		dispatcherMethod.DefIfBegin("err", token.NEQ, "nil")
+		dispatcherMethod.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "err"})
+		dispatcherMethod.Return(nil)
+		dispatcherMethod.CloseIf()
+		dispatcherMethod.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithResponseContentType"), []string{"ctx", "codec.ContentType()"})
+		dispatcherMethod.Caller(types.NewUnsafeTypeReference(fmt.Sprintf("s.serve%sContent", methName)), []string{"ctx", "resp", "req", "transport.DecodeQueryRequest", "transport.NewGetResponseEncoderWith(req, codec)"})
+		dispatcherMethod.Return(nil)
+		dispatcherMethod.CloseIf()
+	} else if hasSideEffectFreeMethods(service) {
+		dispatcherMethod.DefIfBegin("req.Method", token.NEQ, "http.MethodPost")
+		dispatcherMethod.DefAssginCall([]string{"msg"}, types.NewUnsafeTypeReference("fmt.Sprintf"), []string{`"unsupported method %q (only POST is allowed)"`, "req.Method"})
+		dispatcherMethod.DefAssginCall([]string{"terr"}, types.NewUnsafeTypeReference("errors.BadRouteError"), []string{"msg", "req.Method", "req.URL.Path"})
+		dispatcherMethod.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "terr"})
+		dispatcherMethod.Return(nil)
+		dispatcherMethod.CloseIf()
+	}
+
+	dispatcherMethod.DefAssginCall([]string{"codec", "err"}, types.NewUnsafeTypeReference("s.options.RequestCodec"), []string{"req"})
+	dispatcherMethod.DefIfBegin("err", token.NEQ, "nil")
+	dispatcherMethod.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "err"})
 	dispatcherMethod.Return(nil)
-	dispatcherMethod.DefElseIf("modifiedHeader", token.EQL, `xhttp.ApplicationProtobuf`)
-	dispatcherMethod.Caller(types.NewUnsafeTypeReference(fmt.Sprintf("s.serve%sContent", methName)), []string{"ctx", "resp", "req", "transport.DecodePROTORequest", "transport.EncodePROTOResponse"})
+	dispatcherMethod.CloseIf()
+	dispatcherMethod.DefAssginCall([]string{"respCodec", "err"}, types.NewUnsafeTypeReference("s.options.ResponseCodec"), []string{"req", "codec"})
+	dispatcherMethod.DefIfBegin("err", token.NEQ, "nil")
+	dispatcherMethod.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "err"})
 	dispatcherMethod.Return(nil)
-	dispatcherMethod.Else()
-	dispatcherMethod.DefAssginCall([]string{"msg"}, types.NewUnsafeTypeReference("fmt.Sprintf"), []string{`"unexpected Content-Type: %q"`, "header"})
-	dispatcherMethod.DefAssginCall([]string{"terr"}, types.NewUnsafeTypeReference("errors.BadRouteError"), []string{"msg", "req.Method", "req.URL.Path"})
-	dispatcherMethod.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "terr"})
 	dispatcherMethod.CloseIf()
+	dispatcherMethod.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithResponseContentType"), []string{"ctx", "respCodec.ContentType()"})
+	dispatcherMethod.Caller(types.NewUnsafeTypeReference(fmt.Sprintf("s.serve%sContent", methName)), []string{"ctx", "resp", "req", "transport.DecodeRequestWith(codec)", "transport.EncodeResponseWith(respCodec)"})
 
 	structGenerator.AddMethod(dispatcherMethod)
 
-	structGenerator, err = a.generateServerServeMethod(service, method, structGenerator)
+	structGenerator, err = a.generateServerServeMethod(file, service, method, structGenerator)
 	if err != nil {
 		return nil, err
 	}
@@ -750,11 +1085,11 @@ func (a *API) generateServerMethod(service *descriptor.ServiceDescriptorProto, m
 	return structGenerator, nil
 }
 
-func (a *API) generateServerServeMethod(service *descriptor.ServiceDescriptorProto, method *descriptor.MethodDescriptorProto, structGenerator *types.StructGenerator) (*types.StructGenerator, error) {
+func (a *API) generateServerServeMethod(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, method *descriptor.MethodDescriptorProto, structGenerator *types.StructGenerator) (*types.StructGenerator, error) {
 	methName := types.CamelCase(method.GetName())
 	methServe := fmt.Sprintf("serve%sContent", methName)
 
-	comment := fmt.Sprintf("%s sends object to requester", methServe)
+	comment := withDoc(fmt.Sprintf("%s sends object to requester", methServe), a.methodDoc(file, service, method))
 	serveMethod, err := types.NewGoMethod("s", fmt.Sprintf("*%s", structGenerator.StructMetaData.Name), fmt.Sprintf("serve%sContent", methName), []*types.Parameter{
 		{
 			NameOfParameter: "ctx",
@@ -811,18 +1146,39 @@ func (a *API) generateServerServeMethod(service *descriptor.ServiceDescriptorPro
 	serveMethod.Return()
 	serveMethod.CloseIf()
 
-	responseCallWrapper, _ := types.NewAnonymousGoFunc("endpointWrapper", nil, []types.TypeReference{types.NewUnsafeTypeReference(fmt.Sprintf("*%s", outputType)), types.NewUnsafeTypeReference("error")})
+	// A recovered panic is returned as error by the named result err.
+	responseCallWrapper, _ := types.NewAnonymousGoFunc("endpointWrapper", nil, []types.TypeReference{types.NewUnsafeTypeReference(fmt.Sprintf("respContent *%s", outputType)), types.NewUnsafeTypeReference("err error")})
 	responseDeferWrapper, _ := types.NewAnonymousGoFunc("deferWrapper", nil, nil)
 
 	s, _ = responseDeferWrapper.SCallWithDefVar([]string{"r"}, types.NewUnsafeTypeReference("recover"), nil)
 	responseDeferWrapper.DefIfWithOwnScopeBegin(s, "r", token.NEQ, "nil")
-	responseDeferWrapper.DefAssginCall([]string{"terr"}, types.NewUnsafeTypeReference("errors.InternalError"), []string{`"Internal service panic"`})
-	responseDeferWrapper.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "terr"})
-	responseDeferWrapper.Caller(types.NewUnsafeTypeReference("panic"), []string{"r"})
+	responseDeferWrapper.DefCall([]string{"err"}, types.NewUnsafeTypeReference("s.options.RecoverPanic"), []string{"ctx", "r"})
 	responseDeferWrapper.CloseIf()
 	responseCallWrapper.AnonymousGoFunc(responseDeferWrapper)
 	responseCallWrapper.Defer(types.NewUnsafeTypeReference("deferWrapper"), nil)
-	responseCallWrapper.ReturnCaller(types.NewUnsafeTypeReference(fmt.Sprintf("s.%s", methName)), []string{"ctx", "reqContent"})
+
+	call, err := types.NewAnonymousGoFunc("call", []*types.Parameter{
+		{
+			NameOfParameter: "ctx",
+			Typ:             types.NewUnsafeTypeReference("context.Context"),
+		},
+		{
+			NameOfParameter: "req",
+			Typ:             types.NewUnsafeTypeReference("interface{}"),
+		},
+	}, []types.TypeReference{
+		types.NewUnsafeTypeReference("interface{}"),
+		types.NewUnsafeTypeReference("error"),
+	})
+	if err != nil {
+		return nil, err
+	}
+	call.DefAssginCall([]string{"out", "err"}, types.NewUnsafeTypeReference(fmt.Sprintf("s.%s", methName)), []string{"ctx", fmt.Sprintf("req.(*%s)", inputType)})
+	call.Return([]string{"out", "err"})
+	responseCallWrapper.AnonymousGoFunc(call)
+	responseCallWrapper.DefAssginCall([]string{"out", "callErr"}, types.NewUnsafeTypeReference("s.options.Intercept(call)"), []string{"ctx", "reqContent"})
+	responseCallWrapper.DefAssert([]string{"content", "_"}, "out", types.NewUnsafeTypeReference("*"+outputType))
+	responseCallWrapper.Return([]string{"content", "callErr"})
 	serveMethod.AnonymousGoFunc(responseCallWrapper)
 	serveMethod.DefAssginCall([]string{"respContent", "err"}, types.NewUnsafeTypeReference("endpointWrapper"), nil)
 	serveMethod.DefIfBegin("err", token.NEQ, "nil")
@@ -853,14 +1209,10 @@ func (a *API) generateServerServeMethod(service *descriptor.ServiceDescriptorPro
 	return structGenerator, nil
 }
 
-func (a *API) generateServiceMetadataAccessors(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, structGenerator *types.StructGenerator) (*types.StructGenerator, error) {
-	index := 0
-	for i, s := range file.Service {
-		if s.GetName() == service.GetName() {
-			index = i
-		}
-	}
-
+// generateServiceMetadataAccessors generates the accessors of the descriptor
+// of the file and of the version of the generator, index is the index of the
+// service in the file.
+func (a *API) generateServiceMetadataAccessors(file *descriptor.FileDescriptorProto, index int, structGenerator *types.StructGenerator) (*types.StructGenerator, error) {
 	structName := structGenerator.StructMetaData.Name
 
 	comment := "ServiceDescriptor describes an service."
@@ -874,7 +1226,7 @@ func (a *API) generateServiceMetadataAccessors(file *descriptor.FileDescriptorPr
 		return nil, err
 	}
 
-	serviceDescriptorMethod.Return([]string{a.serviceMetadataVarName(), strconv.Itoa(index)})
+	serviceDescriptorMethod.Return([]string{serviceMetadataVarName(file), strconv.Itoa(index)})
 	structGenerator.AddMethod(serviceDescriptorMethod)
 
 	comment = "ProtocGenXServiceVersion returns which xservice version was used to generate that service"
@@ -912,7 +1264,7 @@ func (a *API) generateFileDescriptor(file *descriptor.FileDescriptorProto, goFil
 	w.Close()
 	b = descriptorProto.Bytes()
 
-	v := a.serviceMetadataVarName()
+	v := serviceMetadataVarName(file)
 
 	buff := new(bytes.Buffer)
 
@@ -936,20 +1288,101 @@ func (a *API) generateFileDescriptor(file *descriptor.FileDescriptorProto, goFil
 
 	goFile.Var(buff.String())
 
+	// Register the services, so their descriptors can be looked up by name,
+	// and the generated messages.
+	f, err := types.NewGoFunc("init", nil, nil, "")
+	if err != nil {
+		return nil, err
+	}
+	for i, service := range file.Service {
+		f.Caller(types.NewUnsafeTypeReference("server.RegisterServiceDescriptor"), []string{strconv.Quote(fullServiceName(file, service)), v, strconv.Itoa(i)})
+	}
+	if a.genMessages {
+		for _, line := range a.messageRegistrations(file) {
+			f.Command(line, nil)
+		}
+	}
+	if len(f.MetaData.Lines) > 0 {
+		if err := goFile.Func(f); err != nil {
+			return nil, err
+		}
+	}
+
 	return goFile, nil
 }
 
-// pathPrefix returns the base path for all methods handled by a particular
-// service. It includes a trailing slash. (for example
-// "/xservice/example.Haberdasher/").
-func pathPrefix(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto) string {
-	return fmt.Sprintf("/xservice/%s/", fullServiceName(file, service))
+// generatePolicy generates the auth.Policy of a service, if the service or
+// any of its methods uses the authorization options of
+// proto/xservice/options.proto.
+func (a *API) generatePolicy(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, goFile *types.FileGenerator) (*types.FileGenerator, error) {
+
+	serviceScopes, err := xprotoutil.StringExtension(service.GetOptions(), options.ServiceScopesField)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read options of service %s", service.GetName())
+	}
+	serviceRoles, err := xprotoutil.StringExtension(service.GetOptions(), options.ServiceRolesField)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read options of service %s", service.GetName())
+	}
+
+	restricted := len(serviceScopes) > 0 || len(serviceRoles) > 0
+
+	buff := new(bytes.Buffer)
+	for _, method := range service.Method {
+		scopes, err := xprotoutil.StringExtension(method.GetOptions(), options.ScopesField)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read options of method %s.%s", service.GetName(), method.GetName())
+		}
+		roles, err := xprotoutil.StringExtension(method.GetOptions(), options.RolesField)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not read options of method %s.%s", service.GetName(), method.GetName())
+		}
+
+		scopes = append(append([]string{}, serviceScopes...), scopes...)
+		if len(roles) == 0 {
+			roles = serviceRoles
+		}
+		restricted = restricted || len(scopes) > 0 || len(roles) > 0
+
+		buff.WriteString(fmt.Sprintf("%s: {Scopes: %s, Roles: %s},\n", strconv.Quote(methodName(method)), stringSliceLiteral(scopes), stringSliceLiteral(roles)))
+	}
+
+	if !restricted {
+		return goFile, nil
+	}
+
+	goFile.Import("", "github.com/donutloop/xservice/framework/auth")
+
+	v := serviceName(service) + "Policy"
+	goFile.Var(fmt.Sprintf(`// %s lists the scopes and roles required by the methods of %s.
+// Enforce it with auth.NewPolicyHooks.
+var %s = &auth.Policy{
+	Package: %s,
+	Service: %s,
+	Methods: map[string]auth.Requirement{
+		%s
+	},
+}`, v, serviceName(service), v, strconv.Quote(pkgName(file)), strconv.Quote(serviceName(service)), buff.String()))
+
+	return goFile, nil
 }
 
-// pathFor returns the complete path for requests to a particular method on a
-// particular service.
-func pathFor(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, method *descriptor.MethodDescriptorProto) string {
-	return pathPrefix(file, service) + types.CamelCase(method.GetName())
+func stringSliceLiteral(values []string) string {
+	if len(values) == 0 {
+		return "nil"
+	}
+	quoted := make([]string, 0, len(values))
+	for _, v := range values {
+		quoted = append(quoted, strconv.Quote(v))
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+// servicePathPrefix returns the base path for all methods handled by a
+// particular service. It includes a trailing slash. (for example
+// "/xservice/example.Haberdasher/").
+func (a *API) servicePathPrefix(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto) string {
+	return transport.ServicePathPrefix(a.pathPrefix, fullServiceName(file, service))
 }
 
 // Given a protobuf name for a Message, return the Go name we will use for that
@@ -965,18 +1398,49 @@ func (a *API) goTypeName(protoName string) (string, error) {
 		prefix = pkg + "."
 	}
 
-	var name string
-	for _, parent := range def.Lineage() {
-		name += parent.Descriptor.GetName() + "_"
-	}
-	name += def.Descriptor.GetName()
-	return prefix + name, nil
+	return prefix + def.GoName(), nil
 }
 
 func (a *API) goPackageName(file *descriptor.FileDescriptorProto) string {
 	return a.fileToGoPackageName[file]
 }
 
+// reservedPkgNames are the names of packages imported by generated code.
+var reservedPkgNames = []string{
+	"auth", "context", "errors", "fmt", "hooks", "http", "math", "proto", "rest", "server", "strings", "transport", "xcontext", "xhttp",
+}
+
+// goImportPath returns the Go import path of a file given by a M parameter
+// or its go_package option, or "" if it is unknown.
+func (a *API) goImportPath(file *descriptor.FileDescriptorProto) string {
+	if importPath, ok := a.importMap[file.GetName()]; ok {
+		return importPath
+	}
+	importPath, _, _ := xprotoutil.GoPackageOption(file)
+	return importPath
+}
+
+// goPackageNameOf returns the Go package name of a file imported from
+// importPath: the name of its go_package option, or the last element of
+// the import path.
+func goPackageNameOf(file *descriptor.FileDescriptorProto, importPath string) string {
+	if impPath, pkg, ok := xprotoutil.GoPackageOption(file); ok && impPath == importPath {
+		return types.Identifier(pkg)
+	}
+	return types.Identifier(path.Base(importPath))
+}
+
+// uniquePkgName returns name, or name with the smallest number suffix which
+// isn't in use yet, and marks it as used.
+func (a *API) uniquePkgName(name string) string {
+	unique := name
+	for i := 1; a.pkgNamesInUse[unique]; i++ {
+		unique = name + strconv.Itoa(i)
+	}
+	a.pkgNamesInUse[unique] = true
+	return unique
+}
+
 func unexported(s string) string { return strings.ToLower(s[:1]) + s[1:] }
 
 func fullServiceName(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto) string {
@@ -995,6 +1459,21 @@ func serviceName(service *descriptor.ServiceDescriptorProto) string {
 	return types.CamelCase(service.GetName())
 }
 
+// sideEffectFree reports whether a method is marked with
+// idempotency_level = NO_SIDE_EFFECTS, which allows to call it with GET.
+func sideEffectFree(method *descriptor.MethodDescriptorProto) bool {
+	return method.GetOptions().GetIdempotencyLevel() == descriptor.MethodOptions_NO_SIDE_EFFECTS
+}
+
+func hasSideEffectFreeMethods(service *descriptor.ServiceDescriptorProto) bool {
+	for _, method := range service.Method {
+		if sideEffectFree(method) {
+			return true
+		}
+	}
+	return false
+}
+
 func serviceStruct(service *descriptor.ServiceDescriptorProto) string {
 	return unexported(serviceName(service)) + "Server"
 }
@@ -1014,8 +1493,8 @@ func fileDescSliceContains(slice []*descriptor.FileDescriptorProto, f *descripto
 
 var EmptyComment error = errors.New("comment is empty")
 
-func prepareComment(comments typemap.DefinitionComments) (string, error) {
-	text := strings.TrimSuffix(comments.Leading, "\n")
+// prepareComment renders the lines of text as a comment.
+func prepareComment(text string) (string, error) {
 	if len(strings.TrimSpace(text)) == 0 {
 		return "", EmptyComment
 	}
@@ -1033,6 +1512,54 @@ func prepareComment(comments typemap.DefinitionComments) (string, error) {
 	return commentRendered, nil
 }
 
+// deprecationComment is the paragraph of the comments of deprecated
+// services and methods, which is recognized by linters.
+const deprecationComment = "Deprecated: Do not use."
+
+// serviceDoc returns the documentation of a service for the comments of
+// generated code: its comments in the proto file and a deprecation notice.
+func (a *API) serviceDoc(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto) string {
+	comments, err := a.reg.ServiceComments(file, service)
+	if err != nil {
+		return ""
+	}
+	return protoDoc(comments, service.GetOptions().GetDeprecated())
+}
+
+// methodDoc returns the documentation of a method like serviceDoc.
+func (a *API) methodDoc(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, method *descriptor.MethodDescriptorProto) string {
+	comments, err := a.reg.MethodComments(file, service, method)
+	if err != nil {
+		return ""
+	}
+	return protoDoc(comments, method.GetOptions().GetDeprecated())
+}
+
+// protoDoc joins the detached, leading and trailing comments of a definition
+// as paragraphs, followed by the deprecation notice of deprecated
+// definitions.
+func protoDoc(comments typemap.DefinitionComments, deprecated bool) string {
+	var paragraphs []string
+	for _, comment := range append(append([]string{}, comments.LeadingDetached...), comments.Leading, comments.Trailing) {
+		if comment = strings.TrimSpace(comment); comment != "" {
+			paragraphs = append(paragraphs, comment)
+		}
+	}
+	if deprecated {
+		paragraphs = append(paragraphs, deprecationComment)
+	}
+	return strings.Join(paragraphs, "\n\n")
+}
+
+// withDoc appends the documentation of a service or method to the comment
+// of generated code.
+func withDoc(comment, doc string) string {
+	if doc == "" {
+		return comment
+	}
+	return comment + "\n\n" + doc
+}
+
 // serviceMetadataVarName is the variable name used in generated code to refer
 // to the compressed bytes of this descriptor. It is not exported, so it is only
 // valid inside the generated package.
@@ -1041,8 +1568,13 @@ func prepareComment(comments typemap.DefinitionComments) (string, error) {
 // protoc-gen-gogo - with a different name! Twirp aims to be compatible with
 // both; the simplest way forward is to write the file descriptor again as
 // another variable that we control.
-func (a *API) serviceMetadataVarName() string {
-	return fmt.Sprintf("xserviceFileDescriptor%d", a.filesHandled)
+//
+// Like protoc-gen-go, the name is derived from the hash of the file name, so
+// it doesn't depend on the order of files and doesn't clash with files of
+// the same package generated separately.
+func serviceMetadataVarName(file *descriptor.FileDescriptorProto) string {
+	h := sha256.Sum256([]byte(file.GetName()))
+	return fmt.Sprintf("xserviceFileDescriptor_%s", hex.EncodeToString(h[:8]))
 }
 
 // deduceGenPkgName figures out the go package name to use for generated code.
@@ -0,0 +1,231 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package goproto
+
+import (
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// goldenImportPath is the import path of the packages generated from
+// testdata, see the go_package options of its protos.
+const goldenImportPath = "github.com/donutloop/xservice/generator/proto/go/testdata"
+
+// goldenGeneration is a request of a golden test, it generates a package.
+type goldenGeneration struct {
+	files     []string
+	parameter string
+}
+
+// goldenTests generate the protos of testdata/<name> from the descriptor set
+// testdata/<name>/fileset.pb. The generated files are compared with the
+// golden files testdata/<name>/<file>.golden, errors with
+// testdata/<name>/<proto file>.error.golden.
+var goldenTests = []struct {
+	name        string
+	generations []goldenGeneration
+}{
+	{
+		// Multiple services, an empty service, nested messages, comments
+		// and the scaffold of a server, as generated with -descriptor_set.
+		name: "services",
+		generations: []goldenGeneration{
+			{files: []string{"services.proto"}, parameter: "messages=true,scaffold=true"},
+		},
+	},
+	{
+		// Requests and responses of other packages, also of a package named
+		// like a package imported by generated code.
+		name: "imports",
+		generations: []goldenGeneration{
+			{files: []string{"dep/dep.proto"}, parameter: "messages=true"},
+			{files: []string{"proto/tag.proto"}, parameter: "messages=true"},
+			{files: []string{"imports.proto"}, parameter: "messages=true,Mgoogle/protobuf/empty.proto=github.com/gogo/protobuf/types"},
+		},
+	},
+	{
+		// The authorization policies of the xservice options.
+		name: "policy",
+		generations: []goldenGeneration{
+			{files: []string{"policy.proto"}, parameter: "messages=true"},
+		},
+	},
+	{
+		// The RESTful routes of google.api.http annotations: path variables,
+		// bodies of a field and of the whole message, additional bindings
+		// and a method without annotation.
+		name: "rest",
+		generations: []goldenGeneration{
+			{files: []string{"rest.proto"}, parameter: "messages=true"},
+		},
+	},
+	{
+		// Streaming methods aren't supported.
+		name: "streaming",
+		generations: []goldenGeneration{
+			{files: []string{"streaming.proto"}, parameter: "messages=true"},
+		},
+	},
+}
+
+func TestGolden(t *testing.T) {
+	fset := token.NewFileSet()
+	imp := &goldenImporter{
+		fset:     fset,
+		files:    make(map[string][]*ast.File),
+		checked:  make(map[string]*types.Package),
+		fallback: importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+	}
+
+	for _, test := range goldenTests {
+		dir := filepath.Join("testdata", test.name)
+		set := loadDescriptorSet(t, filepath.Join(dir, "fileset.pb"))
+
+		for _, gen := range test.generations {
+			req := &plugin.CodeGeneratorRequest{
+				FileToGenerate: gen.files,
+				Parameter:      proto.String(gen.parameter),
+				ProtoFile:      set.File,
+			}
+			resp, err := NewAPIGenerator().Generate(req)
+			if err != nil {
+				compareGolden(t, filepath.Join(dir, gen.files[0]+".error.golden"), []byte(err.Error()+"\n"))
+				continue
+			}
+
+			for _, f := range resp.File {
+				golden := filepath.Join(dir, filepath.FromSlash(f.GetName())+".golden")
+				compareGolden(t, golden, []byte(f.GetContent()))
+
+				file, err := parser.ParseFile(fset, golden, f.GetContent(), parser.ParseComments)
+				if err != nil {
+					t.Errorf("%s: could not parse generated code: %v", golden, err)
+					continue
+				}
+				importPath := path.Join(goldenImportPath, test.name, path.Dir(f.GetName()))
+				imp.files[importPath] = append(imp.files[importPath], file)
+			}
+		}
+	}
+
+	// The generated packages must type-check, including the imports of
+	// generated packages of each other.
+	for importPath := range imp.files {
+		if _, err := imp.Import(importPath); err != nil {
+			t.Errorf("%s does not type-check: %v", importPath, err)
+		}
+	}
+}
+
+func loadDescriptorSet(t *testing.T, name string) *descriptor.FileDescriptorSet {
+	data, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Fatalf("could not read descriptor set: %v", err)
+	}
+
+	set := new(descriptor.FileDescriptorSet)
+	if err := proto.Unmarshal(data, set); err != nil {
+		t.Fatalf("could not unmarshal descriptor set %s: %v", name, err)
+	}
+	return set
+}
+
+// compareGolden compares got with the golden file name, or updates it with
+// the -update flag.
+func compareGolden(t *testing.T, name string, got []byte) {
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := ioutil.ReadFile(name)
+	if err != nil {
+		t.Errorf("could not read golden file (run go test -update to create it): %v", err)
+		return
+	}
+	if string(got) != string(want) {
+		t.Errorf("%s differs from the generated code (run go test -update to update it):\n%s", name, lineDiff(string(want), string(got)))
+	}
+}
+
+// lineDiff returns the first line of want and got which differs.
+func lineDiff(want, got string) string {
+	wantLines, gotLines := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < len(wantLines) || i < len(gotLines); i++ {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w != g {
+			return "line " + strconv.Itoa(i+1) + ":\n-" + w + "\n+" + g
+		}
+	}
+	return ""
+}
+
+// goldenImporter type-checks the generated packages of the golden tests and
+// imports all other packages from source.
+type goldenImporter struct {
+	fset     *token.FileSet
+	files    map[string][]*ast.File
+	checked  map[string]*types.Package
+	fallback types.ImporterFrom
+}
+
+func (imp *goldenImporter) Import(path string) (*types.Package, error) {
+	return imp.ImportFrom(path, "", 0)
+}
+
+func (imp *goldenImporter) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	files, ok := imp.files[path]
+	if !ok {
+		return imp.fallback.ImportFrom(path, dir, mode)
+	}
+	if pkg, ok := imp.checked[path]; ok {
+		return pkg, nil
+	}
+
+	conf := types.Config{Importer: imp}
+	pkg, err := conf.Check(path, imp.fset, files, nil)
+	if err != nil {
+		return nil, err
+	}
+	imp.checked[path] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package goproto
+
+import (
+	"fmt"
+	"github.com/donutloop/xservice/internal/xgenerator/types"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"strconv"
+)
+
+// generateLocalClient generates New<Service>LocalClient, a client which calls
+// an implementation of a service in-process like its server, without HTTP.
+func (a *API) generateLocalClient(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, goFile *types.FileGenerator) (*types.FileGenerator, error) {
+	servName := serviceName(service)
+	structName := unexported(servName) + "LocalClient"
+	newClientFunc := "New" + servName + "LocalClient"
+	newClientWithOptionsFunc := newClientFunc + "WithOptions"
+	doc := a.serviceDoc(file, service)
+
+	structGenerator, err := types.NewGoStruct(structName, true, false)
+	if err != nil {
+		return nil, err
+	}
+	comment := withDoc(fmt.Sprintf("%s calls an implementation of %s in-process, with the hooks and interceptors of a server", structName, servName), doc)
+	structGenerator.StructMetaData.Comment = append(structGenerator.StructMetaData.Comment, structGenerator.PrepareComment(comment)...)
+	structGenerator.AddUnexportedField("svc", types.NewUnsafeTypeReference(servName), "")
+	structGenerator.AddUnexportedField("options", types.NewUnsafeTypeReference("*transport.ServerOptions"), "")
+
+	comment = withDoc(fmt.Sprintf("%s constructs a client which implements %s by calling svc in-process. It calls the hooks and populates the context like %s.", newClientFunc, servName, "New"+servName+"Server"), doc)
+	f, err := types.NewGoFunc(newClientFunc, []*types.Parameter{
+		{
+			NameOfParameter: "svc",
+			Typ:             types.NewUnsafeTypeReference(servName),
+		},
+		{
+			NameOfParameter: "hooks",
+			Typ:             types.NewUnsafeTypeReference("*hooks.ServerHooks"),
+		},
+	}, []types.TypeReference{
+		types.NewUnsafeTypeReference(servName),
+	}, comment)
+	if err != nil {
+		return nil, err
+	}
+	f.Return([]string{newClientWithOptionsFunc + "(svc, transport.WithServerHooks(hooks))"})
+	if err := goFile.Func(f); err != nil {
+		return nil, err
+	}
+
+	comment = withDoc(fmt.Sprintf("%s constructs a client like %s, configured by server options. With transport.WithLocalRoundTrip svc receives and returns copies of the messages.", newClientWithOptionsFunc, newClientFunc), doc)
+	f, err = types.NewGoFunc(newClientWithOptionsFunc, []*types.Parameter{
+		{
+			NameOfParameter: "svc",
+			Typ:             types.NewUnsafeTypeReference(servName),
+		},
+		{
+			NameOfParameter: "opts",
+			Typ:             types.NewUnsafeTypeReference("...transport.ServerOption"),
+		},
+	}, []types.TypeReference{
+		types.NewUnsafeTypeReference(servName),
+	}, comment)
+	if err != nil {
+		return nil, err
+	}
+	initStructGenerator, err := types.NewInitGoStruct(structName)
+	if err != nil {
+		return nil, err
+	}
+	initStructGenerator.AddUnexportedValueToField("svc", "svc")
+	initStructGenerator.AddUnexportedValueToField("options", "transport.NewServerOptions(opts...)")
+	if err := f.InitStruct("return", initStructGenerator, true); err != nil {
+		return nil, err
+	}
+	if err := goFile.Func(f); err != nil {
+		return nil, err
+	}
+
+	for _, method := range service.Method {
+		methName := methodName(method)
+		inputType, err := a.goTypeName(method.GetInputType())
+		if err != nil {
+			return nil, err
+		}
+		outputType, err := a.goTypeName(method.GetOutputType())
+		if err != nil {
+			return nil, err
+		}
+
+		comment := withDoc(fmt.Sprintf("%s calls %s of the service in-process", methName, methName), a.methodDoc(file, service, method))
+		m, err := types.NewGoMethod("c", "*"+structName, methName, []*types.Parameter{
+			{
+				NameOfParameter: "ctx",
+				Typ:             types.NewUnsafeTypeReference("context.Context"),
+			},
+			{
+				NameOfParameter: "in",
+				Typ:             types.NewUnsafeTypeReference("*" + inputType),
+			},
+		}, []types.TypeReference{
+			types.NewUnsafeTypeReference("*" + outputType),
+			types.NewUnsafeTypeReference("error"),
+		}, comment)
+		if err != nil {
+			return nil, err
+		}
+
+		call, err := types.NewAnonymousGoFunc("call", []*types.Parameter{
+			{
+				NameOfParameter: "ctx",
+				Typ:             types.NewUnsafeTypeReference("context.Context"),
+			},
+			{
+				NameOfParameter: "req",
+				Typ:             types.NewUnsafeTypeReference("interface{}"),
+			},
+		}, []types.TypeReference{
+			types.NewUnsafeTypeReference("interface{}"),
+			types.NewUnsafeTypeReference("error"),
+		})
+		if err != nil {
+			return nil, err
+		}
+		call.DefAssginCall([]string{"out", "err"}, types.NewUnsafeTypeReference("c.svc."+methName), []string{"ctx", fmt.Sprintf("req.(*%s)", inputType)})
+		call.Return([]string{"out", "err"})
+		if err := m.AnonymousGoFunc(call); err != nil {
+			return nil, err
+		}
+
+		m.DefAssginCall([]string{"resp", "err"}, types.NewUnsafeTypeReference("c.options.CallLocal"), []string{"ctx", strconv.Quote(pkgName(file)), strconv.Quote(servName), strconv.Quote(methName), "in", "call"})
+		m.DefAssert([]string{"out", "_"}, "resp", types.NewUnsafeTypeReference("*"+outputType))
+		m.Return([]string{"out", "err"})
+		structGenerator.AddMethod(m)
+	}
+
+	if err := goFile.TypesWithMethods(structGenerator); err != nil {
+		return nil, err
+	}
+	return goFile, nil
+}
@@ -0,0 +1,650 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package goproto
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/donutloop/xservice/internal/xgenerator/types"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/pkg/errors"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goName joins the names of nested definitions like protoc-gen-go, e.g.
+// "Outer_Inner".
+func goName(names ...string) string {
+	return types.CamelCase(strings.Join(names, "_"))
+}
+
+// messageMethodNames are the names of methods of generated messages, fields
+// of the same name get a "_" suffix.
+var messageMethodNames = map[string]bool{
+	"Reset":          true,
+	"String":         true,
+	"ProtoMessage":   true,
+	"Descriptor":     true,
+	"Marshal":        true,
+	"Unmarshal":      true,
+	"XXX_OneofFuncs": true,
+}
+
+func fieldGoName(field *descriptor.FieldDescriptorProto) string {
+	name := types.CamelCase(field.GetName())
+	if messageMethodNames[name] {
+		name += "_"
+	}
+	return name
+}
+
+// generateMessages generates the enums and messages of a file with the
+// methods of proto.Message, so a package doesn't need protoc-gen-go or
+// protoc-gen-gogo. The gogo/protobuf runtime used by the codecs of
+// framework/transport marshals them by their struct tags, like the messages
+// of protoc-gen-go.
+func (a *API) generateMessages(file *descriptor.FileDescriptorProto, goFile *types.FileGenerator) error {
+	if file.GetSyntax() != "proto3" {
+		return errors.Errorf("%s: messages can only be generated for proto3 files", file.GetName())
+	}
+
+	goFile.Import("", "fmt")
+	goFile.Import("", "math")
+	goFile.Import("", "github.com/gogo/protobuf/proto")
+	a.importFieldTypes(file.MessageType, goFile)
+
+	for i, enum := range file.EnumType {
+		a.generateEnum(file, nil, enum, []int{i}, goFile)
+	}
+	for i, message := range file.MessageType {
+		if err := a.generateMessage(file, nil, message, []int{i}, goFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importFieldTypes imports the packages of message and enum fields of
+// other packages.
+func (a *API) importFieldTypes(messages []*descriptor.DescriptorProto, goFile *types.FileGenerator) {
+	for _, message := range messages {
+		for _, field := range message.Field {
+			var file *descriptor.FileDescriptorProto
+			switch field.GetType() {
+			case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+				if def := a.reg.MessageDefinition(field.GetTypeName()); def != nil {
+					file = def.File
+				}
+			case descriptor.FieldDescriptorProto_TYPE_ENUM:
+				if def := a.reg.EnumDefinition(field.GetTypeName()); def != nil {
+					file = def.File
+				}
+			}
+			if importPath, ok := a.fileToGoImportPath[file]; ok {
+				goFile.Import(a.goPackageName(file), importPath)
+			}
+		}
+		a.importFieldTypes(message.NestedType, goFile)
+	}
+}
+
+// generateEnum generates an enum like protoc-gen-go: an int32 type, a
+// constant for each value and maps of the names and values for
+// proto.RegisterEnum.
+func (a *API) generateEnum(file *descriptor.FileDescriptorProto, parents []string, enum *descriptor.EnumDescriptorProto, path []int, goFile *types.FileGenerator) {
+	typeName := goName(append(append([]string{}, parents...), enum.GetName())...)
+	prefix := typeName + "_"
+	if len(parents) > 0 {
+		prefix = goName(parents...) + "_"
+	}
+
+	buff := new(bytes.Buffer)
+	fmt.Fprintf(buff, "type %s int32\n\n", typeName)
+	buff.WriteString("const (\n")
+	for _, value := range enum.Value {
+		fmt.Fprintf(buff, "%s%s %s = %d\n", prefix, value.GetName(), typeName, value.GetNumber())
+	}
+	buff.WriteString(")\n\n")
+
+	fmt.Fprintf(buff, "var %s_name = map[int32]string{\n", typeName)
+	seen := make(map[int32]bool)
+	for _, value := range enum.Value {
+		// Aliases share a number, the first name is used.
+		if seen[value.GetNumber()] {
+			continue
+		}
+		seen[value.GetNumber()] = true
+		fmt.Fprintf(buff, "%d: %s,\n", value.GetNumber(), strconv.Quote(value.GetName()))
+	}
+	buff.WriteString("}\n\n")
+
+	fmt.Fprintf(buff, "var %s_value = map[string]int32{\n", typeName)
+	for _, value := range enum.Value {
+		fmt.Fprintf(buff, "%s: %d,\n", strconv.Quote(value.GetName()), value.GetNumber())
+	}
+	buff.WriteString("}\n\n")
+
+	fmt.Fprintf(buff, "func (x %s) String() string {\n\treturn proto.EnumName(%s_name, int32(x))\n}\n\n", typeName, typeName)
+	fmt.Fprintf(buff, "func (%s) EnumDescriptor() ([]byte, []int) {\n\treturn %s, %s\n}", typeName, serviceMetadataVarName(file), intSliceLiteral(path))
+
+	goFile.Var(buff.String())
+}
+
+// generateMessage generates the struct of a message, its methods and its
+// nested enums and messages. Map entries are generated as Go maps.
+func (a *API) generateMessage(file *descriptor.FileDescriptorProto, parents []string, message *descriptor.DescriptorProto, path []int, goFile *types.FileGenerator) error {
+	if message.GetOptions().GetMapEntry() {
+		return nil
+	}
+
+	lineage := append(append([]string{}, parents...), message.GetName())
+	typeName := goName(lineage...)
+	protoName := fullProtoName(file, lineage)
+
+	if len(message.Extension) > 0 || len(message.ExtensionRange) > 0 {
+		return errors.Errorf("%s: extensions are not supported", protoName)
+	}
+
+	for i, enum := range message.EnumType {
+		a.generateEnum(file, lineage, enum, append(append([]int{}, path...), i), goFile)
+	}
+	for i, nested := range message.NestedType {
+		if err := a.generateMessage(file, lineage, nested, append(append([]int{}, path...), i), goFile); err != nil {
+			return err
+		}
+	}
+
+	structGenerator, err := types.NewGoStruct(typeName, true, true)
+	if err != nil {
+		return err
+	}
+	if comment := strings.TrimSpace(a.reg.MessageDefinition(protoName).Comments.Leading); comment != "" {
+		structGenerator.StructMetaData.Comment = append(structGenerator.StructMetaData.Comment, strings.Split(comment, "\n")...)
+	}
+
+	var getters []*types.MethodGenerator
+	oneofGenerated := make(map[int32]bool)
+	for _, field := range message.Field {
+		if field.OneofIndex != nil {
+			index := field.GetOneofIndex()
+			if oneofGenerated[index] {
+				continue
+			}
+			oneofGenerated[index] = true
+
+			oneof := message.OneofDecl[index]
+			oneofName := types.CamelCase(oneof.GetName())
+			iface := "is" + typeName + "_" + oneofName
+			tag := fmt.Sprintf("`protobuf_oneof:%s`", strconv.Quote(oneof.GetName()))
+			if err := structGenerator.AddExportedFieldWithTag(oneofName, types.NewUnsafeTypeReference(iface), tag, ""); err != nil {
+				return err
+			}
+
+			getter, err := types.NewGoMethod("m", "*"+typeName, "Get"+oneofName, nil, []types.TypeReference{types.NewUnsafeTypeReference(iface)}, "")
+			if err != nil {
+				return err
+			}
+			getter.DefIfBegin("m", token.NEQ, "nil")
+			getter.Return([]string{"m." + oneofName})
+			getter.CloseIf()
+			getter.Return([]string{"nil"})
+			getters = append(getters, getter)
+			continue
+		}
+
+		goType, zero, err := a.fieldGoType(file, field)
+		if err != nil {
+			return errors.Wrapf(err, "%s.%s", protoName, field.GetName())
+		}
+		tag, err := a.fieldTag(file, field)
+		if err != nil {
+			return errors.Wrapf(err, "%s.%s", protoName, field.GetName())
+		}
+		tag = fmt.Sprintf("`%s json:%s`", tag, strconv.Quote(field.GetName()+",omitempty"))
+
+		name := fieldGoName(field)
+		if err := structGenerator.AddExportedFieldWithTag(name, types.NewUnsafeTypeReference(goType), tag, ""); err != nil {
+			return err
+		}
+
+		getter, err := types.NewGoMethod("m", "*"+typeName, "Get"+name, nil, []types.TypeReference{types.NewUnsafeTypeReference(goType)}, "")
+		if err != nil {
+			return err
+		}
+		getter.DefIfBegin("m", token.NEQ, "nil")
+		getter.Return([]string{"m." + name})
+		getter.CloseIf()
+		getter.Return([]string{zero})
+		getters = append(getters, getter)
+	}
+
+	// Getters of the fields of oneofs.
+	for _, field := range message.Field {
+		if field.OneofIndex == nil {
+			continue
+		}
+		goType, zero, err := a.fieldGoType(file, field)
+		if err != nil {
+			return errors.Wrapf(err, "%s.%s", protoName, field.GetName())
+		}
+		name := fieldGoName(field)
+		oneofName := types.CamelCase(message.OneofDecl[field.GetOneofIndex()].GetName())
+
+		getter, err := types.NewGoMethod("m", "*"+typeName, "Get"+name, nil, []types.TypeReference{types.NewUnsafeTypeReference(goType)}, "")
+		if err != nil {
+			return err
+		}
+		getter.Command(fmt.Sprintf("if x, ok := m.Get%s().(*%s_%s); ok {", oneofName, typeName, name), nil)
+		getter.Return([]string{"x." + name})
+		getter.CloseIf()
+		getter.Return([]string{zero})
+		getters = append(getters, getter)
+	}
+
+	reset, err := types.NewGoMethod("m", "*"+typeName, "Reset", nil, nil, "")
+	if err != nil {
+		return err
+	}
+	reset.Command(fmt.Sprintf("*m = %s{}", typeName), nil)
+
+	str, err := types.NewGoMethod("m", "*"+typeName, "String", nil, []types.TypeReference{types.String}, "")
+	if err != nil {
+		return err
+	}
+	str.Return([]string{"proto.CompactTextString(m)"})
+
+	protoMessage, err := types.NewGoMethod("m", "*"+typeName, "ProtoMessage", nil, nil, "")
+	if err != nil {
+		return err
+	}
+
+	desc, err := types.NewGoMethod("m", "*"+typeName, "Descriptor", nil, []types.TypeReference{
+		types.NewUnsafeTypeReference("[]byte"),
+		types.NewUnsafeTypeReference("[]int"),
+	}, "")
+	if err != nil {
+		return err
+	}
+	desc.Command(fmt.Sprintf("return %s, %s", serviceMetadataVarName(file), intSliceLiteral(path)), nil)
+
+	structGenerator.AddMethod(reset, str, protoMessage, desc)
+	structGenerator.AddMethod(getters...)
+
+	if err := goFile.TypesWithMethods(structGenerator); err != nil {
+		return err
+	}
+
+	if len(message.OneofDecl) > 0 {
+		code, err := a.oneofCode(file, typeName, message)
+		if err != nil {
+			return errors.Wrap(err, protoName)
+		}
+		goFile.Var(code)
+	}
+
+	return nil
+}
+
+// oneofCode generates the interfaces and wrapper types of the oneofs of a
+// message and XXX_OneofFuncs, which the proto package uses to marshal them.
+func (a *API) oneofCode(file *descriptor.FileDescriptorProto, typeName string, message *descriptor.DescriptorProto) (string, error) {
+	buff := new(bytes.Buffer)
+
+	for i, oneof := range message.OneofDecl {
+		iface := "is" + typeName + "_" + types.CamelCase(oneof.GetName())
+		fmt.Fprintf(buff, "type %s interface {\n\t%s()\n}\n\n", iface, iface)
+		for _, field := range message.Field {
+			if field.OneofIndex == nil || field.GetOneofIndex() != int32(i) {
+				continue
+			}
+			goType, _, err := a.fieldGoType(file, field)
+			if err != nil {
+				return "", err
+			}
+			tag, err := a.fieldTag(file, field)
+			if err != nil {
+				return "", err
+			}
+			wrapper := typeName + "_" + fieldGoName(field)
+			fmt.Fprintf(buff, "type %s struct {\n\t%s %s `%s`\n}\n\n", wrapper, fieldGoName(field), goType, tag)
+			fmt.Fprintf(buff, "func (*%s) %s() {}\n\n", wrapper, iface)
+		}
+	}
+
+	fmt.Fprintf(buff, "// XXX_OneofFuncs is for the internal use of the proto package.\n")
+	fmt.Fprintf(buff, "func (*%s) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {\n", typeName)
+	fmt.Fprintf(buff, "return _%s_OneofMarshaler, _%s_OneofUnmarshaler, _%s_OneofSizer, []interface{}{\n", typeName, typeName, typeName)
+	for _, field := range message.Field {
+		if field.OneofIndex != nil {
+			fmt.Fprintf(buff, "(*%s_%s)(nil),\n", typeName, fieldGoName(field))
+		}
+	}
+	buff.WriteString("}\n}\n\n")
+
+	var marshal, unmarshal, size bytes.Buffer
+	for i, oneof := range message.OneofDecl {
+		oneofName := types.CamelCase(oneof.GetName())
+		fmt.Fprintf(&marshal, "switch x := m.%s.(type) {\n", oneofName)
+		fmt.Fprintf(&size, "switch x := m.%s.(type) {\n", oneofName)
+		for _, field := range message.Field {
+			if field.OneofIndex == nil || field.GetOneofIndex() != int32(i) {
+				continue
+			}
+			name := fieldGoName(field)
+			wrapper := typeName + "_" + name
+			v := "x." + name
+			wire, enc, dec, sz, err := a.oneofFieldCoding(file, field, v)
+			if err != nil {
+				return "", err
+			}
+			key := fmt.Sprintf("%d<<3|proto.%s", field.GetNumber(), wire)
+
+			fmt.Fprintf(&marshal, "case *%s:\n\tb.EncodeVarint(%s)\n%s\n", wrapper, key, enc)
+
+			fmt.Fprintf(&unmarshal, "case %d: // %s.%s\n", field.GetNumber(), oneof.GetName(), field.GetName())
+			fmt.Fprintf(&unmarshal, "if wire != proto.%s {\n\treturn true, proto.ErrInternalBadWireType\n}\n", wire)
+			fmt.Fprintf(&unmarshal, "%s\nm.%s = &%s{x}\nreturn true, err\n", dec, oneofName, wrapper)
+
+			fmt.Fprintf(&size, "case *%s:\n\tn += proto.SizeVarint(%s)\n%s\n", wrapper, key, sz)
+		}
+		fmt.Fprintf(&marshal, "case nil:\ndefault:\n\treturn fmt.Errorf(\"%s.%s has unexpected type %%T\", x)\n}\n", typeName, oneofName)
+		fmt.Fprintf(&size, "case nil:\ndefault:\n\tpanic(fmt.Sprintf(\"proto: unexpected type %%T in oneof\", x))\n}\n")
+	}
+
+	fmt.Fprintf(buff, "func _%s_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {\nm := msg.(*%s)\n%sreturn nil\n}\n\n", typeName, typeName, marshal.String())
+	fmt.Fprintf(buff, "func _%s_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {\nm := msg.(*%s)\nswitch tag {\n%sdefault:\n\treturn false, nil\n}\n}\n\n", typeName, typeName, unmarshal.String())
+	fmt.Fprintf(buff, "func _%s_OneofSizer(msg proto.Message) (n int) {\nm := msg.(*%s)\n%sreturn n\n}", typeName, typeName, size.String())
+
+	return buff.String(), nil
+}
+
+// oneofFieldCoding returns the wire type and the statements to encode,
+// decode (into x and err) and size the value v of a field of a oneof.
+func (a *API) oneofFieldCoding(file *descriptor.FileDescriptorProto, field *descriptor.FieldDescriptorProto, v string) (wire, enc, dec, size string, err error) {
+	goType, _, err := a.fieldGoType(file, field)
+	if err != nil {
+		return "", "", "", "", err
+	}
+
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE:
+		return "WireFixed64",
+			fmt.Sprintf("b.EncodeFixed64(math.Float64bits(%s))", v),
+			"d, err := b.DecodeFixed64()\nx := math.Float64frombits(d)",
+			"n += 8", nil
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		return "WireFixed32",
+			fmt.Sprintf("b.EncodeFixed32(uint64(math.Float32bits(%s)))", v),
+			"d, err := b.DecodeFixed32()\nx := math.Float32frombits(uint32(d))",
+			"n += 4", nil
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		return "WireVarint",
+			fmt.Sprintf("t := uint64(0)\nif %s {\n\tt = 1\n}\nb.EncodeVarint(t)", v),
+			"d, err := b.DecodeVarint()\nx := d != 0",
+			"n += 1", nil
+	case descriptor.FieldDescriptorProto_TYPE_INT32, descriptor.FieldDescriptorProto_TYPE_INT64,
+		descriptor.FieldDescriptorProto_TYPE_UINT32, descriptor.FieldDescriptorProto_TYPE_UINT64,
+		descriptor.FieldDescriptorProto_TYPE_ENUM:
+		return "WireVarint",
+			fmt.Sprintf("b.EncodeVarint(uint64(%s))", v),
+			fmt.Sprintf("d, err := b.DecodeVarint()\nx := %s(d)", goType),
+			fmt.Sprintf("n += proto.SizeVarint(uint64(%s))", v), nil
+	case descriptor.FieldDescriptorProto_TYPE_FIXED64, descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		return "WireFixed64",
+			fmt.Sprintf("b.EncodeFixed64(uint64(%s))", v),
+			fmt.Sprintf("d, err := b.DecodeFixed64()\nx := %s(d)", goType),
+			"n += 8", nil
+	case descriptor.FieldDescriptorProto_TYPE_FIXED32, descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		return "WireFixed32",
+			fmt.Sprintf("b.EncodeFixed32(uint64(%s))", v),
+			fmt.Sprintf("d, err := b.DecodeFixed32()\nx := %s(d)", goType),
+			"n += 4", nil
+	case descriptor.FieldDescriptorProto_TYPE_SINT32:
+		return "WireVarint",
+			fmt.Sprintf("b.EncodeZigzag32(uint64(%s))", v),
+			"d, err := b.DecodeZigzag32()\nx := int32(d)",
+			fmt.Sprintf("n += proto.SizeVarint(uint64((uint32(%s) << 1) ^ uint32((int32(%s) >> 31))))", v, v), nil
+	case descriptor.FieldDescriptorProto_TYPE_SINT64:
+		return "WireVarint",
+			fmt.Sprintf("b.EncodeZigzag64(uint64(%s))", v),
+			"d, err := b.DecodeZigzag64()\nx := int64(d)",
+			fmt.Sprintf("n += proto.SizeVarint(uint64(%s<<1) ^ uint64((int64(%s) >> 63)))", v, v), nil
+	case descriptor.FieldDescriptorProto_TYPE_STRING:
+		return "WireBytes",
+			fmt.Sprintf("b.EncodeStringBytes(%s)", v),
+			"x, err := b.DecodeStringBytes()",
+			fmt.Sprintf("n += proto.SizeVarint(uint64(len(%s)))\nn += len(%s)", v, v), nil
+	case descriptor.FieldDescriptorProto_TYPE_BYTES:
+		return "WireBytes",
+			fmt.Sprintf("b.EncodeRawBytes(%s)", v),
+			"x, err := b.DecodeRawBytes(true)",
+			fmt.Sprintf("n += proto.SizeVarint(uint64(len(%s)))\nn += len(%s)", v, v), nil
+	case descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+		return "WireBytes",
+			fmt.Sprintf("if err := b.EncodeMessage(%s); err != nil {\n\treturn err\n}", v),
+			fmt.Sprintf("x := new(%s)\nerr := b.DecodeMessage(x)", strings.TrimPrefix(goType, "*")),
+			fmt.Sprintf("s := proto.Size(%s)\nn += proto.SizeVarint(uint64(s))\nn += s", v), nil
+	}
+	return "", "", "", "", errors.Errorf("field type %s is not supported", field.GetType())
+}
+
+// fieldGoType returns the Go type of a field and its zero value for getters.
+func (a *API) fieldGoType(file *descriptor.FileDescriptorProto, field *descriptor.FieldDescriptorProto) (goType string, zero string, err error) {
+	repeated := field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED
+
+	if field.GetType() == descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+		def := a.reg.MessageDefinition(field.GetTypeName())
+		if def == nil {
+			return "", "", errors.Errorf("could not find message for %s", field.GetTypeName())
+		}
+		if def.Descriptor.GetOptions().GetMapEntry() {
+			key, _, err := a.fieldGoType(file, def.Descriptor.Field[0])
+			if err != nil {
+				return "", "", err
+			}
+			value, _, err := a.fieldGoType(file, def.Descriptor.Field[1])
+			if err != nil {
+				return "", "", err
+			}
+			return fmt.Sprintf("map[%s]%s", key, value), "nil", nil
+		}
+		goType, err := a.goTypeName(field.GetTypeName())
+		if err != nil {
+			return "", "", err
+		}
+		if repeated {
+			return "[]*" + goType, "nil", nil
+		}
+		return "*" + goType, "nil", nil
+	}
+
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE:
+		goType, zero = "float64", "0"
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT:
+		goType, zero = "float32", "0"
+	case descriptor.FieldDescriptorProto_TYPE_INT64, descriptor.FieldDescriptorProto_TYPE_SFIXED64, descriptor.FieldDescriptorProto_TYPE_SINT64:
+		goType, zero = "int64", "0"
+	case descriptor.FieldDescriptorProto_TYPE_UINT64, descriptor.FieldDescriptorProto_TYPE_FIXED64:
+		goType, zero = "uint64", "0"
+	case descriptor.FieldDescriptorProto_TYPE_INT32, descriptor.FieldDescriptorProto_TYPE_SFIXED32, descriptor.FieldDescriptorProto_TYPE_SINT32:
+		goType, zero = "int32", "0"
+	case descriptor.FieldDescriptorProto_TYPE_UINT32, descriptor.FieldDescriptorProto_TYPE_FIXED32:
+		goType, zero = "uint32", "0"
+	case descriptor.FieldDescriptorProto_TYPE_BOOL:
+		goType, zero = "bool", "false"
+	case descriptor.FieldDescriptorProto_TYPE_STRING:
+		goType, zero = "string", `""`
+	case descriptor.FieldDescriptorProto_TYPE_BYTES:
+		goType, zero = "[]byte", "nil"
+	case descriptor.FieldDescriptorProto_TYPE_ENUM:
+		goType, zero, err = a.enumGoType(field.GetTypeName())
+		if err != nil {
+			return "", "", err
+		}
+	default:
+		return "", "", errors.Errorf("field type %s is not supported", field.GetType())
+	}
+
+	if repeated {
+		return "[]" + goType, "nil", nil
+	}
+	return goType, zero, nil
+}
+
+// enumGoType returns the Go type of an enum and the constant of its first
+// value.
+func (a *API) enumGoType(protoName string) (string, string, error) {
+	def := a.reg.EnumDefinition(protoName)
+	if def == nil {
+		return "", "", errors.Errorf("could not find enum for %s", protoName)
+	}
+
+	var prefix string
+	if pkg := a.goPackageName(def.File); pkg != a.genPkgName {
+		prefix = pkg + "."
+	}
+
+	zero := "0"
+	if len(def.Values) > 0 {
+		zero = prefix + def.Values[0].GoName()
+	}
+	return prefix + def.GoName(), zero, nil
+}
+
+// fieldTag returns the protobuf struct tags of a field, which describe its
+// encoding to the proto package.
+func (a *API) fieldTag(file *descriptor.FileDescriptorProto, field *descriptor.FieldDescriptorProto) (string, error) {
+	tag, err := a.protobufTag(field)
+	if err != nil {
+		return "", err
+	}
+	tag = fmt.Sprintf("protobuf:%s", strconv.Quote(tag))
+
+	if field.GetType() == descriptor.FieldDescriptorProto_TYPE_MESSAGE {
+		def := a.reg.MessageDefinition(field.GetTypeName())
+		if def != nil && def.Descriptor.GetOptions().GetMapEntry() {
+			key, err := a.protobufTag(def.Descriptor.Field[0])
+			if err != nil {
+				return "", err
+			}
+			value, err := a.protobufTag(def.Descriptor.Field[1])
+			if err != nil {
+				return "", err
+			}
+			tag += fmt.Sprintf(" protobuf_key:%s protobuf_val:%s", strconv.Quote(key), strconv.Quote(value))
+		}
+	}
+	return tag, nil
+}
+
+// protobufTag returns the value of the protobuf struct tag of a field, e.g.
+// "varint,1,opt,name=color,proto3,enum=example.Color".
+func (a *API) protobufTag(field *descriptor.FieldDescriptorProto) (string, error) {
+	var wire string
+	packable := true
+	switch field.GetType() {
+	case descriptor.FieldDescriptorProto_TYPE_DOUBLE, descriptor.FieldDescriptorProto_TYPE_FIXED64, descriptor.FieldDescriptorProto_TYPE_SFIXED64:
+		wire = "fixed64"
+	case descriptor.FieldDescriptorProto_TYPE_FLOAT, descriptor.FieldDescriptorProto_TYPE_FIXED32, descriptor.FieldDescriptorProto_TYPE_SFIXED32:
+		wire = "fixed32"
+	case descriptor.FieldDescriptorProto_TYPE_INT32, descriptor.FieldDescriptorProto_TYPE_INT64,
+		descriptor.FieldDescriptorProto_TYPE_UINT32, descriptor.FieldDescriptorProto_TYPE_UINT64,
+		descriptor.FieldDescriptorProto_TYPE_BOOL, descriptor.FieldDescriptorProto_TYPE_ENUM:
+		wire = "varint"
+	case descriptor.FieldDescriptorProto_TYPE_SINT32:
+		wire = "zigzag32"
+	case descriptor.FieldDescriptorProto_TYPE_SINT64:
+		wire = "zigzag64"
+	case descriptor.FieldDescriptorProto_TYPE_STRING, descriptor.FieldDescriptorProto_TYPE_BYTES, descriptor.FieldDescriptorProto_TYPE_MESSAGE:
+		wire, packable = "bytes", false
+	default:
+		return "", errors.Errorf("field type %s is not supported", field.GetType())
+	}
+
+	label := "opt"
+	var packed string
+	if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED {
+		label = "rep"
+		// Repeated scalars of proto3 are packed unless disabled.
+		if packable && (field.GetOptions() == nil || field.GetOptions().Packed == nil || field.GetOptions().GetPacked()) {
+			packed = ",packed"
+		}
+	}
+
+	tag := fmt.Sprintf("%s,%d,%s%s,name=%s", wire, field.GetNumber(), label, packed, field.GetName())
+	if json := field.GetJsonName(); json != "" && json != field.GetName() {
+		tag += ",json=" + json
+	}
+	tag += ",proto3"
+	if field.GetType() == descriptor.FieldDescriptorProto_TYPE_ENUM {
+		tag += ",enum=" + strings.TrimPrefix(field.GetTypeName(), ".")
+	}
+	if field.OneofIndex != nil {
+		tag += ",oneof"
+	}
+	return tag, nil
+}
+
+// messageRegistrations returns the statements of the init function which
+// register the enums and messages of a file with the proto package.
+func (a *API) messageRegistrations(file *descriptor.FileDescriptorProto) []string {
+	var lines []string
+	var enumNames []string
+	for _, enum := range file.EnumType {
+		enumNames = append(enumNames, fullProtoName(file, []string{enum.GetName()}))
+	}
+	var walk func(parents []string, messages []*descriptor.DescriptorProto)
+	walk = func(parents []string, messages []*descriptor.DescriptorProto) {
+		for _, message := range messages {
+			if message.GetOptions().GetMapEntry() {
+				continue
+			}
+			lineage := append(append([]string{}, parents...), message.GetName())
+			lines = append(lines, fmt.Sprintf("proto.RegisterType((*%s)(nil), %s)", goName(lineage...), strconv.Quote(strings.TrimPrefix(fullProtoName(file, lineage), "."))))
+			for _, enum := range message.EnumType {
+				enumNames = append(enumNames, fullProtoName(file, append(lineage, enum.GetName())))
+			}
+			walk(lineage, message.NestedType)
+		}
+	}
+	walk(nil, file.MessageType)
+
+	sort.Strings(enumNames)
+	for _, protoName := range enumNames {
+		typeName := a.reg.EnumDefinition(protoName).GoName()
+		lines = append(lines, fmt.Sprintf("proto.RegisterEnum(%s, %s_name, %s_value)", strconv.Quote(strings.TrimPrefix(protoName, ".")), typeName, typeName))
+	}
+	return lines
+}
+
+// fullProtoName returns the fully-qualified proto name of a definition
+// nested in lineage, e.g. ".example.Outer.Inner".
+func fullProtoName(file *descriptor.FileDescriptorProto, lineage []string) string {
+	prefix := "."
+	if file.GetPackage() != "" {
+		prefix += file.GetPackage() + "."
+	}
+	return prefix + strings.Join(lineage, ".")
+}
+
+func intSliceLiteral(values []int) string {
+	s := make([]string, 0, len(values))
+	for _, v := range values {
+		s = append(s, strconv.Itoa(v))
+	}
+	return "[]int{" + strings.Join(s, ", ") + "}"
+}
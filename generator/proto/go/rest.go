@@ -0,0 +1,303 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package goproto
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/donutloop/xservice/framework/rest"
+	"github.com/donutloop/xservice/internal/xgenerator/types"
+	"github.com/donutloop/xservice/internal/xproto/xprotoutil"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	"github.com/pkg/errors"
+	"strconv"
+)
+
+// Field numbers of google.api.http (an extension of
+// google.protobuf.MethodOptions) and of the google.api.HttpRule message.
+const (
+	googleAPIHTTPField int32 = 72295728
+
+	httpRuleGetField                int32 = 2
+	httpRulePutField                int32 = 3
+	httpRulePostField               int32 = 4
+	httpRuleDeleteField             int32 = 5
+	httpRulePatchField              int32 = 6
+	httpRuleBodyField               int32 = 7
+	httpRuleCustomField             int32 = 8
+	httpRuleAdditionalBindingsField int32 = 11
+	httpRuleResponseBodyField       int32 = 12
+
+	customHTTPPatternKindField int32 = 1
+	customHTTPPatternPathField int32 = 2
+)
+
+// httpRules returns the rules of the google.api.http annotation of a method,
+// the primary binding first, followed by its additional bindings.
+func httpRules(method *descriptor.MethodDescriptorProto) ([]rest.Rule, error) {
+	fields, err := xprotoutil.RawFields(method.GetOptions())
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not read options of method %s", method.GetName())
+	}
+
+	rules := make([]rest.Rule, 0)
+	for _, f := range fields {
+		if f.Number != googleAPIHTTPField || f.WireType != xprotoutil.WireBytes {
+			continue
+		}
+		parsed, err := parseHTTPRule(f.Bytes, true)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid google.api.http annotation of method %s", method.GetName())
+		}
+		rules = append(rules, parsed...)
+	}
+	return rules, nil
+}
+
+func parseHTTPRule(b []byte, allowAdditionalBindings bool) ([]rest.Rule, error) {
+	fields, err := xprotoutil.ParseRawFields(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var rule rest.Rule
+	var additional [][]byte
+	for _, f := range fields {
+		if f.WireType != xprotoutil.WireBytes {
+			continue
+		}
+		switch f.Number {
+		case httpRuleGetField:
+			rule.Method, rule.Pattern = "GET", f.String()
+		case httpRulePutField:
+			rule.Method, rule.Pattern = "PUT", f.String()
+		case httpRulePostField:
+			rule.Method, rule.Pattern = "POST", f.String()
+		case httpRuleDeleteField:
+			rule.Method, rule.Pattern = "DELETE", f.String()
+		case httpRulePatchField:
+			rule.Method, rule.Pattern = "PATCH", f.String()
+		case httpRuleCustomField:
+			custom, err := xprotoutil.ParseRawFields(f.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			for _, c := range custom {
+				switch c.Number {
+				case customHTTPPatternKindField:
+					rule.Method = c.String()
+				case customHTTPPatternPathField:
+					rule.Pattern = c.String()
+				}
+			}
+		case httpRuleBodyField:
+			rule.Body = f.String()
+		case httpRuleAdditionalBindingsField:
+			if !allowAdditionalBindings {
+				return nil, errors.New("additional bindings must not be nested")
+			}
+			additional = append(additional, f.Bytes)
+		case httpRuleResponseBodyField:
+			// Responses are always the whole message, silently ignoring
+			// response_body would change the response of the route.
+			return nil, errors.New("response_body is not supported")
+		}
+	}
+
+	if rule.Method == "" || rule.Pattern == "" {
+		return nil, errors.New("rule without method or path")
+	}
+	if _, err := rest.ParseTemplate(rule.Pattern); err != nil {
+		return nil, err
+	}
+	if (rule.Method == "GET" || rule.Method == "DELETE") && rule.Body != "" {
+		return nil, errors.Errorf("%s rule %s must not have a body", rule.Method, rule.Pattern)
+	}
+
+	rules := []rest.Rule{rule}
+	for _, b := range additional {
+		parsed, err := parseHTTPRule(b, false)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, parsed...)
+	}
+	return rules, nil
+}
+
+func hasHTTPRules(service *descriptor.ServiceDescriptorProto) (bool, error) {
+	for _, method := range service.Method {
+		rules, err := httpRules(method)
+		if err != nil {
+			return false, err
+		}
+		if len(rules) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func restRouterVarName(service *descriptor.ServiceDescriptorProto) string {
+	return unexported(serviceName(service)) + "RESTRouter"
+}
+
+func ruleLiteral(rule rest.Rule) string {
+	literal := fmt.Sprintf("rest.Rule{Method: %s, Pattern: %s", strconv.Quote(rule.Method), strconv.Quote(rule.Pattern))
+	if rule.Body != "" {
+		literal += fmt.Sprintf(", Body: %s", strconv.Quote(rule.Body))
+	}
+	return literal + "}"
+}
+
+// generateServerREST generates the router of the RESTful routes of a service
+// and the method dispatching matched requests to the serve methods.
+func (a *API) generateServerREST(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, structGenerator *types.StructGenerator, goFile *types.FileGenerator) (*types.StructGenerator, error) {
+
+	goFile.Import("", "github.com/donutloop/xservice/framework/rest")
+
+	routes := new(bytes.Buffer)
+	switchGenerator, err := types.NewSwitchGenerator("route.Name")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, method := range service.Method {
+		rules, err := httpRules(method)
+		if err != nil {
+			return nil, err
+		}
+		if len(rules) == 0 {
+			continue
+		}
+
+		for _, rule := range rules {
+			routes.WriteString(fmt.Sprintf("rest.Route{Name: %s, Rule: %s},\n", strconv.Quote(methodName(method)), ruleLiteral(rule)))
+		}
+
+		caseGenerator, err := types.NewCaseGenerator(strconv.Quote(methodName(method)))
+		if err != nil {
+			return nil, err
+		}
+		caseGenerator.Caller(types.NewUnsafeTypeReference(fmt.Sprintf("s.serve%sContent", methodName(method))), []string{"ctx", "resp", "req", "rest.NewRequestDecoder(route, values)", "s.options.EncodeJSONResponse"})
+		caseGenerator.Return()
+		switchGenerator.Case(*caseGenerator)
+	}
+
+	goFile.Var(fmt.Sprintf(`// %s routes the RESTful routes of the google.api.http annotations of %s.
+var %s = rest.MustNewRouter(
+	%s)`, restRouterVarName(service), serviceName(service), restRouterVarName(service), routes.String()))
+
+	comment := "serveREST dispatches a request matched by a RESTful route to its method"
+	method, err := types.NewGoMethod("s", fmt.Sprintf("*%s", structGenerator.StructMetaData.Name), "serveREST", []*types.Parameter{
+		{
+			NameOfParameter: "ctx",
+			Typ:             types.NewUnsafeTypeReference("context.Context"),
+		},
+		{
+			NameOfParameter: "resp",
+			Typ:             types.NewUnsafeTypeReference("http.ResponseWriter"),
+		},
+		{
+			NameOfParameter: "req",
+			Typ:             types.NewUnsafeTypeReference("*http.Request"),
+		},
+		{
+			NameOfParameter: "route",
+			Typ:             types.NewUnsafeTypeReference("*rest.Route"),
+		},
+		{
+			NameOfParameter: "values",
+			Typ:             types.NewUnsafeTypeReference("map[string]string"),
+		},
+	}, nil, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	defaultCaseGenerator, err := types.NewDefaultCaseGenerator()
+	if err != nil {
+		return nil, err
+	}
+	defaultCaseGenerator.DefAssginCall([]string{"msg"}, types.NewUnsafeTypeReference("fmt.Sprintf"), []string{`"no handler for route %q"`, "route.Name"})
+	defaultCaseGenerator.DefAssginCall([]string{"terr"}, types.NewUnsafeTypeReference("errors.BadRouteError"), []string{"msg", "req.Method", "req.URL.Path"})
+	defaultCaseGenerator.Caller(types.NewUnsafeTypeReference("s.writeError"), []string{"ctx", "resp", "terr"})
+	defaultCaseGenerator.Return()
+	switchGenerator.Default(*defaultCaseGenerator)
+
+	if err := method.TypeSwitch(*switchGenerator); err != nil {
+		return nil, err
+	}
+
+	structGenerator.AddMethod(method)
+	return structGenerator, nil
+}
+
+// generateRESTClientEndpoints generates the methods of the REST client.
+// Methods without google.api.http annotation are called by their RPC route.
+func (a *API) generateRESTClientEndpoints(fileDescriptor *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, structGenerator *types.StructGenerator) (*types.StructGenerator, error) {
+
+	for i, method := range service.Method {
+		methName := methodName(method)
+		inputType, err := a.goTypeName(method.GetInputType())
+		if err != nil {
+			return nil, err
+		}
+		outputType, err := a.goTypeName(method.GetOutputType())
+		if err != nil {
+			return nil, err
+		}
+		rules, err := httpRules(method)
+		if err != nil {
+			return nil, err
+		}
+
+		comment := fmt.Sprintf("%s sends an %s object to the server", methName, inputType)
+		if len(rules) > 0 {
+			comment = fmt.Sprintf("%s sends an %s object to %s %s", methName, inputType, rules[0].Method, rules[0].Pattern)
+		}
+		comment = withDoc(comment, a.methodDoc(fileDescriptor, service, method))
+		goMethod, err := types.NewGoMethod("c", fmt.Sprintf("*%s", structGenerator.StructMetaData.Name), methName, []*types.Parameter{
+			{
+				NameOfParameter: "ctx",
+				Typ:             types.NewUnsafeTypeReference("context.Context"),
+			},
+			{
+				NameOfParameter: "in",
+				Typ:             types.NewUnsafeTypeReference(fmt.Sprintf("*%s", inputType)),
+			},
+		}, []types.TypeReference{
+			types.NewUnsafeTypeReference(fmt.Sprintf("*%s", outputType)),
+			types.NewUnsafeTypeReference("error"),
+		}, comment)
+		if err != nil {
+			return nil, err
+		}
+
+		goMethod.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithPackageName"), []string{"ctx", `"` + pkgName(fileDescriptor) + `"`})
+		goMethod.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithServiceName"), []string{"ctx", `"` + serviceName(service) + `"`})
+		goMethod.DefCall([]string{"ctx"}, types.NewUnsafeTypeReference("xcontext.WithMethodName"), []string{"ctx", `"` + methName + `"`})
+		if len(rules) > 0 {
+			err = generateInterceptedClientCall(&goMethod.GoBlockGenerator, inputType, outputType, "rest.DoRequest", "c.options", "c.client", "c.addr", ruleLiteral(rules[0]))
+		} else {
+			err = generateInterceptedClientCall(&goMethod.GoBlockGenerator, inputType, outputType, "c.options.DoJSONRequest", "c.client", fmt.Sprintf("c.urls[%s]", strconv.Itoa(i)))
+		}
+		if err != nil {
+			return nil, err
+		}
+		structGenerator.AddMethod(goMethod)
+	}
+
+	return structGenerator, nil
+}
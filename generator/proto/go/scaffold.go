@@ -0,0 +1,134 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package goproto
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/donutloop/xservice/internal/xgenerator/types"
+	"github.com/donutloop/xservice/internal/xproto"
+	"github.com/golang/protobuf/proto"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+	plugin "github.com/golang/protobuf/protoc-gen-go/plugin"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/imports"
+	"path"
+	"strconv"
+)
+
+// generateUnimplemented generates <Service>Unimplemented, an implementation
+// of a service whose methods return an unimplemented error. Implementations
+// which embed it keep compiling when methods are added to the service.
+func (a *API) generateUnimplemented(file *descriptor.FileDescriptorProto, service *descriptor.ServiceDescriptorProto, goFile *types.FileGenerator) (*types.FileGenerator, error) {
+	structName := unimplementedStruct(service)
+	structGenerator, err := types.NewGoStruct(structName, true, true)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := fmt.Sprintf("%s implements %s with methods which return an unimplemented error. Embed it in implementations of %s, so they keep compiling when methods are added to the service.", structName, serviceName(service), serviceName(service))
+	structGenerator.StructMetaData.Comment = append(structGenerator.StructMetaData.Comment, structGenerator.PrepareComment(comment)...)
+
+	for _, method := range service.Method {
+		inputType, err := a.goTypeName(method.GetInputType())
+		if err != nil {
+			return nil, err
+		}
+		outputType, err := a.goTypeName(method.GetOutputType())
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := types.NewGoMethod("u", structName, methodName(method), []*types.Parameter{
+			{
+				NameOfParameter: "ctx",
+				Typ:             types.NewUnsafeTypeReference("context.Context"),
+			},
+			{
+				NameOfParameter: "req",
+				Typ:             types.NewUnsafeTypeReference("*" + inputType),
+			},
+		}, []types.TypeReference{
+			types.NewUnsafeTypeReference("*" + outputType),
+			types.NewUnsafeTypeReference("error"),
+		}, fmt.Sprintf("%s returns an unimplemented error.", methodName(method)))
+		if err != nil {
+			return nil, err
+		}
+		msg := fmt.Sprintf("%s.%s is not implemented", fullServiceName(file, service), methodName(method))
+		m.Command(fmt.Sprintf("return nil, errors.NewError(errors.Unimplemented, %s)", strconv.Quote(msg)), nil)
+		structGenerator.AddMethod(m)
+	}
+
+	if err := goFile.TypesWithMethods(structGenerator); err != nil {
+		return nil, err
+	}
+	return goFile, nil
+}
+
+// generateScaffold generates the main package of a server of the services of
+// a file, <proto dir>/cmd/<proto name>/main.go. Its implementations of the
+// services embed their <Service>Unimplemented structs. Scaffolds are meant to
+// be generated once and edited by hand, see IsScaffold.
+func (a *API) generateScaffold(file *descriptor.FileDescriptorProto) (*plugin.CodeGeneratorResponse_File, error) {
+	importPath := a.goImportPath(file)
+	if importPath == "" {
+		return nil, errors.Errorf("%s: the scaffold needs the import path of the generated package, set by the go_package option or a M parameter", file.GetName())
+	}
+
+	buff := new(bytes.Buffer)
+	fmt.Fprintf(buff, "// Scaffold generated by xproto %s from %s.\n", xproto.Version, file.GetName())
+	buff.WriteString("// It is generated once, implement the services by editing it.\n\n")
+	buff.WriteString("package main\n\n")
+	fmt.Fprintf(buff, "import (\n\t\"log\"\n\t\"net/http\"\n\n\tpb %s\n)\n", strconv.Quote(importPath))
+
+	for _, service := range file.Service {
+		name := serviceName(service)
+		fmt.Fprintf(buff, "\n// %s implements pb.%s.\n// Methods which aren't implemented yet return an unimplemented error of\n// the embedded pb.%s.\n", scaffoldStruct(service), name, unimplementedStruct(service))
+		fmt.Fprintf(buff, "type %s struct {\n\tpb.%s\n}\n", scaffoldStruct(service), unimplementedStruct(service))
+	}
+
+	buff.WriteString("\nfunc main() {\n\tmux := http.NewServeMux()\n")
+	for _, service := range file.Service {
+		name := serviceName(service)
+		fmt.Fprintf(buff, "\tmux.Handle(pb.%sPathPrefix, pb.New%sServer(&%s{}, nil))\n", name, name, scaffoldStruct(service))
+	}
+	buff.WriteString("\n\tserver := &http.Server{Addr: \":8080\", Handler: mux}\n\tlog.Fatal(server.ListenAndServe())\n}\n")
+
+	name := path.Join(path.Dir(file.GetName()), "cmd", types.BaseName(file.GetName()), "main.go")
+	content, err := imports.Process(name, buff.Bytes(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not format the scaffold of %s", file.GetName())
+	}
+
+	a.scaffolds[name] = true
+	return &plugin.CodeGeneratorResponse_File{
+		Name:    proto.String(name),
+		Content: proto.String(string(content)),
+	}, nil
+}
+
+// IsScaffold reports whether a generated file is a scaffold. Scaffolds
+// shouldn't overwrite existing files, they're edited by hand.
+func (a *API) IsScaffold(name string) bool {
+	return a.scaffolds[name]
+}
+
+func unimplementedStruct(service *descriptor.ServiceDescriptorProto) string {
+	return serviceName(service) + "Unimplemented"
+}
+
+func scaffoldStruct(service *descriptor.ServiceDescriptorProto) string {
+	return unexported(serviceName(service)) + "Service"
+}
@@ -0,0 +1,23 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+// Package testdata contains the protos of the golden tests of the generator.
+// Their descriptor sets are regenerated with go generate, the golden files
+// with go test -update.
+package testdata
+
+//go:generate protoc -I services --descriptor_set_out=services/fileset.pb --include_imports --include_source_info services/services.proto
+//go:generate protoc -I imports --descriptor_set_out=imports/fileset.pb --include_imports --include_source_info imports/imports.proto
+//go:generate protoc -I streaming --descriptor_set_out=streaming/fileset.pb --include_imports --include_source_info streaming/streaming.proto
+//go:generate protoc -I policy -I ../../../../proto --descriptor_set_out=policy/fileset.pb --include_imports --include_source_info policy/policy.proto
+//go:generate protoc -I rest -I ../../../../proto --descriptor_set_out=rest/fileset.pb --include_imports --include_source_info rest/rest.proto
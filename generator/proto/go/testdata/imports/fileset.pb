@@ -0,0 +1,18 @@
+
+j
+google/protobuf/empty.protogoogle.protobuf"
+EmptyB)Z'github.com/golang/protobuf/ptypes/emptybproto3
+
+dep/dep.protoexample.dep"
+Entry
+name (	RnameBGZEgithub.com/donutloop/xservice/generator/proto/go/testdata/imports/depbproto3
+
+proto/tag.protoexample.proto"
+Tag
+name (	RnameBIZGgithub.com/donutloop/xservice/generator/proto/go/testdata/imports/protobproto3
+
+imports.protoexample.importsgoogle/protobuf/empty.protodep/dep.protoproto/tag.proto2
+Registry6
+Ping.google.protobuf.Empty.google.protobuf.Empty2
+Register.example.dep.Entry.example.dep.Entry-
+Tag.example.proto.Tag.example.proto.TagBKZIgithub.com/donutloop/xservice/generator/proto/go/testdata/imports;importsbproto3
\ No newline at end of file
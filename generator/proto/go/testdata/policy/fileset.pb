@@ -0,0 +1,20 @@
+
+
+ google/protobuf/descriptor.protogoogle.protobuf"
+ServiceOptions*	"
+MethodOptions*	B@Z>github.com/golang/protobuf/protoc-gen-go/descriptor;descriptor
+
+xservice/options.protoxservice google/protobuf/descriptor.proto:I
+service_scopes.google.protobuf.ServiceOptions (	Rservice_scopes:G
+service_roles.google.protobuf.ServiceOptions (	Rservice_roles:8
+scopes.google.protobuf.MethodOptions (	Rscopes:6
+roles.google.protobuf.MethodOptions (	RrolesB9Z7github.com/donutloop/xservice/framework/options;optionsbproto3
+
+policy.protoexample.policyxservice/options.proto"
+User
+id (	Rid2
+Users1
+Get.example.policy.User.example.policy.UserN
+Delete.example.policy.User.example.policy.User"users.writeadminusersmemberadmin2<
+Public2
+Ping.example.policy.User.example.policy.UserBIZGgithub.com/donutloop/xservice/generator/proto/go/testdata/policy;policybproto3
\ No newline at end of file
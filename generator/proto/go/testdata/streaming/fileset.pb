@@ -0,0 +1,7 @@
+
+
+streaming.protoexample.streaming"
+Tick
+n (Rn2E
+Ticker;
+Watch.example.streaming.Tick.example.streaming.Tick0BOZMgithub.com/donutloop/xservice/generator/proto/go/testdata/streaming;streamingbproto3
\ No newline at end of file
@@ -15,10 +15,16 @@ package helloworld_test
 
 import (
 	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"github.com/donutloop/xservice/framework/xhttp"
 	"github.com/donutloop/xservice/integration_tests/api_hello_world"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -68,3 +74,255 @@ func TestHelloWorldProtobufferCall(t *testing.T) {
 		t.Fatalf(`unexpected text (actual: "%s", expected: "%s")`, resp.Text, expectedMessage)
 	}
 }
+
+func TestHelloWorldGetCall(t *testing.T) {
+	var statusCodes []int
+	handler := helloworld.NewHelloWorldServer(&HelloWorldServer{}, nil)
+	server := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			t.Errorf("unexpected method (actual: %s, expected: %s)", req.Method, http.MethodGet)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		statusCodes = append(statusCodes, recorder.Code)
+		for k, v := range recorder.Header() {
+			resp.Header()[k] = v
+		}
+		resp.WriteHeader(recorder.Code)
+		resp.Write(recorder.Body.Bytes())
+	}))
+	defer server.Close()
+
+	clients := []helloworld.HelloWorld{
+		helloworld.NewHelloWorldJSONClient(server.URL, transport.NewCachingClient(&http.Client{}, 16)),
+		helloworld.NewHelloWorldProtobufferClient(server.URL, transport.NewCachingClient(&http.Client{}, 16)),
+	}
+
+	for _, client := range clients {
+		statusCodes = nil
+		for i := 0; i < 2; i++ {
+			resp, err := client.Hello(xcontext.WithHTTPGet(context.Background()), &helloworld.HelloReq{Subject: "World"})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expectedMessage := "Hello World"
+			if resp.Text != expectedMessage {
+				t.Fatalf(`unexpected text (actual: "%s", expected: "%s")`, resp.Text, expectedMessage)
+			}
+		}
+
+		// the second call is revalidated with the ETag of the first response
+		if len(statusCodes) != 2 || statusCodes[0] != http.StatusOK || statusCodes[1] != http.StatusNotModified {
+			t.Fatalf("unexpected status codes (actual: %v, expected: %v)", statusCodes, []int{http.StatusOK, http.StatusNotModified})
+		}
+	}
+}
+
+func TestHelloWorldPathPrefix(t *testing.T) {
+	server := httptest.NewServer(helloworld.NewHelloWorldServerWithOptions(&HelloWorldServer{}, transport.WithServerPathPrefix("/api/v2")))
+	defer server.Close()
+
+	client := helloworld.NewHelloWorldJSONClientWithOptions(server.URL, &http.Client{}, transport.WithClientPathPrefix("/api/v2"))
+	resp, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedMessage := "Hello World"
+	if resp.Text != expectedMessage {
+		t.Fatalf(`unexpected text (actual: "%s", expected: "%s")`, resp.Text, expectedMessage)
+	}
+
+	client = helloworld.NewHelloWorldJSONClient(server.URL, &http.Client{})
+	if _, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"}); err == nil {
+		t.Fatal("expected an error for the default path prefix")
+	}
+}
+
+func TestHelloWorldOptions(t *testing.T) {
+	var serverCalls, clientCalls []string
+	server := httptest.NewServer(helloworld.NewHelloWorldServerWithOptions(&HelloWorldServer{},
+		transport.WithServerInterceptors(func(next transport.Method) transport.Method {
+			return func(ctx context.Context, request interface{}) (interface{}, error) {
+				req := request.(*helloworld.HelloReq)
+				serverCalls = append(serverCalls, req.Subject)
+				return next(ctx, &helloworld.HelloReq{Subject: strings.ToUpper(req.Subject)})
+			}
+		}),
+		transport.WithMaxRequestBodySize(64),
+	))
+	defer server.Close()
+
+	client := helloworld.NewHelloWorldProtobufferClientWithOptions(server.URL, &http.Client{},
+		transport.WithClientInterceptors(func(next transport.Method) transport.Method {
+			return func(ctx context.Context, request interface{}) (interface{}, error) {
+				method, _ := xcontext.MethodName(ctx)
+				clientCalls = append(clientCalls, method)
+				return next(ctx, request)
+			}
+		}),
+	)
+
+	resp, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedMessage := "Hello WORLD"
+	if resp.Text != expectedMessage {
+		t.Fatalf(`unexpected text (actual: "%s", expected: "%s")`, resp.Text, expectedMessage)
+	}
+	if len(serverCalls) != 1 || serverCalls[0] != "World" {
+		t.Fatalf("unexpected server interceptor calls (actual: %v)", serverCalls)
+	}
+	if len(clientCalls) != 1 || clientCalls[0] != "Hello" {
+		t.Fatalf("unexpected client interceptor calls (actual: %v)", clientCalls)
+	}
+
+	_, err = client.Hello(context.Background(), &helloworld.HelloReq{Subject: strings.Repeat("World", 20)})
+	terr, ok := err.(errors.Error)
+	if !ok || terr.Code() != errors.ResourceExhausted {
+		t.Fatalf("unexpected error for a too large body (actual: %v)", err)
+	}
+	if len(serverCalls) != 1 {
+		t.Fatalf("unexpected server interceptor calls (actual: %v)", serverCalls)
+	}
+}
+
+func TestHelloWorldJSONOptions(t *testing.T) {
+	server := httptest.NewServer(helloworld.NewHelloWorldServerWithOptions(&HelloWorldServer{},
+		transport.WithServerJSONOptions(transport.JSONOptions{DisallowUnknownFields: true}),
+	))
+	defer server.Close()
+
+	client := helloworld.NewHelloWorldJSONClientWithOptions(server.URL, &http.Client{},
+		transport.WithClientJSONOptions(transport.JSONOptions{CamelCase: true, EmitDefaults: true}),
+	)
+	resp, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Hello World" {
+		t.Fatalf(`unexpected text (actual: "%s", expected: "Hello World")`, resp.Text)
+	}
+
+	post := func(body, options string) (*http.Response, string) {
+		req, err := http.NewRequest(http.MethodPost, server.URL+helloworld.HelloWorldPathPrefix+"Hello", strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(xhttp.ContentTypeHeader, xhttp.ApplicationJson)
+		req.Header.Set(xhttp.JSONOptionsHeader, options)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		b, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp, string(b)
+	}
+
+	httpResp, body := post(`{"subject": "World"}`, "indent")
+	expected := "{\n  \"text\": \"Hello World\"\n}"
+	if httpResp.StatusCode != http.StatusOK || body != expected {
+		t.Fatalf(`unexpected response (status: %d, body: "%s", expected: "%s")`, httpResp.StatusCode, body, expected)
+	}
+
+	httpResp, body = post(`{"subject": "World", "unknown": true}`, "")
+	if httpResp.StatusCode != http.StatusBadRequest || !strings.Contains(body, `"json_path":"unknown"`) {
+		t.Fatalf(`unexpected response for an unknown field (status: %d, body: "%s")`, httpResp.StatusCode, body)
+	}
+
+	httpResp, body = post(`{"subject": "World"}`, "unknown")
+	if httpResp.StatusCode != http.StatusBadRequest || !strings.Contains(body, string(errors.InvalidArgument)) {
+		t.Fatalf(`unexpected response for an unknown option (status: %d, body: "%s")`, httpResp.StatusCode, body)
+	}
+}
+
+func TestHelloWorldNegotiation(t *testing.T) {
+	var contentType string
+	server := httptest.NewServer(helloworld.NewHelloWorldServerWithOptions(&HelloWorldServer{},
+		transport.WithServerInterceptors(func(next transport.Method) transport.Method {
+			return func(ctx context.Context, request interface{}) (interface{}, error) {
+				contentType, _ = xcontext.ResponseContentType(ctx)
+				return next(ctx, request)
+			}
+		}),
+	))
+	defer server.Close()
+
+	post := func(contentType, accept string) *http.Response {
+		req, err := http.NewRequest(http.MethodPost, server.URL+helloworld.HelloWorldPathPrefix+"Hello", strings.NewReader(`{"subject": "World"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set(xhttp.ContentTypeHeader, contentType)
+		req.Header.Set("Accept", accept)
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := post(xhttp.ApplicationJson, xhttp.ApplicationProtobuf)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get(xhttp.ContentTypeHeader) != xhttp.ApplicationProtobuf {
+		t.Fatalf("unexpected response (status: %d, Content-Type: %s)", resp.StatusCode, resp.Header.Get(xhttp.ContentTypeHeader))
+	}
+	if contentType != xhttp.ApplicationProtobuf {
+		t.Fatalf(`unexpected response content type in context (actual: "%s")`, contentType)
+	}
+
+	if resp := post("text/xml", ""); resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("unexpected status for an unsupported Content-Type (actual: %d)", resp.StatusCode)
+	}
+	if resp := post(xhttp.ApplicationJson, "text/html"); resp.StatusCode != http.StatusNotAcceptable {
+		t.Fatalf("unexpected status for an unacceptable Accept (actual: %d)", resp.StatusCode)
+	}
+}
+
+// helloCodec is a custom codec, it encodes messages as protobuf with its own
+// content type.
+type helloCodec struct {
+	transport.ProtobufCodec
+}
+
+func (c helloCodec) ContentType() string { return "application/x-hello" }
+
+func TestHelloWorldClientCodec(t *testing.T) {
+	var contentType string
+	server := httptest.NewServer(helloworld.NewHelloWorldServerWithOptions(&HelloWorldServer{},
+		transport.WithServerCodec(helloCodec{}),
+		transport.WithServerInterceptors(func(next transport.Method) transport.Method {
+			return func(ctx context.Context, request interface{}) (interface{}, error) {
+				contentType, _ = xcontext.ResponseContentType(ctx)
+				return next(ctx, request)
+			}
+		}),
+	))
+	defer server.Close()
+
+	clients := []helloworld.HelloWorld{
+		helloworld.NewHelloWorldJSONClientWithOptions(server.URL, &http.Client{}, transport.WithClientCodec(helloCodec{})),
+		helloworld.NewHelloWorldProtobufferClientWithOptions(server.URL, &http.Client{}, transport.WithClientCodec(helloCodec{})),
+	}
+
+	for _, client := range clients {
+		contentType = ""
+		resp, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Text != "Hello World" {
+			t.Fatalf(`unexpected text (actual: "%s", expected: "Hello World")`, resp.Text)
+		}
+		if contentType != "application/x-hello" {
+			t.Fatalf(`unexpected response content type (actual: "%s", expected: "application/x-hello")`, contentType)
+		}
+	}
+}
@@ -68,14 +68,15 @@ func init() {
 func init() { proto.RegisterFile("helloworld.proto", fileDescriptor0) }
 
 var fileDescriptor0 = []byte{
-	// 143 bytes of a gzipped FileDescriptorProto
+	// 145 bytes of a gzipped FileDescriptorProto
 	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0xc8, 0x48, 0xcd, 0xc9,
 	0xc9, 0x2f, 0xcf, 0x2f, 0xca, 0x49, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x12, 0x4a, 0xad,
 	0x48, 0xcc, 0x2d, 0xc8, 0x49, 0xd5, 0x43, 0xc8, 0x28, 0xa9, 0x70, 0x71, 0x78, 0x80, 0x78, 0x41,
 	0xa9, 0x85, 0x42, 0x12, 0x5c, 0xec, 0xc5, 0xa5, 0x49, 0x59, 0xa9, 0xc9, 0x25, 0x12, 0x8c, 0x0a,
 	0x8c, 0x1a, 0x9c, 0x41, 0x30, 0xae, 0x92, 0x3c, 0x17, 0x27, 0x54, 0x55, 0x71, 0x81, 0x90, 0x10,
-	0x17, 0x4b, 0x49, 0x6a, 0x05, 0x4c, 0x0d, 0x98, 0x6d, 0x14, 0xc4, 0xc5, 0x05, 0x56, 0x10, 0x0e,
-	0x32, 0x54, 0xc8, 0x85, 0x8b, 0x15, 0xcc, 0x13, 0x92, 0xd1, 0xc3, 0xb4, 0x52, 0x0f, 0x66, 0x9f,
-	0x94, 0x2c, 0x1e, 0xd9, 0xe2, 0x02, 0x27, 0x9e, 0x28, 0x2e, 0x84, 0x78, 0x12, 0x1b, 0xd8, 0x0f,
-	0xc6, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0x65, 0x34, 0xd5, 0xb9, 0xd7, 0x00, 0x00, 0x00,
+	0x17, 0x4b, 0x49, 0x6a, 0x05, 0x4c, 0x0d, 0x98, 0x6d, 0x14, 0xce, 0xc5, 0x05, 0x56, 0x10, 0x0e,
+	0x32, 0x54, 0xc8, 0x93, 0x8b, 0x15, 0xcc, 0x13, 0x92, 0xd1, 0xc3, 0xb4, 0x52, 0x0f, 0x66, 0x9f,
+	0x94, 0x2c, 0x1e, 0xd9, 0xe2, 0x02, 0x25, 0xe6, 0x09, 0x4c, 0x8c, 0x4e, 0x3c, 0x51, 0x5c, 0x08,
+	0xc9, 0x24, 0x36, 0xb0, 0x47, 0x8c, 0x01, 0x03, 0x00, 0x40, 0xa5, 0x16, 0x87, 0xdc, 0x00, 0x00,
+	0x00,
 }
@@ -1,35 +1,32 @@
-//Code generated by xproto [v0.1.0], DO NOT EDIT.
-//source: [helloworld.proto]
-//Package [helloworld] is a generated stub package.
-//This code was generated with github.com/donutloop/xservice [v0.1.0]
-//It is generated from these files:
-//	 [helloworld.proto]
-//package [helloworld]
+// Code generated by xproto v0.1.0, DO NOT EDIT.
+// source: helloworld.proto
+// Package helloworld is a generated stub package.
+// This code was generated with github.com/donutloop/xservice v0.1.0
+// It is generated from these files:
+// 	 helloworld.proto
+// package helloworld
 
 package helloworld
 
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
-	"strings"
 
 	"github.com/donutloop/xservice/framework/errors"
 	"github.com/donutloop/xservice/framework/hooks"
 	"github.com/donutloop/xservice/framework/server"
 	"github.com/donutloop/xservice/framework/transport"
 	"github.com/donutloop/xservice/framework/xcontext"
-	"github.com/donutloop/xservice/framework/xhttp"
 )
 
-// //[HelloWorldPathPrefix HelloWorld] is used for all URL paths on a %!s(MISSING) server.
-//Requests are always: POST [HelloWorldPathPrefix] /method
-//It can be used in an HTTP mux to route requests
+// HelloWorldPathPrefix is used for all URL paths on a HelloWorld server.
+// Requests are always: POST HelloWorldPathPrefix /method
+// It can be used in an HTTP mux to route requests
 const HelloWorldPathPrefix string = "/xservice/example.helloworld.HelloWorld/"
 
-// 143 bytes of a gzipped FileDescriptorProto
-var xserviceFileDescriptor0 = []byte{0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0xc8, 0x48, 0xcd, 0xc9, 0xc9, 0x2f, 0xcf, 0x2f, 0xca, 0x49, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x12, 0x4a, 0xad, 0x48, 0xcc, 0x2d, 0xc8, 0x49, 0xd5, 0x43, 0xc8, 0x28, 0xa9, 0x70, 0x71, 0x78, 0x80, 0x78, 0x41, 0xa9, 0x85, 0x42, 0x12, 0x5c, 0xec, 0xc5, 0xa5, 0x49, 0x59, 0xa9, 0xc9, 0x25, 0x12, 0x8c, 0x0a, 0x8c, 0x1a, 0x9c, 0x41, 0x30, 0xae, 0x92, 0x3c, 0x17, 0x27, 0x54, 0x55, 0x71, 0x81, 0x90, 0x10, 0x17, 0x4b, 0x49, 0x6a, 0x05, 0x4c, 0x0d, 0x98, 0x6d, 0x14, 0xc4, 0xc5, 0x05, 0x56, 0x10, 0x0e, 0x32, 0x54, 0xc8, 0x85, 0x8b, 0x15, 0xcc, 0x13, 0x92, 0xd1, 0xc3, 0xb4, 0x52, 0x0f, 0x66, 0x9f, 0x94, 0x2c, 0x1e, 0xd9, 0xe2, 0x02, 0x27, 0x9e, 0x28, 0x2e, 0x84, 0x78, 0x12, 0x1b, 0xd8, 0x0f, 0xc6, 0x80, 0x00, 0x00, 0x00, 0xff, 0xff, 0x65, 0x34, 0xd5, 0xb9, 0xd7, 0x00, 0x00, 0x00}
+// 145 bytes of a gzipped FileDescriptorProto
+var xserviceFileDescriptor_17b8c58d586b62f2 = []byte{0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x12, 0xc8, 0x48, 0xcd, 0xc9, 0xc9, 0x2f, 0xcf, 0x2f, 0xca, 0x49, 0xd1, 0x2b, 0x28, 0xca, 0x2f, 0xc9, 0x17, 0x12, 0x4a, 0xad, 0x48, 0xcc, 0x2d, 0xc8, 0x49, 0xd5, 0x43, 0xc8, 0x28, 0xa9, 0x70, 0x71, 0x78, 0x80, 0x78, 0x41, 0xa9, 0x85, 0x42, 0x12, 0x5c, 0xec, 0xc5, 0xa5, 0x49, 0x59, 0xa9, 0xc9, 0x25, 0x12, 0x8c, 0x0a, 0x8c, 0x1a, 0x9c, 0x41, 0x30, 0xae, 0x92, 0x3c, 0x17, 0x27, 0x54, 0x55, 0x71, 0x81, 0x90, 0x10, 0x17, 0x4b, 0x49, 0x6a, 0x05, 0x4c, 0x0d, 0x98, 0x6d, 0x14, 0xce, 0xc5, 0x05, 0x56, 0x10, 0x0e, 0x32, 0x54, 0xc8, 0x93, 0x8b, 0x15, 0xcc, 0x13, 0x92, 0xd1, 0xc3, 0xb4, 0x52, 0x0f, 0x66, 0x9f, 0x94, 0x2c, 0x1e, 0xd9, 0xe2, 0x02, 0x25, 0xe6, 0x09, 0x4c, 0x8c, 0x4e, 0x3c, 0x51, 0x5c, 0x08, 0xc9, 0x24, 0x36, 0xb0, 0x47, 0x8c, 0x01, 0x03, 0x00, 0x40, 0xa5, 0x16, 0x87, 0xdc, 0x00, 0x00, 0x00}
 
 type HelloWorld interface {
 	Hello(ctx context.Context, req *HelloReq) (*HelloResp, error)
@@ -37,8 +34,9 @@ type HelloWorld interface {
 
 // helloWorldJSONClient wraps an http.client and sends JSON objects
 type helloWorldJSONClient struct {
-	client transport.HTTPClient
-	urls   [1]string
+	client  transport.HTTPClient
+	urls    [1]string
+	options *transport.ClientOptions
 }
 
 // Hello sends an HelloReq JSON object to the server
@@ -46,15 +44,21 @@ func (c *helloWorldJSONClient) Hello(ctx context.Context, in *HelloReq) (*HelloR
 	ctx = xcontext.WithPackageName(ctx, "example.helloworld")
 	ctx = xcontext.WithServiceName(ctx, "HelloWorld")
 	ctx = xcontext.WithMethodName(ctx, "Hello")
-	out := new(HelloResp)
-	err := transport.DoJSONRequest(ctx, c.client, c.urls[0], in, out)
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(HelloResp)
+		err := c.options.DoIdempotentJSONRequest(ctx, c.client, c.urls[0], req.(*HelloReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*HelloResp)
 	return out, err
 }
 
 // helloWorldProtobufferClient wraps an http.client and sends Protobuffer objects
 type helloWorldProtobufferClient struct {
-	client transport.HTTPClient
-	urls   [1]string
+	client  transport.HTTPClient
+	urls    [1]string
+	options *transport.ClientOptions
 }
 
 // Hello sends an HelloReq Protobuffer object to the server
@@ -62,8 +66,30 @@ func (c *helloWorldProtobufferClient) Hello(ctx context.Context, in *HelloReq) (
 	ctx = xcontext.WithPackageName(ctx, "example.helloworld")
 	ctx = xcontext.WithServiceName(ctx, "HelloWorld")
 	ctx = xcontext.WithMethodName(ctx, "Hello")
-	out := new(HelloResp)
-	err := transport.DoProtobufferRequest(ctx, c.client, c.urls[0], in, out)
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(HelloResp)
+		err := c.options.DoIdempotentProtobufferRequest(ctx, c.client, c.urls[0], req.(*HelloReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*HelloResp)
+	return out, err
+}
+
+// helloWorldLocalClient calls an implementation of HelloWorld in-process, with the hooks and interceptors of a server
+type helloWorldLocalClient struct {
+	svc     HelloWorld
+	options *transport.ServerOptions
+}
+
+// Hello calls Hello of the service in-process
+func (c *helloWorldLocalClient) Hello(ctx context.Context, in *HelloReq) (*HelloResp, error) {
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out, err := c.svc.Hello(ctx, req.(*HelloReq))
+		return out, err
+	}
+	resp, err := c.options.CallLocal(ctx, "example.helloworld", "HelloWorld", "Hello", in, call)
+	out, _ := resp.(*HelloResp)
 	return out, err
 }
 
@@ -72,33 +98,49 @@ type helloWorldServer struct {
 	HelloWorld
 	hooks        *hooks.ServerHooks
 	logErrorFunc transport.LogErrorFunc
+	options      *transport.ServerOptions
+	prefix       string
 }
 
 func (s *helloWorldServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
-	transport.WriteErrorAndTriggerHooks(ctx, resp, err, s.hooks)
+	s.options.WriteError(ctx, resp, err, s.hooks)
 }
 
 // ServeHTTP implements http.Handler.
 func (s *helloWorldServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	resp = transport.NewResponseWriter(resp)
 	ctx := req.Context()
 	ctx = xcontext.WithPackageName(ctx, "example.helloworld")
 	ctx = xcontext.WithServiceName(ctx, "HelloWorld")
 	ctx = xcontext.WithResponseWriter(ctx, resp)
+	ctx = xcontext.WithHTTPRequest(ctx, req)
+	s.options.LimitRequestBody(req)
+	recoverWrapper := func() {
+		if r := recover(); r != nil {
+			s.options.HandlePanic(ctx, resp, r, s.hooks)
+		}
+	}
+	defer recoverWrapper()
+
 	var err error
 	ctx, err = transport.CallRequestReceived(ctx, s.hooks)
 	if err != nil {
 		s.writeError(ctx, resp, err)
 		return
 	}
-	if req.Method != http.MethodPost {
-		msg := fmt.Sprintf("unsupported method %q (only POST is allowed)", req.Method)
-		terr := errors.BadRouteError(msg, req.Method, req.URL.Path)
-		s.writeError(ctx, resp, terr)
+	err = s.options.CheckVersion(resp, req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	err = s.options.CheckMethod(req, true)
+	if err != nil {
+		s.writeError(ctx, resp, err)
 		return
 	}
 
 	switch req.URL.Path {
-	case "/xservice/example.helloworld.HelloWorld/Hello":
+	case s.prefix + "Hello":
 		s.serveHello(ctx, resp, req)
 		return
 
@@ -113,24 +155,28 @@ func (s *helloWorldServer) ServeHTTP(resp http.ResponseWriter, req *http.Request
 
 // serveHello is used to set an decoder and encoder for a given content type
 func (s *helloWorldServer) serveHello(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
-	header := req.Header.Get(xhttp.ContentTypeHeader)
-	i := strings.Index(header, ";")
-	if i == -1 {
-		i = len(header)
-	}
-	modifiedHeader := strings.ToLower(header[:i])
-	modifiedHeader = strings.TrimSpace(modifiedHeader)
-	if modifiedHeader == xhttp.ApplicationJson {
-		s.serveHelloContent(ctx, resp, req, transport.DecodeJSONRequest, transport.EncodeJSONResponse)
+	if req.Method == http.MethodGet {
+		codec, err := s.options.ResponseCodec(req, nil)
+		if err != nil {
+			s.writeError(ctx, resp, err)
+			return
+		}
+		ctx = xcontext.WithResponseContentType(ctx, codec.ContentType())
+		s.serveHelloContent(ctx, resp, req, transport.DecodeQueryRequest, transport.NewGetResponseEncoderWith(req, codec))
 		return
-	} else if modifiedHeader == xhttp.ApplicationProtobuf {
-		s.serveHelloContent(ctx, resp, req, transport.DecodePROTORequest, transport.EncodePROTOResponse)
+	}
+	codec, err := s.options.RequestCodec(req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
 		return
-	} else {
-		msg := fmt.Sprintf("unexpected Content-Type: %q", header)
-		terr := errors.BadRouteError(msg, req.Method, req.URL.Path)
-		s.writeError(ctx, resp, terr)
 	}
+	respCodec, err := s.options.ResponseCodec(req, codec)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	ctx = xcontext.WithResponseContentType(ctx, respCodec.ContentType())
+	s.serveHelloContent(ctx, resp, req, transport.DecodeRequestWith(codec), transport.EncodeResponseWith(respCodec))
 }
 
 // serveHelloContent sends object to requester
@@ -150,18 +196,21 @@ func (s *helloWorldServer) serveHelloContent(ctx context.Context, resp http.Resp
 		s.writeError(ctx, resp, err)
 		return
 	}
-	endpointWrapper := func() (*HelloResp, error) {
+	endpointWrapper := func() (respContent *HelloResp, err error) {
 		deferWrapper := func() {
 			if r := recover(); r != nil {
-				terr := errors.InternalError("Internal service panic")
-				s.writeError(ctx, resp, terr)
-				panic(r)
+				err = s.options.RecoverPanic(ctx, r)
 			}
 		}
 		defer deferWrapper()
 
-		return s.Hello(ctx, reqContent)
-
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			out, err := s.Hello(ctx, req.(*HelloReq))
+			return out, err
+		}
+		out, callErr := s.options.Intercept(call)(ctx, reqContent)
+		content, _ := out.(*HelloResp)
+		return content, callErr
 	}
 	respContent, err := endpointWrapper()
 	if err != nil {
@@ -185,7 +234,7 @@ func (s *helloWorldServer) serveHelloContent(ctx context.Context, resp http.Resp
 
 // ServiceDescriptor describes an service.
 func (s *helloWorldServer) ServiceDescriptor() ([]uint8, int) {
-	return xserviceFileDescriptor0, 0
+	return xserviceFileDescriptor_17b8c58d586b62f2, 0
 }
 
 // ProtocGenXServiceVersion returns which xservice version was used to generate that service
@@ -195,56 +244,80 @@ func (s *helloWorldServer) ProtocGenXServiceVersion() string {
 
 // NewHelloWorldJSONClient constructs a new client, which wraps the http.client and implements HelloWorld
 func NewHelloWorldJSONClient(addr string, client transport.HTTPClient) HelloWorld {
+	return NewHelloWorldJSONClientWithOptions(addr, client)
+}
+
+// NewHelloWorldJSONClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements HelloWorld
+func NewHelloWorldJSONClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) HelloWorld {
+	options := transport.NewClientOptions(opts...)
 	URLBase := transport.UrlBase(addr)
-	prefix := URLBase + HelloWorldPathPrefix
+	prefix := URLBase + options.ServicePathPrefix(HelloWorldPathPrefix, "example.helloworld.HelloWorld")
 	urls := [1]string{
 		prefix + "Hello",
 	}
-	httpClient, ok := client.(*http.Client)
-	if ok == true {
-		httpClient = transport.WithoutRedirects(httpClient)
-		return &helloWorldJSONClient{
-			client: httpClient,
-			urls:   urls,
-		}
-	}
 	return &helloWorldJSONClient{
-		client: client,
-		urls:   urls,
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
 	}
 }
 
 // NewHelloWorldProtobufferClient constructs a new client, which wraps the http.client and implements HelloWorld
 func NewHelloWorldProtobufferClient(addr string, client transport.HTTPClient) HelloWorld {
+	return NewHelloWorldProtobufferClientWithOptions(addr, client)
+}
+
+// NewHelloWorldProtobufferClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements HelloWorld
+func NewHelloWorldProtobufferClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) HelloWorld {
+	options := transport.NewClientOptions(opts...)
 	URLBase := transport.UrlBase(addr)
-	prefix := URLBase + HelloWorldPathPrefix
+	prefix := URLBase + options.ServicePathPrefix(HelloWorldPathPrefix, "example.helloworld.HelloWorld")
 	urls := [1]string{
 		prefix + "Hello",
 	}
-	httpClient, ok := client.(*http.Client)
-	if ok == true {
-		httpClient = transport.WithoutRedirects(httpClient)
-		return &helloWorldProtobufferClient{
-			client: httpClient,
-			urls:   urls,
-		}
-	}
 	return &helloWorldProtobufferClient{
-		client: client,
-		urls:   urls,
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
+	}
+}
+
+// NewHelloWorldLocalClient constructs a client which implements HelloWorld by calling svc in-process. It calls the hooks
+// and populates the context like NewHelloWorldServer.
+func NewHelloWorldLocalClient(svc HelloWorld, hooks *hooks.ServerHooks) HelloWorld {
+	return NewHelloWorldLocalClientWithOptions(svc, transport.WithServerHooks(hooks))
+}
+
+// NewHelloWorldLocalClientWithOptions constructs a client like NewHelloWorldLocalClient, configured by server options. With transport.WithLocalRoundTrip svc receives and
+// returns copies of the messages.
+func NewHelloWorldLocalClientWithOptions(svc HelloWorld, opts ...transport.ServerOption) HelloWorld {
+	return &helloWorldLocalClient{
+		svc:     svc,
+		options: transport.NewServerOptions(opts...),
 	}
 }
 
 // NewHelloWorldServer constructs a new server, and implements HelloWorld
 func NewHelloWorldServer(svc HelloWorld, hooks *hooks.ServerHooks, errorFunc ...transport.LogErrorFunc) server.Server {
-	server := &helloWorldServer{
-		HelloWorld: svc,
-		hooks:      hooks,
-	}
+	opts := make([]transport.ServerOption, 0, 2)
+	opts = append(opts, transport.WithServerHooks(hooks))
 	if len(errorFunc) == 1 {
-		server.logErrorFunc = errorFunc[0]
-	} else {
-		server.logErrorFunc = log.Printf
+		opts = append(opts, transport.WithServerLogger(errorFunc[0]))
+	}
+	return NewHelloWorldServerWithOptions(svc, opts...)
+}
+
+// NewHelloWorldServerWithOptions constructs a new server configured by opts, and implements HelloWorld
+func NewHelloWorldServerWithOptions(svc HelloWorld, opts ...transport.ServerOption) server.Server {
+	options := transport.NewServerOptions(opts...)
+	return &helloWorldServer{
+		HelloWorld:   svc,
+		hooks:        options.Hooks(),
+		logErrorFunc: options.Logger(),
+		options:      options,
+		prefix:       options.ServicePathPrefix(HelloWorldPathPrefix, "example.helloworld.HelloWorld"),
 	}
-	return server
+}
+func init() {
+	server.RegisterServiceDescriptor("example.helloworld.HelloWorld", xserviceFileDescriptor_17b8c58d586b62f2, 0)
 }
@@ -0,0 +1,121 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package helloworld_test
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"github.com/donutloop/xservice/integration_tests/api_hello_world"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHelloWorldResponseSentHook(t *testing.T) {
+	var status string
+	var bytesWritten int64
+	var contentType string
+	server := httptest.NewServer(helloworld.NewHelloWorldServerWithOptions(&HelloWorldServer{}, transport.WithServerHooks(&hooks.ServerHooks{
+		ResponseSent: func(ctx context.Context) {
+			status, _ = xcontext.StatusCode(ctx)
+			bytesWritten, _ = xcontext.ResponseBytesWritten(ctx)
+			header, _ := xcontext.ResponseHeaders(ctx)
+			contentType = header.Get("Content-Type")
+		},
+	})))
+	defer server.Close()
+
+	client := helloworld.NewHelloWorldJSONClient(server.URL, &http.Client{})
+	if _, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if status != "200" {
+		t.Fatalf(`unexpected status code (actual: "%s", expected: "200")`, status)
+	}
+	if bytesWritten != int64(len(`{"text":"Hello World"}`)) {
+		t.Fatalf("unexpected number of bytes written (actual: %d)", bytesWritten)
+	}
+	if contentType != "application/json" {
+		t.Fatalf(`unexpected content type (actual: "%s", expected: "application/json")`, contentType)
+	}
+}
+
+func TestHelloWorldPanicAfterResponse(t *testing.T) {
+	var panics int
+	handler := helloworld.NewHelloWorldServerWithOptions(&HelloWorldServer{},
+		transport.WithServerHooks(&hooks.ServerHooks{
+			ResponseSent: func(ctx context.Context) {
+				panic("hook panic")
+			},
+		}),
+		transport.WithServerPanicHandler(func(ctx context.Context, p *transport.Panic) {
+			panics++
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, helloworld.HelloWorldPathPrefix+"Hello", strings.NewReader(`{"subject":"World"}`))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if panics != 1 {
+		t.Fatalf("unexpected number of handled panics (actual: %d, expected: 1)", panics)
+	}
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("unexpected status code (actual: %d, expected: %d)", recorder.Code, http.StatusOK)
+	}
+	body, _ := ioutil.ReadAll(recorder.Body)
+	if string(body) != `{"text":"Hello World"}` {
+		t.Fatalf("unexpected body (actual: %s)", body)
+	}
+}
+
+func TestHelloWorldClientVersionHook(t *testing.T) {
+	var versions []string
+	handler := helloworld.NewHelloWorldServerWithOptions(&HelloWorldServer{}, transport.WithServerHooks(&hooks.ServerHooks{
+		RequestReceived: func(ctx context.Context) (context.Context, error) {
+			version, _ := xcontext.ClientVersion(ctx)
+			versions = append(versions, version)
+			return ctx, nil
+		},
+	}))
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	client := helloworld.NewHelloWorldJSONClientWithOptions(server.URL, &http.Client{}, transport.WithClientVersionCheck())
+	if _, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"}); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, helloworld.HelloWorldPathPrefix+"Hello", strings.NewReader(`{"subject":"World"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("XService-Version", "v1.0.0")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+
+	if recorder.Code != http.StatusPreconditionFailed {
+		t.Fatalf("unexpected status code of an incompatible client (actual: %d, expected: %d)", recorder.Code, http.StatusPreconditionFailed)
+	}
+	if recorder.Header().Get("XService-Version") != transport.ProtocolVersion {
+		t.Fatalf(`unexpected server version (actual: "%s")`, recorder.Header().Get("XService-Version"))
+	}
+	if len(versions) != 2 || versions[0] != transport.ProtocolVersion || versions[1] != "v1.0.0" {
+		t.Fatalf("unexpected client versions (actual: %v)", versions)
+	}
+}
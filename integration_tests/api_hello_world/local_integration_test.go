@@ -0,0 +1,186 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package helloworld_test
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"github.com/donutloop/xservice/integration_tests/api_hello_world"
+	"reflect"
+	"testing"
+)
+
+// aliasingHelloWorldServer modifies its request and returns it in its
+// response.
+type aliasingHelloWorldServer struct {
+	resp *helloworld.HelloResp
+}
+
+func (s *aliasingHelloWorldServer) Hello(ctx context.Context, req *helloworld.HelloReq) (*helloworld.HelloResp, error) {
+	req.Subject = "modified"
+	s.resp = &helloworld.HelloResp{Text: "Hello " + req.Subject}
+	return s.resp, nil
+}
+
+func TestHelloWorldLocalClient(t *testing.T) {
+	var calls []string
+	var names []string
+	var status string
+	client := helloworld.NewHelloWorldLocalClient(&HelloWorldServer{}, &hooks.ServerHooks{
+		RequestReceived: func(ctx context.Context) (context.Context, error) {
+			calls = append(calls, "RequestReceived")
+			return ctx, nil
+		},
+		RequestRouted: func(ctx context.Context) (context.Context, error) {
+			calls = append(calls, "RequestRouted")
+			pkg, _ := xcontext.PackageName(ctx)
+			service, _ := xcontext.ServiceName(ctx)
+			method, _ := xcontext.MethodName(ctx)
+			names = []string{pkg, service, method}
+			return ctx, nil
+		},
+		ResponsePrepared: func(ctx context.Context) context.Context {
+			calls = append(calls, "ResponsePrepared")
+			return ctx
+		},
+		ResponseSent: func(ctx context.Context) {
+			calls = append(calls, "ResponseSent")
+			status, _ = xcontext.StatusCode(ctx)
+		},
+	})
+
+	resp, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.Text != "Hello World" {
+		t.Fatalf(`unexpected text (actual: "%s", expected: "Hello World")`, resp.Text)
+	}
+	if expected := []string{"RequestReceived", "RequestRouted", "ResponsePrepared", "ResponseSent"}; !reflect.DeepEqual(calls, expected) {
+		t.Fatalf("unexpected hook calls (actual: %v, expected: %v)", calls, expected)
+	}
+	if expected := []string{"example.helloworld", "HelloWorld", "Hello"}; !reflect.DeepEqual(names, expected) {
+		t.Fatalf("unexpected names in the context (actual: %v, expected: %v)", names, expected)
+	}
+	if status != "200" {
+		t.Fatalf(`unexpected status code (actual: "%s", expected: "200")`, status)
+	}
+}
+
+func TestHelloWorldLocalClientErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		svc    helloworld.HelloWorld
+		code   errors.ErrorCode
+		status string
+	}{
+		{
+			name:   "error",
+			svc:    &quotaHelloWorldServer{},
+			code:   errors.ResourceExhausted,
+			status: "403",
+		},
+		{
+			name:   "panic",
+			svc:    &panickingHelloWorldServer{},
+			code:   errors.Internal,
+			status: "500",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var hookErr errors.Error
+			var status string
+			client := helloworld.NewHelloWorldLocalClientWithOptions(test.svc,
+				transport.WithServerHooks(&hooks.ServerHooks{
+					Error: func(ctx context.Context, err errors.Error) context.Context {
+						hookErr = err
+						return ctx
+					},
+					ResponseSent: func(ctx context.Context) {
+						status, _ = xcontext.StatusCode(ctx)
+					},
+				}),
+				transport.WithServerPanicHandler(func(ctx context.Context, p *transport.Panic) {}),
+			)
+
+			resp, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "quota"})
+			if resp != nil {
+				t.Fatalf("unexpected response (actual: %v)", resp)
+			}
+			xerr, ok := err.(errors.Error)
+			if !ok {
+				t.Fatalf("unexpected error (actual: %v)", err)
+			}
+			if xerr.Code() != test.code {
+				t.Fatalf(`unexpected error code (actual: "%s", expected: "%s")`, xerr.Code(), test.code)
+			}
+			if hookErr == nil || hookErr.Code() != test.code {
+				t.Fatalf("unexpected error of the hook (actual: %v)", hookErr)
+			}
+			if status != test.status {
+				t.Fatalf(`unexpected status code (actual: "%s", expected: "%s")`, status, test.status)
+			}
+		})
+	}
+}
+
+func TestHelloWorldLocalRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []transport.ServerOption
+		aliased bool
+	}{
+		{
+			name:    "without round trip",
+			aliased: true,
+		},
+		{
+			name: "json",
+			opts: []transport.ServerOption{transport.WithLocalRoundTrip(transport.NewJSONCodec())},
+		},
+		{
+			name: "protobuf",
+			opts: []transport.ServerOption{transport.WithLocalRoundTrip(transport.ProtobufCodec{})},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			svc := &aliasingHelloWorldServer{}
+			client := helloworld.NewHelloWorldLocalClientWithOptions(svc, test.opts...)
+
+			req := &helloworld.HelloReq{Subject: "World"}
+			resp, err := client.Hello(context.Background(), req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if resp.Text != "Hello modified" {
+				t.Fatalf(`unexpected text (actual: "%s", expected: "Hello modified")`, resp.Text)
+			}
+			if aliased := req.Subject == "modified"; aliased != test.aliased {
+				t.Fatalf(`unexpected subject of the request (actual: "%s")`, req.Subject)
+			}
+			if aliased := resp == svc.resp; aliased != test.aliased {
+				t.Fatalf("unexpected aliasing of the response (actual: %t, expected: %t)", aliased, test.aliased)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package helloworld_test
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/integration_tests/api_hello_world"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type panickingHelloWorldServer struct{}
+
+func (s *panickingHelloWorldServer) Hello(ctx context.Context, req *helloworld.HelloReq) (*helloworld.HelloResp, error) {
+	panic("handler panic")
+}
+
+func TestHelloWorldPanics(t *testing.T) {
+	tests := []struct {
+		name  string
+		svc   helloworld.HelloWorld
+		hooks *hooks.ServerHooks
+		value string
+	}{
+		{
+			name:  "handler",
+			svc:   &panickingHelloWorldServer{},
+			value: "handler panic",
+		},
+		{
+			name: "RequestReceived hook",
+			svc:  &HelloWorldServer{},
+			hooks: &hooks.ServerHooks{
+				RequestReceived: func(ctx context.Context) (context.Context, error) {
+					panic("hook panic")
+				},
+			},
+			value: "hook panic",
+		},
+		{
+			name: "RequestRouted hook",
+			svc:  &HelloWorldServer{},
+			hooks: &hooks.ServerHooks{
+				RequestRouted: func(ctx context.Context) (context.Context, error) {
+					panic("hook panic")
+				},
+			},
+			value: "hook panic",
+		},
+	}
+
+	for _, test := range tests {
+		var panics []*transport.Panic
+		server := httptest.NewServer(helloworld.NewHelloWorldServerWithOptions(test.svc,
+			transport.WithServerHooks(test.hooks),
+			transport.WithServerPanicHandler(func(ctx context.Context, p *transport.Panic) {
+				panics = append(panics, p)
+			}),
+		))
+
+		client := helloworld.NewHelloWorldJSONClient(server.URL, &http.Client{})
+		for i := 0; i < 2; i++ {
+			_, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"})
+			terr, ok := err.(errors.Error)
+			if !ok || terr.Code() != errors.Internal || terr.Meta(transport.PanicMetaKey) != "true" {
+				t.Fatalf("%s: unexpected error (actual: %v)", test.name, err)
+			}
+		}
+		server.Close()
+
+		if len(panics) != 2 {
+			t.Fatalf("%s: unexpected number of handled panics (actual: %d, expected: 2)", test.name, len(panics))
+		}
+		for _, p := range panics {
+			if p.Value != test.value {
+				t.Fatalf(`%s: unexpected panic value (actual: "%v", expected: "%s")`, test.name, p.Value, test.value)
+			}
+			if !strings.Contains(string(p.Stack), "runtime/debug.Stack") {
+				t.Fatalf("%s: stack trace is missing", test.name)
+			}
+		}
+	}
+}
+
+func TestHelloWorldRePanic(t *testing.T) {
+	var handled int
+	handler := helloworld.NewHelloWorldServerWithOptions(&panickingHelloWorldServer{},
+		transport.WithServerRePanic(),
+		transport.WithServerPanicHandler(func(ctx context.Context, p *transport.Panic) {
+			handled++
+		}),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, helloworld.HelloWorldPathPrefix+"Hello", strings.NewReader("{}"))
+	req.Header.Set("Content-Type", "application/json")
+
+	func() {
+		defer func() {
+			r := recover()
+			p, ok := r.(*transport.Panic)
+			if !ok || p.Value != "handler panic" {
+				t.Fatalf("unexpected panic (actual: %v)", r)
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+
+	if handled != 1 {
+		t.Fatalf("unexpected number of handled panics (actual: %d, expected: 1)", handled)
+	}
+}
+
+type abortingHelloWorldServer struct{}
+
+func (s *abortingHelloWorldServer) Hello(ctx context.Context, req *helloworld.HelloReq) (*helloworld.HelloResp, error) {
+	panic(http.ErrAbortHandler)
+}
+
+func TestHelloWorldAbortHandler(t *testing.T) {
+	tests := []struct {
+		name  string
+		svc   helloworld.HelloWorld
+		hooks *hooks.ServerHooks
+	}{
+		{
+			name: "handler",
+			svc:  &abortingHelloWorldServer{},
+		},
+		{
+			name: "RequestReceived hook",
+			svc:  &HelloWorldServer{},
+			hooks: &hooks.ServerHooks{
+				RequestReceived: func(ctx context.Context) (context.Context, error) {
+					panic(http.ErrAbortHandler)
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		var handled int
+		handler := helloworld.NewHelloWorldServerWithOptions(test.svc,
+			transport.WithServerHooks(test.hooks),
+			transport.WithServerPanicHandler(func(ctx context.Context, p *transport.Panic) {
+				handled++
+			}),
+		)
+
+		req := httptest.NewRequest(http.MethodPost, helloworld.HelloWorldPathPrefix+"Hello", strings.NewReader("{}"))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+
+		func() {
+			defer func() {
+				if r := recover(); r != http.ErrAbortHandler {
+					t.Fatalf("%s: unexpected panic (actual: %v, expected: %v)", test.name, r, http.ErrAbortHandler)
+				}
+			}()
+			handler.ServeHTTP(resp, req)
+		}()
+
+		if handled != 0 {
+			t.Fatalf("%s: aborted handler was handled as panic", test.name)
+		}
+		if resp.Body.Len() != 0 {
+			t.Fatalf("%s: unexpected response of aborted handler (actual: %s)", test.name, resp.Body.String())
+		}
+	}
+}
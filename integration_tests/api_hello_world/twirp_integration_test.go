@@ -0,0 +1,251 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package helloworld_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/integration_tests/api_hello_world"
+	"github.com/gogo/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// twirpHandler is a minimal handler for the HelloWorld service written like
+// the code generated by Twirp v7, so the compatibility mode is tested
+// against the protocol and not against xservice itself.
+type twirpHandler struct {
+	versions []string
+}
+
+type twirpError struct {
+	Code string            `json:"code"`
+	Msg  string            `json:"msg"`
+	Meta map[string]string `json:"meta,omitempty"`
+}
+
+func (h *twirpHandler) writeError(resp http.ResponseWriter, status int, terr twirpError) {
+	resp.Header().Set("Content-Type", "application/json")
+	resp.WriteHeader(status)
+	json.NewEncoder(resp).Encode(terr)
+}
+
+func (h *twirpHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	h.versions = append(h.versions, req.Header.Get("Twirp-Version")+"|"+req.Header.Get("XService-Version"))
+
+	route := req.Method + " " + req.URL.Path
+	if req.Method != http.MethodPost || req.URL.Path != "/twirp/example.helloworld.HelloWorld/Hello" {
+		h.writeError(resp, http.StatusNotFound, twirpError{Code: "bad_route", Msg: "no handler for path " + req.URL.Path, Meta: map[string]string{"twirp_invalid_route": route}})
+		return
+	}
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		h.writeError(resp, http.StatusBadRequest, twirpError{Code: "malformed", Msg: err.Error()})
+		return
+	}
+
+	in := new(helloworld.HelloReq)
+	contentType := req.Header.Get("Content-Type")
+	switch contentType {
+	case "application/json":
+		err = jsonpb.Unmarshal(bytes.NewReader(body), in)
+	case "application/protobuf":
+		err = proto.Unmarshal(body, in)
+	default:
+		h.writeError(resp, http.StatusNotFound, twirpError{Code: "bad_route", Msg: "unexpected Content-Type", Meta: map[string]string{"twirp_invalid_route": route}})
+		return
+	}
+	if err != nil {
+		h.writeError(resp, http.StatusBadRequest, twirpError{Code: "malformed", Msg: "the json request could not be decoded"})
+		return
+	}
+
+	if in.Subject == "quota" {
+		h.writeError(resp, http.StatusTooManyRequests, twirpError{Code: "resource_exhausted", Msg: "quota exceeded", Meta: map[string]string{"retry_after": "1s"}})
+		return
+	}
+
+	out := &helloworld.HelloResp{Text: "Hello " + in.Subject}
+	resp.Header().Set("Content-Type", contentType)
+	if contentType == "application/json" {
+		marshaler := &jsonpb.Marshaler{OrigName: true, EmitDefaults: true}
+		marshaler.Marshal(resp, out)
+		return
+	}
+	b, _ := proto.Marshal(out)
+	resp.Write(b)
+}
+
+func TestTwirpClientCompatibility(t *testing.T) {
+	handler := &twirpHandler{}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	clients := []helloworld.HelloWorld{
+		helloworld.NewHelloWorldJSONClientWithOptions(server.URL, &http.Client{}, transport.WithClientTwirpCompatibility()),
+		helloworld.NewHelloWorldProtobufferClientWithOptions(server.URL, &http.Client{}, transport.WithClientTwirpCompatibility()),
+	}
+
+	for _, client := range clients {
+		resp, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Text != "Hello World" {
+			t.Fatalf(`unexpected text (actual: "%s", expected: "%s")`, resp.Text, "Hello World")
+		}
+
+		_, err = client.Hello(context.Background(), &helloworld.HelloReq{Subject: "quota"})
+		terr, ok := err.(errors.Error)
+		if !ok {
+			t.Fatalf("unexpected error type (actual: %T)", err)
+		}
+		if terr.Code() != errors.ResourceExhausted || terr.Meta("retry_after") != "1s" {
+			t.Fatalf("unexpected error (actual: %v, meta: %v)", terr, terr.MetaMap())
+		}
+	}
+
+	for _, version := range handler.versions {
+		if version != transport.TwirpVersion+"|" {
+			t.Fatalf(`unexpected version headers (actual: "%s", expected: "%s|")`, version, transport.TwirpVersion)
+		}
+	}
+}
+
+type quotaHelloWorldServer struct{}
+
+func (s *quotaHelloWorldServer) Hello(ctx context.Context, req *helloworld.HelloReq) (*helloworld.HelloResp, error) {
+	switch req.Subject {
+	case "quota":
+		return nil, errors.NewError(errors.ResourceExhausted, "quota exceeded")
+	case "":
+		return &helloworld.HelloResp{}, nil
+	}
+	return &helloworld.HelloResp{Text: "Hello " + req.Subject}, nil
+}
+
+func TestTwirpServerCompatibility(t *testing.T) {
+	server := httptest.NewServer(helloworld.NewHelloWorldServerWithOptions(&quotaHelloWorldServer{}, transport.WithServerTwirpCompatibility()))
+	defer server.Close()
+
+	tests := []struct {
+		method     string
+		path       string
+		body       string
+		accept     string
+		statusCode int
+		response   string
+	}{
+		{
+			path:       "/twirp/example.helloworld.HelloWorld/Hello",
+			body:       `{"subject":"World"}`,
+			statusCode: http.StatusOK,
+			response:   `{"text":"Hello World"}`,
+		},
+		{
+			path:       "/twirp/example.helloworld.HelloWorld/Hello",
+			body:       `{}`,
+			statusCode: http.StatusOK,
+			response:   `{"text":""}`,
+		},
+		{
+			path:       "/twirp/example.helloworld.HelloWorld/Hello",
+			body:       `{"subject":"quota"}`,
+			statusCode: http.StatusTooManyRequests,
+			response:   `{"code":"resource_exhausted","msg":"quota exceeded"}`,
+		},
+		{
+			path:       "/twirp/example.helloworld.HelloWorld/Goodbye",
+			body:       `{}`,
+			statusCode: http.StatusNotFound,
+			response:   `{"code":"bad_route","msg":"no handler for path \"/twirp/example.helloworld.HelloWorld/Goodbye\"","meta":{"twirp_invalid_route":"POST /twirp/example.helloworld.HelloWorld/Goodbye"}}`,
+		},
+		{
+			path:       "/twirp/example.helloworld.HelloWorld/Hello",
+			body:       `{"subject":"World"}`,
+			accept:     "text/html",
+			statusCode: http.StatusOK,
+			response:   `{"text":"Hello World"}`,
+		},
+		{
+			path:       "/twirp/example.helloworld.HelloWorld/Hello",
+			body:       `{"subject":`,
+			statusCode: http.StatusBadRequest,
+			response:   `{"code":"malformed","msg":"failed to parse request json: unexpected EOF","meta":{"json_offset":"11"}}`,
+		},
+		{
+			// Twirp doesn't support GET, not even for side effect free
+			// methods.
+			method:     http.MethodGet,
+			path:       "/twirp/example.helloworld.HelloWorld/Hello?subject=World",
+			statusCode: http.StatusNotFound,
+			response:   `{"code":"bad_route","msg":"unsupported method \"GET\" (only POST is allowed)","meta":{"twirp_invalid_route":"GET /twirp/example.helloworld.HelloWorld/Hello"}}`,
+		},
+	}
+
+	for _, test := range tests {
+		method := test.method
+		if method == "" {
+			method = http.MethodPost
+		}
+		req, err := http.NewRequest(method, server.URL+test.path, strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Twirp-Version", transport.TwirpVersion)
+		if test.accept != "" {
+			req.Header.Set("Accept", test.accept)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if resp.StatusCode != test.statusCode {
+			t.Errorf("%s %s: unexpected status code (actual: %d, expected: %d)", test.path, test.body, resp.StatusCode, test.statusCode)
+		}
+		if strings.TrimSpace(string(body)) != test.response {
+			t.Errorf("%s %s: unexpected response (actual: %s, expected: %s)", test.path, test.body, body, test.response)
+		}
+	}
+}
+
+func TestTwirpRoundTrip(t *testing.T) {
+	server := httptest.NewServer(helloworld.NewHelloWorldServerWithOptions(&quotaHelloWorldServer{}, transport.WithServerTwirpCompatibility()))
+	defer server.Close()
+
+	client := helloworld.NewHelloWorldProtobufferClientWithOptions(server.URL, &http.Client{}, transport.WithClientTwirpCompatibility())
+	resp, err := client.Hello(context.Background(), &helloworld.HelloReq{Subject: "World"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Text != "Hello World" {
+		t.Fatalf(`unexpected text (actual: "%s", expected: "%s")`, resp.Text, "Hello World")
+	}
+}
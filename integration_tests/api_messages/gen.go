@@ -0,0 +1,4 @@
+package messages
+
+// The messages are generated by xservice, protoc-gen-go isn't needed.
+//go:generate protoc -I . ./messages.proto --xservice_out=messages=true,Mgoogle/protobuf/timestamp.proto=github.com/gogo/protobuf/types:.
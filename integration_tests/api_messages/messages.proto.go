@@ -0,0 +1,826 @@
+// Code generated by xproto v0.1.0, DO NOT EDIT.
+// source: messages.proto
+// Package messages is a generated stub package.
+// This code was generated with github.com/donutloop/xservice v0.1.0
+// It is generated from these files:
+// 	 messages.proto
+// package messages
+
+package messages
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/server"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"github.com/gogo/protobuf/proto"
+	types "github.com/gogo/protobuf/types"
+)
+
+// CatalogPathPrefix is used for all URL paths on a Catalog server.
+// Requests are always: POST CatalogPathPrefix /method
+// It can be used in an HTTP mux to route requests
+const CatalogPathPrefix string = "/xservice/example.messages.Catalog/"
+
+type Color int32
+
+const (
+	Color_RED   Color = 0
+	Color_GREEN Color = 1
+	Color_BLUE  Color = 2
+)
+
+var Color_name = map[int32]string{
+	0: "RED",
+	1: "GREEN",
+	2: "BLUE",
+}
+
+var Color_value = map[string]int32{
+	"RED":   0,
+	"GREEN": 1,
+	"BLUE":  2,
+}
+
+func (x Color) String() string {
+	return proto.EnumName(Color_name, int32(x))
+}
+
+func (Color) EnumDescriptor() ([]byte, []int) {
+	return xserviceFileDescriptor_4dc296cbfe5ffcd5, []int{0}
+}
+
+type Item_Kind int32
+
+const (
+	Item_KIND_UNKNOWN Item_Kind = 0
+	Item_KIND_BOOK    Item_Kind = 1
+)
+
+var Item_Kind_name = map[int32]string{
+	0: "KIND_UNKNOWN",
+	1: "KIND_BOOK",
+}
+
+var Item_Kind_value = map[string]int32{
+	"KIND_UNKNOWN": 0,
+	"KIND_BOOK":    1,
+}
+
+func (x Item_Kind) String() string {
+	return proto.EnumName(Item_Kind_name, int32(x))
+}
+
+func (Item_Kind) EnumDescriptor() ([]byte, []int) {
+	return xserviceFileDescriptor_4dc296cbfe5ffcd5, []int{0, 0}
+}
+
+type isItem_Price interface {
+	isItem_Price()
+}
+
+type Item_Cents struct {
+	Cents int64 `protobuf:"varint,9,opt,name=cents,proto3,oneof"`
+}
+
+func (*Item_Cents) isItem_Price() {}
+
+type Item_Note struct {
+	Note string `protobuf:"bytes,10,opt,name=note,proto3,oneof"`
+}
+
+func (*Item_Note) isItem_Price() {}
+
+type Item_Box struct {
+	Box *Item_Dimensions `protobuf:"bytes,11,opt,name=box,proto3,oneof"`
+}
+
+func (*Item_Box) isItem_Price() {}
+
+type Item_Free struct {
+	Free bool `protobuf:"varint,12,opt,name=free,proto3,oneof"`
+}
+
+func (*Item_Free) isItem_Price() {}
+
+type Item_Ratio struct {
+	Ratio float64 `protobuf:"fixed64,13,opt,name=ratio,proto3,oneof"`
+}
+
+func (*Item_Ratio) isItem_Price() {}
+
+type Item_Delta struct {
+	Delta int32 `protobuf:"zigzag32,14,opt,name=delta,proto3,oneof"`
+}
+
+func (*Item_Delta) isItem_Price() {}
+
+type Item_Tint struct {
+	Tint Color `protobuf:"varint,15,opt,name=tint,proto3,enum=example.messages.Color,oneof"`
+}
+
+func (*Item_Tint) isItem_Price() {}
+
+// XXX_OneofFuncs is for the internal use of the proto package.
+func (*Item) XXX_OneofFuncs() (func(msg proto.Message, b *proto.Buffer) error, func(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error), func(msg proto.Message) (n int), []interface{}) {
+	return _Item_OneofMarshaler, _Item_OneofUnmarshaler, _Item_OneofSizer, []interface{}{
+		(*Item_Cents)(nil),
+		(*Item_Note)(nil),
+		(*Item_Box)(nil),
+		(*Item_Free)(nil),
+		(*Item_Ratio)(nil),
+		(*Item_Delta)(nil),
+		(*Item_Tint)(nil),
+	}
+}
+
+func _Item_OneofMarshaler(msg proto.Message, b *proto.Buffer) error {
+	m := msg.(*Item)
+	switch x := m.Price.(type) {
+	case *Item_Cents:
+		b.EncodeVarint(9<<3 | proto.WireVarint)
+		b.EncodeVarint(uint64(x.Cents))
+	case *Item_Note:
+		b.EncodeVarint(10<<3 | proto.WireBytes)
+		b.EncodeStringBytes(x.Note)
+	case *Item_Box:
+		b.EncodeVarint(11<<3 | proto.WireBytes)
+		if err := b.EncodeMessage(x.Box); err != nil {
+			return err
+		}
+	case *Item_Free:
+		b.EncodeVarint(12<<3 | proto.WireVarint)
+		t := uint64(0)
+		if x.Free {
+			t = 1
+		}
+		b.EncodeVarint(t)
+	case *Item_Ratio:
+		b.EncodeVarint(13<<3 | proto.WireFixed64)
+		b.EncodeFixed64(math.Float64bits(x.Ratio))
+	case *Item_Delta:
+		b.EncodeVarint(14<<3 | proto.WireVarint)
+		b.EncodeZigzag32(uint64(x.Delta))
+	case *Item_Tint:
+		b.EncodeVarint(15<<3 | proto.WireVarint)
+		b.EncodeVarint(uint64(x.Tint))
+	case nil:
+	default:
+		return fmt.Errorf("Item.Price has unexpected type %T", x)
+	}
+	return nil
+}
+
+func _Item_OneofUnmarshaler(msg proto.Message, tag, wire int, b *proto.Buffer) (bool, error) {
+	m := msg.(*Item)
+	switch tag {
+	case 9: // price.cents
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		d, err := b.DecodeVarint()
+		x := int64(d)
+		m.Price = &Item_Cents{x}
+		return true, err
+	case 10: // price.note
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x, err := b.DecodeStringBytes()
+		m.Price = &Item_Note{x}
+		return true, err
+	case 11: // price.box
+		if wire != proto.WireBytes {
+			return true, proto.ErrInternalBadWireType
+		}
+		x := new(Item_Dimensions)
+		err := b.DecodeMessage(x)
+		m.Price = &Item_Box{x}
+		return true, err
+	case 12: // price.free
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		d, err := b.DecodeVarint()
+		x := d != 0
+		m.Price = &Item_Free{x}
+		return true, err
+	case 13: // price.ratio
+		if wire != proto.WireFixed64 {
+			return true, proto.ErrInternalBadWireType
+		}
+		d, err := b.DecodeFixed64()
+		x := math.Float64frombits(d)
+		m.Price = &Item_Ratio{x}
+		return true, err
+	case 14: // price.delta
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		d, err := b.DecodeZigzag32()
+		x := int32(d)
+		m.Price = &Item_Delta{x}
+		return true, err
+	case 15: // price.tint
+		if wire != proto.WireVarint {
+			return true, proto.ErrInternalBadWireType
+		}
+		d, err := b.DecodeVarint()
+		x := Color(d)
+		m.Price = &Item_Tint{x}
+		return true, err
+	default:
+		return false, nil
+	}
+}
+
+func _Item_OneofSizer(msg proto.Message) (n int) {
+	m := msg.(*Item)
+	switch x := m.Price.(type) {
+	case *Item_Cents:
+		n += proto.SizeVarint(9<<3 | proto.WireVarint)
+		n += proto.SizeVarint(uint64(x.Cents))
+	case *Item_Note:
+		n += proto.SizeVarint(10<<3 | proto.WireBytes)
+		n += proto.SizeVarint(uint64(len(x.Note)))
+		n += len(x.Note)
+	case *Item_Box:
+		n += proto.SizeVarint(11<<3 | proto.WireBytes)
+		s := proto.Size(x.Box)
+		n += proto.SizeVarint(uint64(s))
+		n += s
+	case *Item_Free:
+		n += proto.SizeVarint(12<<3 | proto.WireVarint)
+		n += 1
+	case *Item_Ratio:
+		n += proto.SizeVarint(13<<3 | proto.WireFixed64)
+		n += 8
+	case *Item_Delta:
+		n += proto.SizeVarint(14<<3 | proto.WireVarint)
+		n += proto.SizeVarint(uint64((uint32(x.Delta) << 1) ^ uint32((int32(x.Delta) >> 31))))
+	case *Item_Tint:
+		n += proto.SizeVarint(15<<3 | proto.WireVarint)
+		n += proto.SizeVarint(uint64(x.Tint))
+	case nil:
+	default:
+		panic(fmt.Sprintf("proto: unexpected type %T in oneof", x))
+	}
+	return n
+}
+
+// 638 bytes of a gzipped FileDescriptorProto
+var xserviceFileDescriptor_4dc296cbfe5ffcd5 = []byte{0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x93, 0x5d, 0x4f, 0xdb, 0x3e, 0x14, 0xc6, 0xeb, 0x26, 0xe9, 0xcb, 0x69, 0xe1, 0x1f, 0x2c, 0xc4, 0xdf, 0x0a, 0x17, 0x0b, 0x95, 0xa6, 0x45, 0xd3, 0x08, 0x12, 0xdb, 0x04, 0xe3, 0x6a, 0x2b, 0x54, 0x03, 0x75, 0x6a, 0x25, 0x33, 0x34, 0x69, 0xbb, 0x40, 0xa6, 0x31, 0xc5, 0x22, 0x89, 0xb3, 0xd8, 0x6c, 0xb0, 0xef, 0xb3, 0xef, 0x39, 0xd9, 0x69, 0x57, 0x34, 0x5e, 0xc4, 0xdd, 0x39, 0xc7, 0xcf, 0xf3, 0x1c, 0x4e, 0xf8, 0x15, 0x96, 0x33, 0xae, 0x14, 0x9b, 0x72, 0x15, 0x17, 0xa5, 0xd4, 0x12, 0xfb, 0xfc, 0x9a, 0x65, 0x45, 0xca, 0xe3, 0xf9, 0x3c, 0x78, 0x36, 0x95, 0x72, 0x9a, 0xf2, 0x2d, 0xfb, 0x7e, 0x76, 0x75, 0xbe, 0xa5, 0x45, 0xc6, 0x95, 0x66, 0x59, 0x51, 0x59, 0x7a, 0xbf, 0x9b, 0xe0, 0x1e, 0x69, 0x9e, 0x61, 0x0c, 0x6e, 0xce, 0x32, 0x4e, 0x50, 0x88, 0xa2, 0x36, 0xb5, 0x35, 0xde, 0x04, 0x6f, 0x22, 0x53, 0x59, 0x92, 0x7a, 0x88, 0xa2, 0xe5, 0xed, 0xff, 0xe3, 0x7f, 0xf3, 0xe3, 0x7d, 0xf3, 0x4c, 0x2b, 0x15, 0xde, 0x02, 0xf7, 0x52, 0xe4, 0x09, 0x71, 0xac, 0x7a, 0xfd, 0xae, 0xda, 0x2c, 0x8a, 0x87, 0x22, 0x4f, 0xa8, 0x15, 0x62, 0x1f, 0x1c, 0x91, 0x28, 0xe2, 0x86, 0x4e, 0xe4, 0x50, 0x53, 0xe2, 0x3d, 0x68, 0x4c, 0xe4, 0x55, 0xae, 0x15, 0xf1, 0x42, 0x27, 0xea, 0x6c, 0xf7, 0x1e, 0x08, 0xd9, 0xb7, 0xa2, 0x41, 0xae, 0xcb, 0x1b, 0x3a, 0x73, 0xe0, 0x1d, 0xf0, 0x94, 0xf8, 0xc5, 0x15, 0x69, 0x58, 0xeb, 0xc6, 0x03, 0xd6, 0x63, 0xa3, 0xa9, 0x9c, 0x95, 0xde, 0x9c, 0x9e, 0x30, 0xcd, 0x48, 0x33, 0x44, 0x51, 0x97, 0xda, 0x1a, 0x7f, 0x00, 0x48, 0x44, 0xc6, 0x73, 0x25, 0x64, 0xae, 0x48, 0x2b, 0x44, 0x8f, 0x24, 0x1e, 0xfc, 0x15, 0xd2, 0x5b, 0x26, 0xbc, 0x06, 0xde, 0x84, 0x9b, 0x53, 0xda, 0x21, 0x8a, 0x9c, 0xc3, 0x1a, 0xad, 0x5a, 0xbc, 0x0a, 0x6e, 0x2e, 0x35, 0x27, 0x60, 0xbe, 0xf4, 0x61, 0x8d, 0xda, 0x0e, 0xbf, 0x05, 0xe7, 0x4c, 0x5e, 0x93, 0xce, 0x13, 0x37, 0x1d, 0xd6, 0xa8, 0xd1, 0x9b, 0xb0, 0xf3, 0x92, 0x73, 0xd2, 0x0d, 0x51, 0xd4, 0x32, 0x61, 0xa6, 0x33, 0xab, 0x4b, 0xa6, 0x85, 0x24, 0x4b, 0x21, 0x8a, 0x90, 0x59, 0x6d, 0x5b, 0x33, 0x4f, 0x78, 0xaa, 0x19, 0x59, 0x0e, 0x51, 0xb4, 0x62, 0xe6, 0xb6, 0xc5, 0x9b, 0xe0, 0x6a, 0x91, 0x6b, 0xf2, 0xdf, 0xa3, 0xff, 0x67, 0x13, 0x6f, 0x64, 0xe6, 0x83, 0x69, 0x36, 0x55, 0xc4, 0x0f, 0x1d, 0xc3, 0x8a, 0xa9, 0xf1, 0x06, 0x74, 0x13, 0xa1, 0x8a, 0x94, 0xdd, 0x9c, 0x5a, 0x8e, 0x56, 0x2c, 0x47, 0x9d, 0xd9, 0x6c, 0x64, 0x70, 0x7a, 0x03, 0xcd, 0x49, 0xc9, 0x99, 0xe6, 0x09, 0xc1, 0xf6, 0xcc, 0x20, 0xae, 0xf0, 0x8c, 0xe7, 0x78, 0xc6, 0x9f, 0xe7, 0x78, 0xd2, 0xb9, 0x34, 0xd8, 0x03, 0x58, 0x9c, 0x8d, 0x57, 0xc1, 0xfb, 0x29, 0x12, 0x7d, 0x61, 0x39, 0x45, 0xb4, 0x6a, 0xf0, 0x1a, 0x34, 0x2e, 0xb8, 0x98, 0x5e, 0x68, 0x4b, 0x2a, 0xa2, 0xb3, 0x2e, 0x78, 0x07, 0x9d, 0x5b, 0xa4, 0x18, 0xde, 0x2e, 0xf9, 0xcd, 0x0c, 0x71, 0x53, 0x9a, 0xb8, 0x1f, 0x2c, 0xbd, 0xe2, 0xd6, 0xe7, 0xd1, 0xaa, 0xd9, 0xab, 0xef, 0xa2, 0xe0, 0x1b, 0xc0, 0x82, 0x94, 0x7b, 0x9c, 0x3b, 0xb7, 0x9d, 0x4f, 0x62, 0x63, 0x11, 0xde, 0x7b, 0x01, 0xee, 0xb0, 0xfa, 0x01, 0x74, 0x87, 0x47, 0xa3, 0x83, 0xd3, 0x93, 0xd1, 0x70, 0x34, 0xfe, 0x32, 0xf2, 0x6b, 0x78, 0x09, 0xda, 0x76, 0xd2, 0x1f, 0x8f, 0x87, 0x3e, 0xea, 0x37, 0xc1, 0x2b, 0x4a, 0x31, 0xe1, 0xbd, 0x5d, 0x68, 0x1d, 0x6b, 0x59, 0x72, 0xca, 0xbf, 0xe3, 0x57, 0xe0, 0x09, 0xcd, 0x33, 0x45, 0x90, 0x05, 0x7d, 0xed, 0xfe, 0xd5, 0xb4, 0x12, 0xf5, 0xc6, 0xd0, 0x9e, 0x39, 0x55, 0x61, 0xee, 0xd5, 0x52, 0xb3, 0xd4, 0x5e, 0xe2, 0xd1, 0xaa, 0x59, 0x04, 0xd6, 0x9f, 0x10, 0xf8, 0xf2, 0x39, 0x78, 0x16, 0x07, 0xdc, 0x04, 0x87, 0x0e, 0x0e, 0xfc, 0x1a, 0x6e, 0x83, 0xf7, 0x91, 0x0e, 0x06, 0x23, 0x1f, 0xe1, 0x16, 0xb8, 0xfd, 0x4f, 0x27, 0x03, 0xbf, 0xbe, 0x3d, 0x84, 0xe6, 0x3e, 0xd3, 0x2c, 0x95, 0x53, 0xfc, 0x1e, 0x3c, 0xfb, 0x27, 0xe0, 0xe0, 0x6e, 0xf2, 0xfc, 0xaa, 0x60, 0xfd, 0xc1, 0x37, 0x55, 0xf4, 0xe1, 0x6b, 0x6b, 0x3e, 0x3d, 0x6b, 0x58, 0x5a, 0x5e, 0xff, 0x19, 0x00, 0x24, 0x2b, 0xb1, 0x5b, 0xfe, 0x04, 0x00, 0x00}
+
+type Catalog interface {
+	Store(ctx context.Context, req *StoreReq) (*StoreResp, error)
+}
+
+type Item_Dimensions struct {
+	Width  float64 `protobuf:"fixed64,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height float64 `protobuf:"fixed64,2,opt,name=height,proto3" json:"height,omitempty"`
+}
+
+func (m *Item_Dimensions) Reset() {
+	*m = Item_Dimensions{}
+}
+
+func (m *Item_Dimensions) String() string {
+	return proto.CompactTextString(m)
+}
+
+func (m *Item_Dimensions) ProtoMessage() {
+}
+
+func (m *Item_Dimensions) Descriptor() ([]byte, []int) {
+	return xserviceFileDescriptor_4dc296cbfe5ffcd5, []int{0, 0}
+}
+
+func (m *Item_Dimensions) GetWidth() float64 {
+	if m != nil {
+		return m.Width
+	}
+	return 0
+}
+
+func (m *Item_Dimensions) GetHeight() float64 {
+	if m != nil {
+		return m.Height
+	}
+	return 0
+}
+
+// Item of a catalog.
+type Item struct {
+	Name        string                      `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Color       Color                       `protobuf:"varint,2,opt,name=color,proto3,enum=example.messages.Color" json:"color,omitempty"`
+	Kind        Item_Kind                   `protobuf:"varint,3,opt,name=kind,proto3,enum=example.messages.Item.Kind" json:"kind,omitempty"`
+	Ids         []int64                     `protobuf:"varint,4,rep,packed,name=ids,proto3" json:"ids,omitempty"`
+	Counts      map[string]int32            `protobuf:"bytes,5,rep,name=counts,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"varint,2,opt,name=value,proto3" json:"counts,omitempty"`
+	Sizes       map[string]*Item_Dimensions `protobuf:"bytes,6,rep,name=sizes,proto3" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3" json:"sizes,omitempty"`
+	Data        []byte                      `protobuf:"bytes,7,opt,name=data,proto3" json:"data,omitempty"`
+	Dimensions  *Item_Dimensions            `protobuf:"bytes,8,opt,name=dimensions,proto3" json:"dimensions,omitempty"`
+	Price       isItem_Price                `protobuf_oneof:"price"`
+	Tags        []string                    `protobuf:"bytes,16,rep,name=tags,proto3" json:"tags,omitempty"`
+	DisplayName string                      `protobuf:"bytes,17,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+	Created     *types.Timestamp            `protobuf:"bytes,18,opt,name=created,proto3" json:"created,omitempty"`
+}
+
+func (m *Item) Reset() {
+	*m = Item{}
+}
+
+func (m *Item) String() string {
+	return proto.CompactTextString(m)
+}
+
+func (m *Item) ProtoMessage() {
+}
+
+func (m *Item) Descriptor() ([]byte, []int) {
+	return xserviceFileDescriptor_4dc296cbfe5ffcd5, []int{0}
+}
+
+func (m *Item) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *Item) GetColor() Color {
+	if m != nil {
+		return m.Color
+	}
+	return Color_RED
+}
+
+func (m *Item) GetKind() Item_Kind {
+	if m != nil {
+		return m.Kind
+	}
+	return Item_KIND_UNKNOWN
+}
+
+func (m *Item) GetIds() []int64 {
+	if m != nil {
+		return m.Ids
+	}
+	return nil
+}
+
+func (m *Item) GetCounts() map[string]int32 {
+	if m != nil {
+		return m.Counts
+	}
+	return nil
+}
+
+func (m *Item) GetSizes() map[string]*Item_Dimensions {
+	if m != nil {
+		return m.Sizes
+	}
+	return nil
+}
+
+func (m *Item) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *Item) GetDimensions() *Item_Dimensions {
+	if m != nil {
+		return m.Dimensions
+	}
+	return nil
+}
+
+func (m *Item) GetPrice() isItem_Price {
+	if m != nil {
+		return m.Price
+	}
+	return nil
+}
+
+func (m *Item) GetTags() []string {
+	if m != nil {
+		return m.Tags
+	}
+	return nil
+}
+
+func (m *Item) GetDisplayName() string {
+	if m != nil {
+		return m.DisplayName
+	}
+	return ""
+}
+
+func (m *Item) GetCreated() *types.Timestamp {
+	if m != nil {
+		return m.Created
+	}
+	return nil
+}
+
+func (m *Item) GetCents() int64 {
+	if x, ok := m.GetPrice().(*Item_Cents); ok {
+		return x.Cents
+	}
+	return 0
+}
+
+func (m *Item) GetNote() string {
+	if x, ok := m.GetPrice().(*Item_Note); ok {
+		return x.Note
+	}
+	return ""
+}
+
+func (m *Item) GetBox() *Item_Dimensions {
+	if x, ok := m.GetPrice().(*Item_Box); ok {
+		return x.Box
+	}
+	return nil
+}
+
+func (m *Item) GetFree() bool {
+	if x, ok := m.GetPrice().(*Item_Free); ok {
+		return x.Free
+	}
+	return false
+}
+
+func (m *Item) GetRatio() float64 {
+	if x, ok := m.GetPrice().(*Item_Ratio); ok {
+		return x.Ratio
+	}
+	return 0
+}
+
+func (m *Item) GetDelta() int32 {
+	if x, ok := m.GetPrice().(*Item_Delta); ok {
+		return x.Delta
+	}
+	return 0
+}
+
+func (m *Item) GetTint() Color {
+	if x, ok := m.GetPrice().(*Item_Tint); ok {
+		return x.Tint
+	}
+	return Color_RED
+}
+
+type StoreReq struct {
+	Items []*Item `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (m *StoreReq) Reset() {
+	*m = StoreReq{}
+}
+
+func (m *StoreReq) String() string {
+	return proto.CompactTextString(m)
+}
+
+func (m *StoreReq) ProtoMessage() {
+}
+
+func (m *StoreReq) Descriptor() ([]byte, []int) {
+	return xserviceFileDescriptor_4dc296cbfe5ffcd5, []int{1}
+}
+
+func (m *StoreReq) GetItems() []*Item {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+type StoreResp struct {
+	Total int32   `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Items []*Item `protobuf:"bytes,2,rep,name=items,proto3" json:"items,omitempty"`
+}
+
+func (m *StoreResp) Reset() {
+	*m = StoreResp{}
+}
+
+func (m *StoreResp) String() string {
+	return proto.CompactTextString(m)
+}
+
+func (m *StoreResp) ProtoMessage() {
+}
+
+func (m *StoreResp) Descriptor() ([]byte, []int) {
+	return xserviceFileDescriptor_4dc296cbfe5ffcd5, []int{2}
+}
+
+func (m *StoreResp) GetTotal() int32 {
+	if m != nil {
+		return m.Total
+	}
+	return 0
+}
+
+func (m *StoreResp) GetItems() []*Item {
+	if m != nil {
+		return m.Items
+	}
+	return nil
+}
+
+// catalogJSONClient wraps an http.client and sends JSON objects
+type catalogJSONClient struct {
+	client  transport.HTTPClient
+	urls    [1]string
+	options *transport.ClientOptions
+}
+
+// Store sends an StoreReq JSON object to the server
+func (c *catalogJSONClient) Store(ctx context.Context, in *StoreReq) (*StoreResp, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.messages")
+	ctx = xcontext.WithServiceName(ctx, "Catalog")
+	ctx = xcontext.WithMethodName(ctx, "Store")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(StoreResp)
+		err := c.options.DoJSONRequest(ctx, c.client, c.urls[0], req.(*StoreReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*StoreResp)
+	return out, err
+}
+
+// catalogProtobufferClient wraps an http.client and sends Protobuffer objects
+type catalogProtobufferClient struct {
+	client  transport.HTTPClient
+	urls    [1]string
+	options *transport.ClientOptions
+}
+
+// Store sends an StoreReq Protobuffer object to the server
+func (c *catalogProtobufferClient) Store(ctx context.Context, in *StoreReq) (*StoreResp, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.messages")
+	ctx = xcontext.WithServiceName(ctx, "Catalog")
+	ctx = xcontext.WithMethodName(ctx, "Store")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(StoreResp)
+		err := c.options.DoProtobufferRequest(ctx, c.client, c.urls[0], req.(*StoreReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*StoreResp)
+	return out, err
+}
+
+// catalogLocalClient calls an implementation of Catalog in-process, with the hooks and interceptors of a server
+type catalogLocalClient struct {
+	svc     Catalog
+	options *transport.ServerOptions
+}
+
+// Store calls Store of the service in-process
+func (c *catalogLocalClient) Store(ctx context.Context, in *StoreReq) (*StoreResp, error) {
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out, err := c.svc.Store(ctx, req.(*StoreReq))
+		return out, err
+	}
+	resp, err := c.options.CallLocal(ctx, "example.messages", "Catalog", "Store", in, call)
+	out, _ := resp.(*StoreResp)
+	return out, err
+}
+
+// catalogServer wraps an endpoint and implements http.Handler.
+type catalogServer struct {
+	Catalog
+	hooks        *hooks.ServerHooks
+	logErrorFunc transport.LogErrorFunc
+	options      *transport.ServerOptions
+	prefix       string
+}
+
+func (s *catalogServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	s.options.WriteError(ctx, resp, err, s.hooks)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *catalogServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	resp = transport.NewResponseWriter(resp)
+	ctx := req.Context()
+	ctx = xcontext.WithPackageName(ctx, "example.messages")
+	ctx = xcontext.WithServiceName(ctx, "Catalog")
+	ctx = xcontext.WithResponseWriter(ctx, resp)
+	ctx = xcontext.WithHTTPRequest(ctx, req)
+	s.options.LimitRequestBody(req)
+	recoverWrapper := func() {
+		if r := recover(); r != nil {
+			s.options.HandlePanic(ctx, resp, r, s.hooks)
+		}
+	}
+	defer recoverWrapper()
+
+	var err error
+	ctx, err = transport.CallRequestReceived(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	err = s.options.CheckVersion(resp, req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	err = s.options.CheckMethod(req, false)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	switch req.URL.Path {
+	case s.prefix + "Store":
+		s.serveStore(ctx, resp, req)
+		return
+
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		terr := errors.BadRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+
+}
+
+// serveStore is used to set an decoder and encoder for a given content type
+func (s *catalogServer) serveStore(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	codec, err := s.options.RequestCodec(req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	respCodec, err := s.options.ResponseCodec(req, codec)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	ctx = xcontext.WithResponseContentType(ctx, respCodec.ContentType())
+	s.serveStoreContent(ctx, resp, req, transport.DecodeRequestWith(codec), transport.EncodeResponseWith(respCodec))
+}
+
+// serveStoreContent sends object to requester
+func (s *catalogServer) serveStoreContent(ctx context.Context, resp http.ResponseWriter, req *http.Request, decodeRequest transport.DecodeRequestFunc, encodeResponse transport.EncodeResponseFunc) {
+	var err error
+	ctx = xcontext.WithMethodName(ctx, "Store")
+	ctx, err = transport.CallRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	defer transport.Closebody(req.Body, s.logErrorFunc)
+
+	reqContent := new(StoreReq)
+	if err := decodeRequest(ctx, req, reqContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	endpointWrapper := func() (respContent *StoreResp, err error) {
+		deferWrapper := func() {
+			if r := recover(); r != nil {
+				err = s.options.RecoverPanic(ctx, r)
+			}
+		}
+		defer deferWrapper()
+
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			out, err := s.Store(ctx, req.(*StoreReq))
+			return out, err
+		}
+		out, callErr := s.options.Intercept(call)(ctx, reqContent)
+		content, _ := out.(*StoreResp)
+		return content, callErr
+	}
+	respContent, err := endpointWrapper()
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		terr := errors.InternalError("received a nil * StoreResp, and nil error while calling Store. nil responses are not supported")
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+	ctx = transport.CallResponsePrepared(ctx, s.hooks)
+	if err := encodeResponse(ctx, resp, respContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	transport.CallResponseSent(ctx, s.hooks)
+}
+
+// ServiceDescriptor describes an service.
+func (s *catalogServer) ServiceDescriptor() ([]uint8, int) {
+	return xserviceFileDescriptor_4dc296cbfe5ffcd5, 0
+}
+
+// ProtocGenXServiceVersion returns which xservice version was used to generate that service
+func (s *catalogServer) ProtocGenXServiceVersion() string {
+	return "v0.1.0"
+}
+
+// NewCatalogJSONClient constructs a new client, which wraps the http.client and implements Catalog
+func NewCatalogJSONClient(addr string, client transport.HTTPClient) Catalog {
+	return NewCatalogJSONClientWithOptions(addr, client)
+}
+
+// NewCatalogJSONClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements Catalog
+func NewCatalogJSONClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) Catalog {
+	options := transport.NewClientOptions(opts...)
+	URLBase := transport.UrlBase(addr)
+	prefix := URLBase + options.ServicePathPrefix(CatalogPathPrefix, "example.messages.Catalog")
+	urls := [1]string{
+		prefix + "Store",
+	}
+	return &catalogJSONClient{
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
+	}
+}
+
+// NewCatalogProtobufferClient constructs a new client, which wraps the http.client and implements Catalog
+func NewCatalogProtobufferClient(addr string, client transport.HTTPClient) Catalog {
+	return NewCatalogProtobufferClientWithOptions(addr, client)
+}
+
+// NewCatalogProtobufferClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements Catalog
+func NewCatalogProtobufferClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) Catalog {
+	options := transport.NewClientOptions(opts...)
+	URLBase := transport.UrlBase(addr)
+	prefix := URLBase + options.ServicePathPrefix(CatalogPathPrefix, "example.messages.Catalog")
+	urls := [1]string{
+		prefix + "Store",
+	}
+	return &catalogProtobufferClient{
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
+	}
+}
+
+// NewCatalogLocalClient constructs a client which implements Catalog by calling svc in-process. It calls the hooks
+// and populates the context like NewCatalogServer.
+func NewCatalogLocalClient(svc Catalog, hooks *hooks.ServerHooks) Catalog {
+	return NewCatalogLocalClientWithOptions(svc, transport.WithServerHooks(hooks))
+}
+
+// NewCatalogLocalClientWithOptions constructs a client like NewCatalogLocalClient, configured by server options. With transport.WithLocalRoundTrip svc receives and
+// returns copies of the messages.
+func NewCatalogLocalClientWithOptions(svc Catalog, opts ...transport.ServerOption) Catalog {
+	return &catalogLocalClient{
+		svc:     svc,
+		options: transport.NewServerOptions(opts...),
+	}
+}
+
+// NewCatalogServer constructs a new server, and implements Catalog
+func NewCatalogServer(svc Catalog, hooks *hooks.ServerHooks, errorFunc ...transport.LogErrorFunc) server.Server {
+	opts := make([]transport.ServerOption, 0, 2)
+	opts = append(opts, transport.WithServerHooks(hooks))
+	if len(errorFunc) == 1 {
+		opts = append(opts, transport.WithServerLogger(errorFunc[0]))
+	}
+	return NewCatalogServerWithOptions(svc, opts...)
+}
+
+// NewCatalogServerWithOptions constructs a new server configured by opts, and implements Catalog
+func NewCatalogServerWithOptions(svc Catalog, opts ...transport.ServerOption) server.Server {
+	options := transport.NewServerOptions(opts...)
+	return &catalogServer{
+		Catalog:      svc,
+		hooks:        options.Hooks(),
+		logErrorFunc: options.Logger(),
+		options:      options,
+		prefix:       options.ServicePathPrefix(CatalogPathPrefix, "example.messages.Catalog"),
+	}
+}
+func init() {
+	server.RegisterServiceDescriptor("example.messages.Catalog", xserviceFileDescriptor_4dc296cbfe5ffcd5, 0)
+	proto.RegisterType((*Item)(nil), "example.messages.Item")
+	proto.RegisterType((*Item_Dimensions)(nil), "example.messages.Item.Dimensions")
+	proto.RegisterType((*StoreReq)(nil), "example.messages.StoreReq")
+	proto.RegisterType((*StoreResp)(nil), "example.messages.StoreResp")
+	proto.RegisterEnum("example.messages.Color", Color_name, Color_value)
+	proto.RegisterEnum("example.messages.Item.Kind", Item_Kind_name, Item_Kind_value)
+}
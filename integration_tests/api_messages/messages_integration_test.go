@@ -0,0 +1,86 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package messages_test
+
+import (
+	"context"
+	"github.com/donutloop/xservice/integration_tests/api_messages"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type CatalogServer struct{}
+
+func (s *CatalogServer) Store(ctx context.Context, req *messages.StoreReq) (*messages.StoreResp, error) {
+	return &messages.StoreResp{Total: int32(len(req.Items)), Items: req.Items}, nil
+}
+
+func TestGeneratedMessagesCalls(t *testing.T) {
+	server := httptest.NewServer(messages.NewCatalogServer(&CatalogServer{}, nil))
+	defer server.Close()
+
+	req := &messages.StoreReq{Items: []*messages.Item{
+		{
+			Name:        "book",
+			Color:       messages.Color_BLUE,
+			Kind:        messages.Item_KIND_BOOK,
+			Ids:         []int64{1, -2},
+			Counts:      map[string]int32{"shelf": 3},
+			Sizes:       map[string]*messages.Item_Dimensions{"a4": {Width: 21, Height: 29.7}},
+			Data:        []byte("data"),
+			Dimensions:  &messages.Item_Dimensions{Width: 1},
+			Price:       &messages.Item_Cents{Cents: 1299},
+			Tags:        []string{"new"},
+			DisplayName: "Book",
+			Created:     &types.Timestamp{Seconds: 1528329600},
+		},
+		{Name: "gift", Price: &messages.Item_Box{Box: &messages.Item_Dimensions{Height: 2}}},
+		{Name: "sample", Price: &messages.Item_Tint{Tint: messages.Color_GREEN}},
+	}}
+
+	clients := map[string]messages.Catalog{
+		"json":        messages.NewCatalogJSONClient(server.URL, &http.Client{}),
+		"protobuffer": messages.NewCatalogProtobufferClient(server.URL, &http.Client{}),
+	}
+
+	for name, client := range clients {
+		resp, err := client.Store(context.Background(), req)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if resp.Total != 3 {
+			t.Errorf("%s: unexpected total (actual: %d, expected: %d)", name, resp.Total, 3)
+		}
+		for i, item := range resp.Items {
+			if !proto.Equal(item, req.Items[i]) {
+				t.Errorf("%s: unexpected item (actual: %v, expected: %v)", name, item, req.Items[i])
+			}
+		}
+		if resp.Items[0].GetCents() != 1299 || resp.Items[2].GetTint() != messages.Color_GREEN {
+			t.Errorf("%s: unexpected oneof values (actual: %v, %v)", name, resp.Items[0].GetPrice(), resp.Items[2].GetPrice())
+		}
+	}
+}
+
+func TestGeneratedMessagesRegistration(t *testing.T) {
+	if typ := proto.MessageType("example.messages.Item.Dimensions"); typ == nil || typ.Elem().Name() != "Item_Dimensions" {
+		t.Errorf("message example.messages.Item.Dimensions is not registered (actual: %v)", typ)
+	}
+	if v := proto.EnumValueMap("example.messages.Item.Kind")["KIND_BOOK"]; v != int32(messages.Item_KIND_BOOK) {
+		t.Errorf("unexpected registered enum value (actual: %d, expected: %d)", v, messages.Item_KIND_BOOK)
+	}
+}
@@ -0,0 +1,80 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: common/common.proto
+
+package common
+
+import (
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type Status struct {
+	Message              string   `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Status) Reset()         { *m = Status{} }
+func (m *Status) String() string { return proto.CompactTextString(m) }
+func (*Status) ProtoMessage()    {}
+func (*Status) Descriptor() ([]byte, []int) {
+	return fileDescriptor_8f954d82c0b891f6, []int{0}
+}
+
+func (m *Status) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Status.Unmarshal(m, b)
+}
+func (m *Status) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Status.Marshal(b, m, deterministic)
+}
+func (m *Status) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Status.Merge(m, src)
+}
+func (m *Status) XXX_Size() int {
+	return xxx_messageInfo_Status.Size(m)
+}
+func (m *Status) XXX_DiscardUnknown() {
+	xxx_messageInfo_Status.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Status proto.InternalMessageInfo
+
+func (m *Status) GetMessage() string {
+	if m != nil {
+		return m.Message
+	}
+	return ""
+}
+
+func init() {
+	proto.RegisterType((*Status)(nil), "example.common.Status")
+}
+
+func init() { proto.RegisterFile("common/common.proto", fileDescriptor_8f954d82c0b891f6) }
+
+var fileDescriptor_8f954d82c0b891f6 = []byte{
+	// 152 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x34, 0xce, 0x31, 0x0b, 0xc2, 0x30,
+	0x10, 0x05, 0x60, 0xba, 0x54, 0xec, 0xe0, 0x50, 0x97, 0x8e, 0xd2, 0xc9, 0xa9, 0x19, 0xfc, 0x07,
+	0x4e, 0xe2, 0xa8, 0x9b, 0x4b, 0xb9, 0xc6, 0x23, 0x1e, 0x26, 0xb9, 0x90, 0xbb, 0x48, 0x7f, 0xbe,
+	0x68, 0x75, 0x7a, 0xbc, 0x07, 0x0f, 0xbe, 0x66, 0x6b, 0x39, 0x04, 0x8e, 0x66, 0x89, 0x21, 0x65,
+	0x56, 0x6e, 0x37, 0x38, 0x43, 0x48, 0x1e, 0x87, 0x65, 0xed, 0xfb, 0xa6, 0xbe, 0x2a, 0x68, 0x91,
+	0xb6, 0x6b, 0x56, 0x01, 0x45, 0xc0, 0x61, 0x57, 0xed, 0xaa, 0xfd, 0xfa, 0xf2, 0xaf, 0xc7, 0xf3,
+	0xed, 0xe4, 0x48, 0x1f, 0x65, 0xfa, 0x9c, 0xcc, 0x9d, 0x63, 0x51, 0xcf, 0x9c, 0xcc, 0x2c, 0x98,
+	0x5f, 0x64, 0xd1, 0x50, 0x54, 0x74, 0x19, 0x94, 0x38, 0x8e, 0x8a, 0xa2, 0x62, 0x20, 0xd1, 0x18,
+	0x8a, 0x57, 0x1a, 0x13, 0xd8, 0x27, 0x38, 0xfc, 0x29, 0xa6, 0xfa, 0xcb, 0x38, 0xbc, 0x07, 0x00,
+	0x2b, 0xe3, 0x15, 0xae, 0x9d, 0x00, 0x00, 0x00,
+}
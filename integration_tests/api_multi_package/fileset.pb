@@ -0,0 +1,27 @@
+
+j
+google/protobuf/empty.protogoogle.protobuf"
+EmptyB)Z'github.com/golang/protobuf/ptypes/emptybproto3
+
+google/protobuf/timestamp.protogoogle.protobuf";
+	Timestamp
+seconds (Rseconds
+nanos (RnanosB-Z+github.com/golang/protobuf/ptypes/timestampbproto3
+
+common/common.protoexample.common""
+Status
+message (	RmessageBJZHgithub.com/donutloop/xservice/integration_tests/api_multi_package/commonbproto3
+Q
+shared/common/common.protoexample.shared"
+Label
+name (	Rnamebproto3
+
+multi.protoexample.multigoogle/protobuf/empty.protogoogle/protobuf/timestamp.protocommon/common.protoshared/common/common.proto"7
+LabelReq+
+label (2.example.shared.LabelRlabel2
+Clock9
+Now.google.protobuf.Empty.google.protobuf.Timestamp5
+Echo.example.common.Status.example.shared.Label8
+Check.example.multi.LabelReq.example.common.Status2H
+	Stopwatch;
+Start.google.protobuf.Empty.google.protobuf.TimestampBIZGgithub.com/donutloop/xservice/integration_tests/api_multi_package;multibproto3
\ No newline at end of file
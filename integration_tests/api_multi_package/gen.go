@@ -0,0 +1,7 @@
+package multi
+
+// The well-known types are mapped to gogo's types and shared/common/common.proto,
+// which has no go_package, to its import path with M parameters.
+//go:generate protoc -I . ./common/common.proto ./shared/common/common.proto --go_out=paths=source_relative:.
+//go:generate protoc -I . ./multi.proto --xservice_out=Mgoogle/protobuf/empty.proto=github.com/gogo/protobuf/types,Mgoogle/protobuf/timestamp.proto=github.com/gogo/protobuf/types,Mshared/common/common.proto=github.com/donutloop/xservice/integration_tests/api_multi_package/shared/common:. --go_out=Mgoogle/protobuf/empty.proto=github.com/gogo/protobuf/types,Mgoogle/protobuf/timestamp.proto=github.com/gogo/protobuf/types,Mshared/common/common.proto=github.com/donutloop/xservice/integration_tests/api_multi_package/shared/common,paths=source_relative:.
+//go:generate protoc -I . ./multi.proto --include_imports --descriptor_set_out=fileset.pb
@@ -0,0 +1,92 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: multi.proto
+
+package multi
+
+import (
+	fmt "fmt"
+	_ "github.com/donutloop/xservice/integration_tests/api_multi_package/common"
+	common "github.com/donutloop/xservice/integration_tests/api_multi_package/shared/common"
+	_ "github.com/gogo/protobuf/types"
+	proto "github.com/golang/protobuf/proto"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type LabelReq struct {
+	Label                *common.Label `protobuf:"bytes,1,opt,name=label,proto3" json:"label,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
+	XXX_unrecognized     []byte        `json:"-"`
+	XXX_sizecache        int32         `json:"-"`
+}
+
+func (m *LabelReq) Reset()         { *m = LabelReq{} }
+func (m *LabelReq) String() string { return proto.CompactTextString(m) }
+func (*LabelReq) ProtoMessage()    {}
+func (*LabelReq) Descriptor() ([]byte, []int) {
+	return fileDescriptor_a33188ebc3268bf7, []int{0}
+}
+
+func (m *LabelReq) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_LabelReq.Unmarshal(m, b)
+}
+func (m *LabelReq) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_LabelReq.Marshal(b, m, deterministic)
+}
+func (m *LabelReq) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_LabelReq.Merge(m, src)
+}
+func (m *LabelReq) XXX_Size() int {
+	return xxx_messageInfo_LabelReq.Size(m)
+}
+func (m *LabelReq) XXX_DiscardUnknown() {
+	xxx_messageInfo_LabelReq.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_LabelReq proto.InternalMessageInfo
+
+func (m *LabelReq) GetLabel() *common.Label {
+	if m != nil {
+		return m.Label
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterType((*LabelReq)(nil), "example.multi.LabelReq")
+}
+
+func init() { proto.RegisterFile("multi.proto", fileDescriptor_a33188ebc3268bf7) }
+
+var fileDescriptor_a33188ebc3268bf7 = []byte{
+	// 302 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x90, 0x4f, 0x4b, 0x03, 0x31,
+	0x10, 0xc5, 0x29, 0xba, 0xa2, 0x29, 0x5e, 0x22, 0x56, 0x89, 0x07, 0xc5, 0x93, 0x20, 0x24, 0x50,
+	0x11, 0x95, 0xde, 0x2c, 0x45, 0x05, 0xf1, 0x60, 0x3d, 0x79, 0x29, 0xd9, 0x74, 0xdc, 0x0d, 0x4d,
+	0x76, 0xe2, 0xee, 0xac, 0xad, 0x9f, 0xcb, 0x2f, 0x28, 0xdd, 0x3f, 0x82, 0x5a, 0x2f, 0x9e, 0x92,
+	0xbc, 0xf7, 0x32, 0xef, 0x97, 0xb0, 0xae, 0x2f, 0x1d, 0x59, 0x19, 0x72, 0x24, 0xe4, 0xdb, 0xb0,
+	0xd0, 0x3e, 0x38, 0x90, 0x95, 0x28, 0x0e, 0x12, 0xc4, 0xc4, 0x81, 0xaa, 0xcc, 0xb8, 0x7c, 0x51,
+	0xe0, 0x03, 0xbd, 0xd7, 0x59, 0x71, 0xf8, 0xd3, 0x24, 0xeb, 0xa1, 0x20, 0xed, 0x43, 0x13, 0xd8,
+	0x31, 0xe8, 0x3d, 0x66, 0xaa, 0x5e, 0x1a, 0x51, 0x14, 0xa9, 0xce, 0x61, 0xaa, 0x56, 0x78, 0xc7,
+	0x17, 0x6c, 0xf3, 0x5e, 0xc7, 0xe0, 0x1e, 0xe1, 0x95, 0x9f, 0xb2, 0xc8, 0x2d, 0xf7, 0xfb, 0x9d,
+	0xa3, 0xce, 0x49, 0xb7, 0xbf, 0x2b, 0x5b, 0xb2, 0xfa, 0xbe, 0xac, 0x83, 0x75, 0xa6, 0xff, 0xd1,
+	0x61, 0xd1, 0xd0, 0xa1, 0x99, 0xf1, 0x2b, 0xb6, 0xf6, 0x80, 0x73, 0xde, 0x93, 0x35, 0x9c, 0x6c,
+	0xe1, 0xe4, 0x68, 0x49, 0x2e, 0xc4, 0x2f, 0xfd, 0xa9, 0x85, 0xe6, 0xe7, 0x6c, 0x7d, 0x64, 0x52,
+	0xe4, 0xbd, 0xaf, 0xaa, 0x06, 0x6e, 0x4c, 0x9a, 0xca, 0x42, 0xac, 0x46, 0xe0, 0x97, 0x2c, 0x1a,
+	0xa6, 0x60, 0x66, 0x7c, 0x4f, 0x7e, 0xfb, 0x3c, 0xd9, 0x3e, 0x45, 0xfc, 0x31, 0xb0, 0x7f, 0xcb,
+	0xb6, 0xc6, 0x84, 0x61, 0xae, 0xc9, 0xa4, 0x7c, 0xc0, 0xa2, 0x31, 0xe9, 0x9c, 0xfe, 0x83, 0x7e,
+	0x7d, 0xf7, 0x7c, 0x93, 0x58, 0x4a, 0xcb, 0x78, 0xd9, 0xa0, 0xa6, 0x98, 0x95, 0xe4, 0x10, 0x83,
+	0x5a, 0x14, 0x90, 0xbf, 0x59, 0x03, 0xca, 0x66, 0x04, 0x49, 0xae, 0xc9, 0x62, 0x36, 0x21, 0x28,
+	0xa8, 0x50, 0x3a, 0xd8, 0x49, 0xc5, 0x39, 0x09, 0xda, 0xcc, 0x74, 0x02, 0x83, 0xea, 0x14, 0x6f,
+	0x54, 0xe3, 0xcf, 0x3e, 0x07, 0x00, 0x24, 0xa1, 0x69, 0xd0, 0x17, 0x02, 0x00, 0x00,
+}
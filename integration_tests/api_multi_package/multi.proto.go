@@ -0,0 +1,836 @@
+// Code generated by xproto v0.1.0, DO NOT EDIT.
+// source: multi.proto
+// Package multi is a generated stub package.
+// This code was generated with github.com/donutloop/xservice v0.1.0
+// It is generated from these files:
+// 	 multi.proto
+// package multi
+
+package multi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/server"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/framework/xcontext"
+	common "github.com/donutloop/xservice/integration_tests/api_multi_package/common"
+	common1 "github.com/donutloop/xservice/integration_tests/api_multi_package/shared/common"
+	types "github.com/gogo/protobuf/types"
+)
+
+// ClockPathPrefix is used for all URL paths on a Clock server.
+// Requests are always: POST ClockPathPrefix /method
+// It can be used in an HTTP mux to route requests
+const ClockPathPrefix string = "/xservice/example.multi.Clock/"
+
+// StopwatchPathPrefix is used for all URL paths on a Stopwatch server.
+// Requests are always: POST StopwatchPathPrefix /method
+// It can be used in an HTTP mux to route requests
+const StopwatchPathPrefix string = "/xservice/example.multi.Stopwatch/"
+
+// 302 bytes of a gzipped FileDescriptorProto
+var xserviceFileDescriptor_a33188ebc3268bf7 = []byte{0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x9c, 0x90, 0x4f, 0x4b, 0x03, 0x31, 0x10, 0xc5, 0x29, 0xba, 0xa2, 0x29, 0x5e, 0x22, 0x56, 0x89, 0x07, 0xc5, 0x93, 0x20, 0x24, 0x50, 0x11, 0x95, 0xde, 0x2c, 0x45, 0x05, 0xf1, 0x60, 0x3d, 0x79, 0x29, 0xd9, 0x74, 0xdc, 0x0d, 0x4d, 0x76, 0xe2, 0xee, 0xac, 0xad, 0x9f, 0xcb, 0x2f, 0x28, 0xdd, 0x3f, 0x82, 0x5a, 0x2f, 0x9e, 0x92, 0xbc, 0xf7, 0x32, 0xef, 0x97, 0xb0, 0xae, 0x2f, 0x1d, 0x59, 0x19, 0x72, 0x24, 0xe4, 0xdb, 0xb0, 0xd0, 0x3e, 0x38, 0x90, 0x95, 0x28, 0x0e, 0x12, 0xc4, 0xc4, 0x81, 0xaa, 0xcc, 0xb8, 0x7c, 0x51, 0xe0, 0x03, 0xbd, 0xd7, 0x59, 0x71, 0xf8, 0xd3, 0x24, 0xeb, 0xa1, 0x20, 0xed, 0x43, 0x13, 0xd8, 0x31, 0xe8, 0x3d, 0x66, 0xaa, 0x5e, 0x1a, 0x51, 0x14, 0xa9, 0xce, 0x61, 0xaa, 0x56, 0x78, 0xc7, 0x17, 0x6c, 0xf3, 0x5e, 0xc7, 0xe0, 0x1e, 0xe1, 0x95, 0x9f, 0xb2, 0xc8, 0x2d, 0xf7, 0xfb, 0x9d, 0xa3, 0xce, 0x49, 0xb7, 0xbf, 0x2b, 0x5b, 0xb2, 0xfa, 0xbe, 0xac, 0x83, 0x75, 0xa6, 0xff, 0xd1, 0x61, 0xd1, 0xd0, 0xa1, 0x99, 0xf1, 0x2b, 0xb6, 0xf6, 0x80, 0x73, 0xde, 0x93, 0x35, 0x9c, 0x6c, 0xe1, 0xe4, 0x68, 0x49, 0x2e, 0xc4, 0x2f, 0xfd, 0xa9, 0x85, 0xe6, 0xe7, 0x6c, 0x7d, 0x64, 0x52, 0xe4, 0xbd, 0xaf, 0xaa, 0x06, 0x6e, 0x4c, 0x9a, 0xca, 0x42, 0xac, 0x46, 0xe0, 0x97, 0x2c, 0x1a, 0xa6, 0x60, 0x66, 0x7c, 0x4f, 0x7e, 0xfb, 0x3c, 0xd9, 0x3e, 0x45, 0xfc, 0x31, 0xb0, 0x7f, 0xcb, 0xb6, 0xc6, 0x84, 0x61, 0xae, 0xc9, 0xa4, 0x7c, 0xc0, 0xa2, 0x31, 0xe9, 0x9c, 0xfe, 0x83, 0x7e, 0x7d, 0xf7, 0x7c, 0x93, 0x58, 0x4a, 0xcb, 0x78, 0xd9, 0xa0, 0xa6, 0x98, 0x95, 0xe4, 0x10, 0x83, 0x5a, 0x14, 0x90, 0xbf, 0x59, 0x03, 0xca, 0x66, 0x04, 0x49, 0xae, 0xc9, 0x62, 0x36, 0x21, 0x28, 0xa8, 0x50, 0x3a, 0xd8, 0x49, 0xc5, 0x39, 0x09, 0xda, 0xcc, 0x74, 0x02, 0x83, 0xea, 0x14, 0x6f, 0x54, 0xe3, 0xcf, 0x3e, 0x07, 0x00, 0x24, 0xa1, 0x69, 0xd0, 0x17, 0x02, 0x00, 0x00}
+
+type Clock interface {
+	Now(ctx context.Context, req *types.Empty) (*types.Timestamp, error)
+
+	Echo(ctx context.Context, req *common.Status) (*common1.Label, error)
+
+	Check(ctx context.Context, req *LabelReq) (*common.Status, error)
+}
+type Stopwatch interface {
+	Start(ctx context.Context, req *types.Empty) (*types.Timestamp, error)
+}
+
+// clockJSONClient wraps an http.client and sends JSON objects
+type clockJSONClient struct {
+	client  transport.HTTPClient
+	urls    [3]string
+	options *transport.ClientOptions
+}
+
+// Now sends an types.Empty JSON object to the server
+func (c *clockJSONClient) Now(ctx context.Context, in *types.Empty) (*types.Timestamp, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.multi")
+	ctx = xcontext.WithServiceName(ctx, "Clock")
+	ctx = xcontext.WithMethodName(ctx, "Now")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(types.Timestamp)
+		err := c.options.DoJSONRequest(ctx, c.client, c.urls[0], req.(*types.Empty), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*types.Timestamp)
+	return out, err
+}
+
+// Echo sends an common.Status JSON object to the server
+func (c *clockJSONClient) Echo(ctx context.Context, in *common.Status) (*common1.Label, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.multi")
+	ctx = xcontext.WithServiceName(ctx, "Clock")
+	ctx = xcontext.WithMethodName(ctx, "Echo")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(common1.Label)
+		err := c.options.DoJSONRequest(ctx, c.client, c.urls[1], req.(*common.Status), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*common1.Label)
+	return out, err
+}
+
+// Check sends an LabelReq JSON object to the server
+func (c *clockJSONClient) Check(ctx context.Context, in *LabelReq) (*common.Status, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.multi")
+	ctx = xcontext.WithServiceName(ctx, "Clock")
+	ctx = xcontext.WithMethodName(ctx, "Check")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(common.Status)
+		err := c.options.DoJSONRequest(ctx, c.client, c.urls[2], req.(*LabelReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*common.Status)
+	return out, err
+}
+
+// clockProtobufferClient wraps an http.client and sends Protobuffer objects
+type clockProtobufferClient struct {
+	client  transport.HTTPClient
+	urls    [3]string
+	options *transport.ClientOptions
+}
+
+// Now sends an types.Empty Protobuffer object to the server
+func (c *clockProtobufferClient) Now(ctx context.Context, in *types.Empty) (*types.Timestamp, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.multi")
+	ctx = xcontext.WithServiceName(ctx, "Clock")
+	ctx = xcontext.WithMethodName(ctx, "Now")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(types.Timestamp)
+		err := c.options.DoProtobufferRequest(ctx, c.client, c.urls[0], req.(*types.Empty), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*types.Timestamp)
+	return out, err
+}
+
+// Echo sends an common.Status Protobuffer object to the server
+func (c *clockProtobufferClient) Echo(ctx context.Context, in *common.Status) (*common1.Label, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.multi")
+	ctx = xcontext.WithServiceName(ctx, "Clock")
+	ctx = xcontext.WithMethodName(ctx, "Echo")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(common1.Label)
+		err := c.options.DoProtobufferRequest(ctx, c.client, c.urls[1], req.(*common.Status), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*common1.Label)
+	return out, err
+}
+
+// Check sends an LabelReq Protobuffer object to the server
+func (c *clockProtobufferClient) Check(ctx context.Context, in *LabelReq) (*common.Status, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.multi")
+	ctx = xcontext.WithServiceName(ctx, "Clock")
+	ctx = xcontext.WithMethodName(ctx, "Check")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(common.Status)
+		err := c.options.DoProtobufferRequest(ctx, c.client, c.urls[2], req.(*LabelReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*common.Status)
+	return out, err
+}
+
+// clockLocalClient calls an implementation of Clock in-process, with the hooks and interceptors of a server
+type clockLocalClient struct {
+	svc     Clock
+	options *transport.ServerOptions
+}
+
+// Now calls Now of the service in-process
+func (c *clockLocalClient) Now(ctx context.Context, in *types.Empty) (*types.Timestamp, error) {
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out, err := c.svc.Now(ctx, req.(*types.Empty))
+		return out, err
+	}
+	resp, err := c.options.CallLocal(ctx, "example.multi", "Clock", "Now", in, call)
+	out, _ := resp.(*types.Timestamp)
+	return out, err
+}
+
+// Echo calls Echo of the service in-process
+func (c *clockLocalClient) Echo(ctx context.Context, in *common.Status) (*common1.Label, error) {
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out, err := c.svc.Echo(ctx, req.(*common.Status))
+		return out, err
+	}
+	resp, err := c.options.CallLocal(ctx, "example.multi", "Clock", "Echo", in, call)
+	out, _ := resp.(*common1.Label)
+	return out, err
+}
+
+// Check calls Check of the service in-process
+func (c *clockLocalClient) Check(ctx context.Context, in *LabelReq) (*common.Status, error) {
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out, err := c.svc.Check(ctx, req.(*LabelReq))
+		return out, err
+	}
+	resp, err := c.options.CallLocal(ctx, "example.multi", "Clock", "Check", in, call)
+	out, _ := resp.(*common.Status)
+	return out, err
+}
+
+// clockServer wraps an endpoint and implements http.Handler.
+type clockServer struct {
+	Clock
+	hooks        *hooks.ServerHooks
+	logErrorFunc transport.LogErrorFunc
+	options      *transport.ServerOptions
+	prefix       string
+}
+
+func (s *clockServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	s.options.WriteError(ctx, resp, err, s.hooks)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *clockServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	resp = transport.NewResponseWriter(resp)
+	ctx := req.Context()
+	ctx = xcontext.WithPackageName(ctx, "example.multi")
+	ctx = xcontext.WithServiceName(ctx, "Clock")
+	ctx = xcontext.WithResponseWriter(ctx, resp)
+	ctx = xcontext.WithHTTPRequest(ctx, req)
+	s.options.LimitRequestBody(req)
+	recoverWrapper := func() {
+		if r := recover(); r != nil {
+			s.options.HandlePanic(ctx, resp, r, s.hooks)
+		}
+	}
+	defer recoverWrapper()
+
+	var err error
+	ctx, err = transport.CallRequestReceived(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	err = s.options.CheckVersion(resp, req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	err = s.options.CheckMethod(req, false)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	switch req.URL.Path {
+	case s.prefix + "Now":
+		s.serveNow(ctx, resp, req)
+		return
+	case s.prefix + "Echo":
+		s.serveEcho(ctx, resp, req)
+		return
+	case s.prefix + "Check":
+		s.serveCheck(ctx, resp, req)
+		return
+
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		terr := errors.BadRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+
+}
+
+// serveNow is used to set an decoder and encoder for a given content type
+func (s *clockServer) serveNow(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	codec, err := s.options.RequestCodec(req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	respCodec, err := s.options.ResponseCodec(req, codec)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	ctx = xcontext.WithResponseContentType(ctx, respCodec.ContentType())
+	s.serveNowContent(ctx, resp, req, transport.DecodeRequestWith(codec), transport.EncodeResponseWith(respCodec))
+}
+
+// serveNowContent sends object to requester
+func (s *clockServer) serveNowContent(ctx context.Context, resp http.ResponseWriter, req *http.Request, decodeRequest transport.DecodeRequestFunc, encodeResponse transport.EncodeResponseFunc) {
+	var err error
+	ctx = xcontext.WithMethodName(ctx, "Now")
+	ctx, err = transport.CallRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	defer transport.Closebody(req.Body, s.logErrorFunc)
+
+	reqContent := new(types.Empty)
+	if err := decodeRequest(ctx, req, reqContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	endpointWrapper := func() (respContent *types.Timestamp, err error) {
+		deferWrapper := func() {
+			if r := recover(); r != nil {
+				err = s.options.RecoverPanic(ctx, r)
+			}
+		}
+		defer deferWrapper()
+
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			out, err := s.Now(ctx, req.(*types.Empty))
+			return out, err
+		}
+		out, callErr := s.options.Intercept(call)(ctx, reqContent)
+		content, _ := out.(*types.Timestamp)
+		return content, callErr
+	}
+	respContent, err := endpointWrapper()
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		terr := errors.InternalError("received a nil * types.Timestamp, and nil error while calling Now. nil responses are not supported")
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+	ctx = transport.CallResponsePrepared(ctx, s.hooks)
+	if err := encodeResponse(ctx, resp, respContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	transport.CallResponseSent(ctx, s.hooks)
+}
+
+// serveEcho is used to set an decoder and encoder for a given content type
+func (s *clockServer) serveEcho(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	codec, err := s.options.RequestCodec(req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	respCodec, err := s.options.ResponseCodec(req, codec)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	ctx = xcontext.WithResponseContentType(ctx, respCodec.ContentType())
+	s.serveEchoContent(ctx, resp, req, transport.DecodeRequestWith(codec), transport.EncodeResponseWith(respCodec))
+}
+
+// serveEchoContent sends object to requester
+func (s *clockServer) serveEchoContent(ctx context.Context, resp http.ResponseWriter, req *http.Request, decodeRequest transport.DecodeRequestFunc, encodeResponse transport.EncodeResponseFunc) {
+	var err error
+	ctx = xcontext.WithMethodName(ctx, "Echo")
+	ctx, err = transport.CallRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	defer transport.Closebody(req.Body, s.logErrorFunc)
+
+	reqContent := new(common.Status)
+	if err := decodeRequest(ctx, req, reqContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	endpointWrapper := func() (respContent *common1.Label, err error) {
+		deferWrapper := func() {
+			if r := recover(); r != nil {
+				err = s.options.RecoverPanic(ctx, r)
+			}
+		}
+		defer deferWrapper()
+
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			out, err := s.Echo(ctx, req.(*common.Status))
+			return out, err
+		}
+		out, callErr := s.options.Intercept(call)(ctx, reqContent)
+		content, _ := out.(*common1.Label)
+		return content, callErr
+	}
+	respContent, err := endpointWrapper()
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		terr := errors.InternalError("received a nil * common1.Label, and nil error while calling Echo. nil responses are not supported")
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+	ctx = transport.CallResponsePrepared(ctx, s.hooks)
+	if err := encodeResponse(ctx, resp, respContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	transport.CallResponseSent(ctx, s.hooks)
+}
+
+// serveCheck is used to set an decoder and encoder for a given content type
+func (s *clockServer) serveCheck(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	codec, err := s.options.RequestCodec(req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	respCodec, err := s.options.ResponseCodec(req, codec)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	ctx = xcontext.WithResponseContentType(ctx, respCodec.ContentType())
+	s.serveCheckContent(ctx, resp, req, transport.DecodeRequestWith(codec), transport.EncodeResponseWith(respCodec))
+}
+
+// serveCheckContent sends object to requester
+func (s *clockServer) serveCheckContent(ctx context.Context, resp http.ResponseWriter, req *http.Request, decodeRequest transport.DecodeRequestFunc, encodeResponse transport.EncodeResponseFunc) {
+	var err error
+	ctx = xcontext.WithMethodName(ctx, "Check")
+	ctx, err = transport.CallRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	defer transport.Closebody(req.Body, s.logErrorFunc)
+
+	reqContent := new(LabelReq)
+	if err := decodeRequest(ctx, req, reqContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	endpointWrapper := func() (respContent *common.Status, err error) {
+		deferWrapper := func() {
+			if r := recover(); r != nil {
+				err = s.options.RecoverPanic(ctx, r)
+			}
+		}
+		defer deferWrapper()
+
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			out, err := s.Check(ctx, req.(*LabelReq))
+			return out, err
+		}
+		out, callErr := s.options.Intercept(call)(ctx, reqContent)
+		content, _ := out.(*common.Status)
+		return content, callErr
+	}
+	respContent, err := endpointWrapper()
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		terr := errors.InternalError("received a nil * common.Status, and nil error while calling Check. nil responses are not supported")
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+	ctx = transport.CallResponsePrepared(ctx, s.hooks)
+	if err := encodeResponse(ctx, resp, respContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	transport.CallResponseSent(ctx, s.hooks)
+}
+
+// ServiceDescriptor describes an service.
+func (s *clockServer) ServiceDescriptor() ([]uint8, int) {
+	return xserviceFileDescriptor_a33188ebc3268bf7, 0
+}
+
+// ProtocGenXServiceVersion returns which xservice version was used to generate that service
+func (s *clockServer) ProtocGenXServiceVersion() string {
+	return "v0.1.0"
+}
+
+// stopwatchJSONClient wraps an http.client and sends JSON objects
+type stopwatchJSONClient struct {
+	client  transport.HTTPClient
+	urls    [1]string
+	options *transport.ClientOptions
+}
+
+// Start sends an types.Empty JSON object to the server
+func (c *stopwatchJSONClient) Start(ctx context.Context, in *types.Empty) (*types.Timestamp, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.multi")
+	ctx = xcontext.WithServiceName(ctx, "Stopwatch")
+	ctx = xcontext.WithMethodName(ctx, "Start")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(types.Timestamp)
+		err := c.options.DoJSONRequest(ctx, c.client, c.urls[0], req.(*types.Empty), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*types.Timestamp)
+	return out, err
+}
+
+// stopwatchProtobufferClient wraps an http.client and sends Protobuffer objects
+type stopwatchProtobufferClient struct {
+	client  transport.HTTPClient
+	urls    [1]string
+	options *transport.ClientOptions
+}
+
+// Start sends an types.Empty Protobuffer object to the server
+func (c *stopwatchProtobufferClient) Start(ctx context.Context, in *types.Empty) (*types.Timestamp, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.multi")
+	ctx = xcontext.WithServiceName(ctx, "Stopwatch")
+	ctx = xcontext.WithMethodName(ctx, "Start")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(types.Timestamp)
+		err := c.options.DoProtobufferRequest(ctx, c.client, c.urls[0], req.(*types.Empty), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*types.Timestamp)
+	return out, err
+}
+
+// stopwatchLocalClient calls an implementation of Stopwatch in-process, with the hooks and interceptors of a server
+type stopwatchLocalClient struct {
+	svc     Stopwatch
+	options *transport.ServerOptions
+}
+
+// Start calls Start of the service in-process
+func (c *stopwatchLocalClient) Start(ctx context.Context, in *types.Empty) (*types.Timestamp, error) {
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out, err := c.svc.Start(ctx, req.(*types.Empty))
+		return out, err
+	}
+	resp, err := c.options.CallLocal(ctx, "example.multi", "Stopwatch", "Start", in, call)
+	out, _ := resp.(*types.Timestamp)
+	return out, err
+}
+
+// stopwatchServer wraps an endpoint and implements http.Handler.
+type stopwatchServer struct {
+	Stopwatch
+	hooks        *hooks.ServerHooks
+	logErrorFunc transport.LogErrorFunc
+	options      *transport.ServerOptions
+	prefix       string
+}
+
+func (s *stopwatchServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	s.options.WriteError(ctx, resp, err, s.hooks)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *stopwatchServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	resp = transport.NewResponseWriter(resp)
+	ctx := req.Context()
+	ctx = xcontext.WithPackageName(ctx, "example.multi")
+	ctx = xcontext.WithServiceName(ctx, "Stopwatch")
+	ctx = xcontext.WithResponseWriter(ctx, resp)
+	ctx = xcontext.WithHTTPRequest(ctx, req)
+	s.options.LimitRequestBody(req)
+	recoverWrapper := func() {
+		if r := recover(); r != nil {
+			s.options.HandlePanic(ctx, resp, r, s.hooks)
+		}
+	}
+	defer recoverWrapper()
+
+	var err error
+	ctx, err = transport.CallRequestReceived(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	err = s.options.CheckVersion(resp, req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	err = s.options.CheckMethod(req, false)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	switch req.URL.Path {
+	case s.prefix + "Start":
+		s.serveStart(ctx, resp, req)
+		return
+
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		terr := errors.BadRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+
+}
+
+// serveStart is used to set an decoder and encoder for a given content type
+func (s *stopwatchServer) serveStart(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	codec, err := s.options.RequestCodec(req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	respCodec, err := s.options.ResponseCodec(req, codec)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	ctx = xcontext.WithResponseContentType(ctx, respCodec.ContentType())
+	s.serveStartContent(ctx, resp, req, transport.DecodeRequestWith(codec), transport.EncodeResponseWith(respCodec))
+}
+
+// serveStartContent sends object to requester
+func (s *stopwatchServer) serveStartContent(ctx context.Context, resp http.ResponseWriter, req *http.Request, decodeRequest transport.DecodeRequestFunc, encodeResponse transport.EncodeResponseFunc) {
+	var err error
+	ctx = xcontext.WithMethodName(ctx, "Start")
+	ctx, err = transport.CallRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	defer transport.Closebody(req.Body, s.logErrorFunc)
+
+	reqContent := new(types.Empty)
+	if err := decodeRequest(ctx, req, reqContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	endpointWrapper := func() (respContent *types.Timestamp, err error) {
+		deferWrapper := func() {
+			if r := recover(); r != nil {
+				err = s.options.RecoverPanic(ctx, r)
+			}
+		}
+		defer deferWrapper()
+
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			out, err := s.Start(ctx, req.(*types.Empty))
+			return out, err
+		}
+		out, callErr := s.options.Intercept(call)(ctx, reqContent)
+		content, _ := out.(*types.Timestamp)
+		return content, callErr
+	}
+	respContent, err := endpointWrapper()
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		terr := errors.InternalError("received a nil * types.Timestamp, and nil error while calling Start. nil responses are not supported")
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+	ctx = transport.CallResponsePrepared(ctx, s.hooks)
+	if err := encodeResponse(ctx, resp, respContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	transport.CallResponseSent(ctx, s.hooks)
+}
+
+// ServiceDescriptor describes an service.
+func (s *stopwatchServer) ServiceDescriptor() ([]uint8, int) {
+	return xserviceFileDescriptor_a33188ebc3268bf7, 1
+}
+
+// ProtocGenXServiceVersion returns which xservice version was used to generate that service
+func (s *stopwatchServer) ProtocGenXServiceVersion() string {
+	return "v0.1.0"
+}
+
+// NewClockJSONClient constructs a new client, which wraps the http.client and implements Clock
+func NewClockJSONClient(addr string, client transport.HTTPClient) Clock {
+	return NewClockJSONClientWithOptions(addr, client)
+}
+
+// NewClockJSONClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements Clock
+func NewClockJSONClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) Clock {
+	options := transport.NewClientOptions(opts...)
+	URLBase := transport.UrlBase(addr)
+	prefix := URLBase + options.ServicePathPrefix(ClockPathPrefix, "example.multi.Clock")
+	urls := [3]string{
+		prefix + "Now",
+		prefix + "Echo",
+		prefix + "Check",
+	}
+	return &clockJSONClient{
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
+	}
+}
+
+// NewClockProtobufferClient constructs a new client, which wraps the http.client and implements Clock
+func NewClockProtobufferClient(addr string, client transport.HTTPClient) Clock {
+	return NewClockProtobufferClientWithOptions(addr, client)
+}
+
+// NewClockProtobufferClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements Clock
+func NewClockProtobufferClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) Clock {
+	options := transport.NewClientOptions(opts...)
+	URLBase := transport.UrlBase(addr)
+	prefix := URLBase + options.ServicePathPrefix(ClockPathPrefix, "example.multi.Clock")
+	urls := [3]string{
+		prefix + "Now",
+		prefix + "Echo",
+		prefix + "Check",
+	}
+	return &clockProtobufferClient{
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
+	}
+}
+
+// NewClockLocalClient constructs a client which implements Clock by calling svc in-process. It calls the hooks
+// and populates the context like NewClockServer.
+func NewClockLocalClient(svc Clock, hooks *hooks.ServerHooks) Clock {
+	return NewClockLocalClientWithOptions(svc, transport.WithServerHooks(hooks))
+}
+
+// NewClockLocalClientWithOptions constructs a client like NewClockLocalClient, configured by server options. With transport.WithLocalRoundTrip svc receives and
+// returns copies of the messages.
+func NewClockLocalClientWithOptions(svc Clock, opts ...transport.ServerOption) Clock {
+	return &clockLocalClient{
+		svc:     svc,
+		options: transport.NewServerOptions(opts...),
+	}
+}
+
+// NewClockServer constructs a new server, and implements Clock
+func NewClockServer(svc Clock, hooks *hooks.ServerHooks, errorFunc ...transport.LogErrorFunc) server.Server {
+	opts := make([]transport.ServerOption, 0, 2)
+	opts = append(opts, transport.WithServerHooks(hooks))
+	if len(errorFunc) == 1 {
+		opts = append(opts, transport.WithServerLogger(errorFunc[0]))
+	}
+	return NewClockServerWithOptions(svc, opts...)
+}
+
+// NewClockServerWithOptions constructs a new server configured by opts, and implements Clock
+func NewClockServerWithOptions(svc Clock, opts ...transport.ServerOption) server.Server {
+	options := transport.NewServerOptions(opts...)
+	return &clockServer{
+		Clock:        svc,
+		hooks:        options.Hooks(),
+		logErrorFunc: options.Logger(),
+		options:      options,
+		prefix:       options.ServicePathPrefix(ClockPathPrefix, "example.multi.Clock"),
+	}
+}
+
+// NewStopwatchJSONClient constructs a new client, which wraps the http.client and implements Stopwatch
+func NewStopwatchJSONClient(addr string, client transport.HTTPClient) Stopwatch {
+	return NewStopwatchJSONClientWithOptions(addr, client)
+}
+
+// NewStopwatchJSONClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements Stopwatch
+func NewStopwatchJSONClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) Stopwatch {
+	options := transport.NewClientOptions(opts...)
+	URLBase := transport.UrlBase(addr)
+	prefix := URLBase + options.ServicePathPrefix(StopwatchPathPrefix, "example.multi.Stopwatch")
+	urls := [1]string{
+		prefix + "Start",
+	}
+	return &stopwatchJSONClient{
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
+	}
+}
+
+// NewStopwatchProtobufferClient constructs a new client, which wraps the http.client and implements Stopwatch
+func NewStopwatchProtobufferClient(addr string, client transport.HTTPClient) Stopwatch {
+	return NewStopwatchProtobufferClientWithOptions(addr, client)
+}
+
+// NewStopwatchProtobufferClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements Stopwatch
+func NewStopwatchProtobufferClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) Stopwatch {
+	options := transport.NewClientOptions(opts...)
+	URLBase := transport.UrlBase(addr)
+	prefix := URLBase + options.ServicePathPrefix(StopwatchPathPrefix, "example.multi.Stopwatch")
+	urls := [1]string{
+		prefix + "Start",
+	}
+	return &stopwatchProtobufferClient{
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
+	}
+}
+
+// NewStopwatchLocalClient constructs a client which implements Stopwatch by calling svc in-process. It calls the hooks
+// and populates the context like NewStopwatchServer.
+func NewStopwatchLocalClient(svc Stopwatch, hooks *hooks.ServerHooks) Stopwatch {
+	return NewStopwatchLocalClientWithOptions(svc, transport.WithServerHooks(hooks))
+}
+
+// NewStopwatchLocalClientWithOptions constructs a client like NewStopwatchLocalClient, configured by server options. With transport.WithLocalRoundTrip svc receives and
+// returns copies of the messages.
+func NewStopwatchLocalClientWithOptions(svc Stopwatch, opts ...transport.ServerOption) Stopwatch {
+	return &stopwatchLocalClient{
+		svc:     svc,
+		options: transport.NewServerOptions(opts...),
+	}
+}
+
+// NewStopwatchServer constructs a new server, and implements Stopwatch
+func NewStopwatchServer(svc Stopwatch, hooks *hooks.ServerHooks, errorFunc ...transport.LogErrorFunc) server.Server {
+	opts := make([]transport.ServerOption, 0, 2)
+	opts = append(opts, transport.WithServerHooks(hooks))
+	if len(errorFunc) == 1 {
+		opts = append(opts, transport.WithServerLogger(errorFunc[0]))
+	}
+	return NewStopwatchServerWithOptions(svc, opts...)
+}
+
+// NewStopwatchServerWithOptions constructs a new server configured by opts, and implements Stopwatch
+func NewStopwatchServerWithOptions(svc Stopwatch, opts ...transport.ServerOption) server.Server {
+	options := transport.NewServerOptions(opts...)
+	return &stopwatchServer{
+		Stopwatch:    svc,
+		hooks:        options.Hooks(),
+		logErrorFunc: options.Logger(),
+		options:      options,
+		prefix:       options.ServicePathPrefix(StopwatchPathPrefix, "example.multi.Stopwatch"),
+	}
+}
+func init() {
+	server.RegisterServiceDescriptor("example.multi.Clock", xserviceFileDescriptor_a33188ebc3268bf7, 0)
+	server.RegisterServiceDescriptor("example.multi.Stopwatch", xserviceFileDescriptor_a33188ebc3268bf7, 1)
+}
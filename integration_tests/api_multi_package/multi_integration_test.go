@@ -0,0 +1,125 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package multi_test
+
+import (
+	"context"
+	"github.com/donutloop/xservice/framework/server"
+	"github.com/donutloop/xservice/integration_tests/api_multi_package"
+	"github.com/donutloop/xservice/integration_tests/api_multi_package/common"
+	shared "github.com/donutloop/xservice/integration_tests/api_multi_package/shared/common"
+	"github.com/gogo/protobuf/types"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type ClockServer struct{}
+
+func (s *ClockServer) Now(ctx context.Context, req *types.Empty) (*types.Timestamp, error) {
+	return &types.Timestamp{Seconds: 1528329600}, nil
+}
+
+func (s *ClockServer) Echo(ctx context.Context, req *common.Status) (*shared.Label, error) {
+	return &shared.Label{Name: req.Message}, nil
+}
+
+func (s *ClockServer) Check(ctx context.Context, req *multi.LabelReq) (*common.Status, error) {
+	return &common.Status{Message: "checked " + req.Label.GetName()}, nil
+}
+
+func TestMultiPackageCalls(t *testing.T) {
+	server := httptest.NewServer(multi.NewClockServer(&ClockServer{}, nil))
+	defer server.Close()
+
+	clients := map[string]multi.Clock{
+		"json":        multi.NewClockJSONClient(server.URL, &http.Client{}),
+		"protobuffer": multi.NewClockProtobufferClient(server.URL, &http.Client{}),
+	}
+
+	for name, client := range clients {
+		now, err := client.Now(context.Background(), &types.Empty{})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if now.Seconds != 1528329600 {
+			t.Errorf(`%s: unexpected seconds (actual: %d, expected: %d)`, name, now.Seconds, 1528329600)
+		}
+
+		label, err := client.Echo(context.Background(), &common.Status{Message: "ok"})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if label.Name != "ok" {
+			t.Errorf(`%s: unexpected name (actual: "%s", expected: "%s")`, name, label.Name, "ok")
+		}
+
+		status, err := client.Check(context.Background(), &multi.LabelReq{Label: &shared.Label{Name: "clock"}})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if status.Message != "checked clock" {
+			t.Errorf(`%s: unexpected message (actual: "%s", expected: "%s")`, name, status.Message, "checked clock")
+		}
+	}
+}
+
+type StopwatchServer struct{}
+
+func (s *StopwatchServer) Start(ctx context.Context, req *types.Empty) (*types.Timestamp, error) {
+	return &types.Timestamp{}, nil
+}
+
+func TestMultiPackageServiceDescriptors(t *testing.T) {
+	servers := map[string]server.Server{
+		"example.multi.Clock":     multi.NewClockServer(&ClockServer{}, nil),
+		"example.multi.Stopwatch": multi.NewStopwatchServer(&StopwatchServer{}, nil),
+	}
+
+	for name, s := range servers {
+		fd, sd, err := server.ExtractServiceDescriptor(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if fd.GetName() != "multi.proto" {
+			t.Errorf(`unexpected file (actual: "%s", expected: "%s")`, fd.GetName(), "multi.proto")
+		}
+		if fullName := fd.GetPackage() + "." + sd.GetName(); fullName != name {
+			t.Errorf(`unexpected service (actual: "%s", expected: "%s")`, fullName, name)
+		}
+
+		_, registered, err := server.LookupServiceDescriptor(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if registered.GetName() != sd.GetName() {
+			t.Errorf(`unexpected registered service (actual: "%s", expected: "%s")`, registered.GetName(), sd.GetName())
+		}
+	}
+
+	method, err := server.LookupMethodDescriptor("example.multi.Stopwatch", "Start")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if method.GetOutputType() != ".google.protobuf.Timestamp" {
+		t.Errorf(`unexpected output type (actual: "%s", expected: "%s")`, method.GetOutputType(), ".google.protobuf.Timestamp")
+	}
+
+	if _, err := server.LookupMethodDescriptor("example.multi.Clock", "Start"); err == nil {
+		t.Error("expected error for unknown method")
+	}
+	if _, _, err := server.LookupServiceDescriptor("example.multi.Unknown"); err == nil {
+		t.Error("expected error for unregistered service")
+	}
+}
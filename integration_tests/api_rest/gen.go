@@ -0,0 +1,5 @@
+package users
+
+// The messages are generated by xservice, the google.api.http annotations
+// are imported from the proto directory of xservice.
+//go:generate protoc -I . -I ../../proto ./users.proto --xservice_out=messages=true:.
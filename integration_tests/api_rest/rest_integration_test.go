@@ -0,0 +1,208 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package users_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/integration_tests/api_rest"
+	"github.com/gogo/protobuf/proto"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type UsersServer struct {
+	mu    sync.Mutex
+	users map[string]*users.User
+}
+
+func NewUsersServer() *UsersServer {
+	return &UsersServer{users: make(map[string]*users.User)}
+}
+
+func (s *UsersServer) GetUser(ctx context.Context, req *users.GetUserReq) (*users.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := *s.users[req.Id]
+	if !req.Verbose {
+		user.DisplayName = ""
+	}
+	return &user, nil
+}
+
+func (s *UsersServer) UpdateUser(ctx context.Context, req *users.UpdateUserReq) (*users.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := *req.User
+	user.Id = req.Id
+	s.users[req.Id] = &user
+	return &user, nil
+}
+
+func (s *UsersServer) CreateUser(ctx context.Context, req *users.User) (*users.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[req.Id] = req
+	return req, nil
+}
+
+func (s *UsersServer) Ping(ctx context.Context, req *users.User) (*users.User, error) {
+	return req, nil
+}
+
+// recordingClient records the requests of a client with their bodies.
+type recordingClient struct {
+	requests []*http.Request
+	bodies   []string
+}
+
+func (c *recordingClient) Do(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		c.bodies = append(c.bodies, string(body))
+	} else {
+		c.bodies = append(c.bodies, "")
+	}
+	c.requests = append(c.requests, req)
+	return http.DefaultClient.Do(req)
+}
+
+func TestRESTClientRoundTrip(t *testing.T) {
+	server := httptest.NewServer(users.NewUsersServer(NewUsersServer(), nil))
+	defer server.Close()
+
+	recorder := &recordingClient{}
+	client := users.NewUsersRESTClient(server.URL, recorder)
+	ctx := context.Background()
+
+	created, err := client.CreateUser(ctx, &users.User{Id: "a/b", Name: "ann", DisplayName: "Ann"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if !proto.Equal(created, &users.User{Id: "a/b", Name: "ann", DisplayName: "Ann"}) {
+		t.Errorf("create: unexpected user (actual: %v)", created)
+	}
+
+	user, err := client.GetUser(ctx, &users.GetUserReq{Id: "a/b", Verbose: true})
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if !proto.Equal(user, created) {
+		t.Errorf("get: unexpected user (actual: %v, expected: %v)", user, created)
+	}
+
+	updated, err := client.UpdateUser(ctx, &users.UpdateUserReq{Id: "a/b", User: &users.User{Name: "bob"}})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !proto.Equal(updated, &users.User{Id: "a/b", Name: "bob"}) {
+		t.Errorf("update: unexpected user (actual: %v)", updated)
+	}
+
+	pong, err := client.Ping(ctx, &users.User{Name: "ping"})
+	if err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	if pong.Name != "ping" {
+		t.Errorf("ping: unexpected user (actual: %v)", pong)
+	}
+
+	expected := []struct {
+		method, path, query, body string
+	}{
+		{method: "POST", path: "/v1/users", body: `{"id":"a/b","name":"ann","display_name":"Ann"}`},
+		{method: "GET", path: "/v1/users/a%2Fb", query: "verbose=true"},
+		{method: "PATCH", path: "/v1/users/a%2Fb", body: `{"name":"bob"}`},
+		{method: "POST", path: "/xservice/example.users.Users/Ping", body: `{"name":"ping"}`},
+	}
+	if len(recorder.requests) != len(expected) {
+		t.Fatalf("unexpected number of requests (actual: %d, expected: %d)", len(recorder.requests), len(expected))
+	}
+	for i, e := range expected {
+		req := recorder.requests[i]
+		if req.Method != e.method || req.URL.EscapedPath() != e.path || req.URL.RawQuery != e.query || recorder.bodies[i] != e.body {
+			t.Errorf("unexpected request %d (actual: %s %s?%s %s, expected: %s %s?%s %s)", i, req.Method, req.URL.EscapedPath(), req.URL.RawQuery, recorder.bodies[i], e.method, e.path, e.query, e.body)
+		}
+	}
+}
+
+func TestRESTClientJSONOptions(t *testing.T) {
+	server := httptest.NewServer(users.NewUsersServer(NewUsersServer(), nil))
+	defer server.Close()
+
+	recorder := &recordingClient{}
+	client := users.NewUsersRESTClientWithOptions(server.URL, recorder, transport.WithClientJSONOptions(transport.JSONOptions{CamelCase: true}))
+	ctx := context.Background()
+
+	if _, err := client.CreateUser(ctx, &users.User{Id: "1", DisplayName: "Ann"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	updated, err := client.UpdateUser(ctx, &users.UpdateUserReq{Id: "1", User: &users.User{DisplayName: "Bob"}})
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if updated.DisplayName != "Bob" {
+		t.Errorf("update: unexpected user (actual: %v)", updated)
+	}
+
+	expected := []string{`{"id":"1","displayName":"Ann"}`, `{"displayName":"Bob"}`}
+	for i, body := range expected {
+		if recorder.bodies[i] != body {
+			t.Errorf("unexpected body of request %d (actual: %s, expected: %s)", i, recorder.bodies[i], body)
+		}
+	}
+}
+
+func TestRESTAdditionalBindings(t *testing.T) {
+	server := httptest.NewServer(users.NewUsersServer(NewUsersServer(), nil))
+	defer server.Close()
+
+	tests := []struct {
+		method, path, body string
+		response           string
+	}{
+		{method: "PUT", path: "/v1/users/1", body: `{"name":"ann"}`, response: `{"id":"1","name":"ann"}`},
+		{method: "GET", path: "/v1/me/1", response: `{"id":"1","name":"ann"}`},
+		{method: "DELETE", path: "/v1/users/1", response: `"code":"bad_route"`},
+	}
+
+	for _, test := range tests {
+		req, err := http.NewRequest(test.method, server.URL+test.path, strings.NewReader(test.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(body), test.response) {
+			t.Errorf("%s %s: unexpected response (actual: %s, expected: %s)", test.method, test.path, body, test.response)
+		}
+	}
+}
@@ -0,0 +1,907 @@
+// Code generated by xproto v0.1.0, DO NOT EDIT.
+// source: users.proto
+// Package users is a generated stub package.
+// This code was generated with github.com/donutloop/xservice v0.1.0
+// It is generated from these files:
+// 	 users.proto
+// package users
+
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/donutloop/xservice/framework/errors"
+	"github.com/donutloop/xservice/framework/hooks"
+	"github.com/donutloop/xservice/framework/rest"
+	"github.com/donutloop/xservice/framework/server"
+	"github.com/donutloop/xservice/framework/transport"
+	"github.com/donutloop/xservice/framework/xcontext"
+	"github.com/gogo/protobuf/proto"
+)
+
+// UsersPathPrefix is used for all URL paths on a Users server.
+// Requests are always: POST UsersPathPrefix /method
+// It can be used in an HTTP mux to route requests
+const UsersPathPrefix string = "/xservice/example.users.Users/"
+
+// usersRESTRouter routes the RESTful routes of the google.api.http annotations of Users.
+var usersRESTRouter = rest.MustNewRouter(
+	rest.Route{Name: "GetUser", Rule: rest.Rule{Method: "GET", Pattern: "/v1/users/{id}"}},
+	rest.Route{Name: "GetUser", Rule: rest.Rule{Method: "GET", Pattern: "/v1/me/{id}"}},
+	rest.Route{Name: "UpdateUser", Rule: rest.Rule{Method: "PATCH", Pattern: "/v1/users/{id}", Body: "user"}},
+	rest.Route{Name: "CreateUser", Rule: rest.Rule{Method: "POST", Pattern: "/v1/users", Body: "*"}},
+	rest.Route{Name: "CreateUser", Rule: rest.Rule{Method: "PUT", Pattern: "/v1/users/{id}", Body: "*"}},
+)
+
+// 324 bytes of a gzipped FileDescriptorProto
+var xserviceFileDescriptor_030765f334c86cea = []byte{0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x8c, 0x92, 0xc1, 0x4a, 0xf3, 0x50, 0x10, 0x85, 0x49, 0xfe, 0xf4, 0xaf, 0x9d, 0xd8, 0x2e, 0xa6, 0x20, 0xb5, 0x14, 0xd4, 0x88, 0xa8, 0x5d, 0x24, 0x5a, 0xc1, 0x45, 0x97, 0xba, 0xd0, 0x8d, 0x22, 0x85, 0x6e, 0x0a, 0xa2, 0xb7, 0x64, 0x08, 0x17, 0x92, 0xdc, 0x98, 0x1b, 0x8b, 0x22, 0x6e, 0x7c, 0x05, 0x1f, 0xcd, 0xb5, 0x3b, 0x1f, 0x44, 0x32, 0xa6, 0x56, 0x63, 0x04, 0x77, 0x39, 0xe7, 0xce, 0x7c, 0x67, 0x0e, 0x04, 0xec, 0x5b, 0x4d, 0xa9, 0x76, 0x93, 0x54, 0x65, 0x0a, 0x9b, 0x74, 0x27, 0xa2, 0x24, 0x24, 0x97, 0xcd, 0x6e, 0x2f, 0x50, 0x2a, 0x08, 0xc9, 0x13, 0x89, 0xf4, 0x44, 0x1c, 0xab, 0x4c, 0x64, 0x52, 0xc5, 0xc5, 0xb0, 0x73, 0x06, 0xd6, 0x58, 0x53, 0x8a, 0x2d, 0x30, 0xa5, 0xdf, 0x31, 0xd6, 0x8d, 0x9d, 0xc6, 0xc8, 0x94, 0x3e, 0x22, 0x58, 0xb1, 0x88, 0xa8, 0x63, 0xb2, 0xc3, 0xdf, 0xb8, 0x01, 0xcb, 0xbe, 0xd4, 0x49, 0x28, 0xee, 0xaf, 0xf8, 0xed, 0x1f, 0xbf, 0xd9, 0x85, 0x77, 0x2e, 0x22, 0x72, 0x0e, 0x01, 0x4e, 0x28, 0xcb, 0x89, 0x23, 0xba, 0xf9, 0x01, 0xed, 0x40, 0x7d, 0x46, 0xe9, 0x54, 0xe9, 0x0f, 0xee, 0xd2, 0x68, 0x2e, 0x9d, 0x53, 0x68, 0x8e, 0x13, 0x5f, 0x64, 0xf4, 0xdb, 0xea, 0x36, 0x58, 0x79, 0x1d, 0xde, 0xb3, 0x07, 0x6d, 0xf7, 0x5b, 0x47, 0x97, 0xb7, 0x78, 0x60, 0xf0, 0x6a, 0x42, 0x2d, 0x97, 0x1a, 0xaf, 0xa1, 0x5e, 0xdc, 0x82, 0xab, 0xa5, 0xf9, 0xc5, 0x8d, 0xdd, 0x2a, 0x94, 0xb3, 0xf5, 0xf4, 0xf2, 0xf6, 0x6c, 0xae, 0x61, 0xcb, 0x9b, 0xed, 0x7b, 0xec, 0x7b, 0x0f, 0xd2, 0x7f, 0x9c, 0x34, 0xd1, 0xce, 0x9d, 0x88, 0x58, 0xe2, 0x25, 0xc0, 0xe2, 0x6a, 0xec, 0x95, 0x49, 0x5f, 0x0b, 0x55, 0xe7, 0xf4, 0x38, 0x67, 0x65, 0x50, 0xca, 0x19, 0x72, 0x15, 0x14, 0x00, 0xc7, 0x29, 0xcd, 0xf1, 0x55, 0x80, 0x6a, 0xea, 0x2e, 0x53, 0x37, 0x9d, 0xc6, 0x27, 0x75, 0x68, 0xf4, 0x27, 0xed, 0x6e, 0x39, 0xc5, 0xe8, 0xe3, 0x1e, 0x58, 0x17, 0x32, 0x0e, 0xfe, 0x0e, 0x3f, 0xaa, 0x4f, 0x6a, 0xac, 0xa6, 0xff, 0xf9, 0x07, 0x3a, 0x78, 0x1f, 0x00, 0x67, 0xd9, 0xf0, 0x22, 0x7c, 0x02, 0x00, 0x00}
+
+type Users interface {
+	GetUser(ctx context.Context, req *GetUserReq) (*User, error)
+
+	UpdateUser(ctx context.Context, req *UpdateUserReq) (*User, error)
+
+	CreateUser(ctx context.Context, req *User) (*User, error)
+
+	Ping(ctx context.Context, req *User) (*User, error)
+}
+
+type User struct {
+	Id          string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	DisplayName string `protobuf:"bytes,3,opt,name=display_name,json=displayName,proto3" json:"display_name,omitempty"`
+}
+
+func (m *User) Reset() {
+	*m = User{}
+}
+
+func (m *User) String() string {
+	return proto.CompactTextString(m)
+}
+
+func (m *User) ProtoMessage() {
+}
+
+func (m *User) Descriptor() ([]byte, []int) {
+	return xserviceFileDescriptor_030765f334c86cea, []int{0}
+}
+
+func (m *User) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *User) GetName() string {
+	if m != nil {
+		return m.Name
+	}
+	return ""
+}
+
+func (m *User) GetDisplayName() string {
+	if m != nil {
+		return m.DisplayName
+	}
+	return ""
+}
+
+type GetUserReq struct {
+	Id      string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Verbose bool   `protobuf:"varint,2,opt,name=verbose,proto3" json:"verbose,omitempty"`
+}
+
+func (m *GetUserReq) Reset() {
+	*m = GetUserReq{}
+}
+
+func (m *GetUserReq) String() string {
+	return proto.CompactTextString(m)
+}
+
+func (m *GetUserReq) ProtoMessage() {
+}
+
+func (m *GetUserReq) Descriptor() ([]byte, []int) {
+	return xserviceFileDescriptor_030765f334c86cea, []int{1}
+}
+
+func (m *GetUserReq) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *GetUserReq) GetVerbose() bool {
+	if m != nil {
+		return m.Verbose
+	}
+	return false
+}
+
+type UpdateUserReq struct {
+	Id   string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	User *User  `protobuf:"bytes,2,opt,name=user,proto3" json:"user,omitempty"`
+}
+
+func (m *UpdateUserReq) Reset() {
+	*m = UpdateUserReq{}
+}
+
+func (m *UpdateUserReq) String() string {
+	return proto.CompactTextString(m)
+}
+
+func (m *UpdateUserReq) ProtoMessage() {
+}
+
+func (m *UpdateUserReq) Descriptor() ([]byte, []int) {
+	return xserviceFileDescriptor_030765f334c86cea, []int{2}
+}
+
+func (m *UpdateUserReq) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *UpdateUserReq) GetUser() *User {
+	if m != nil {
+		return m.User
+	}
+	return nil
+}
+
+// usersJSONClient wraps an http.client and sends JSON objects
+type usersJSONClient struct {
+	client  transport.HTTPClient
+	urls    [4]string
+	options *transport.ClientOptions
+}
+
+// GetUser sends an GetUserReq JSON object to the server
+func (c *usersJSONClient) GetUser(ctx context.Context, in *GetUserReq) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "GetUser")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := c.options.DoJSONRequest(ctx, c.client, c.urls[0], req.(*GetUserReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// UpdateUser sends an UpdateUserReq JSON object to the server
+func (c *usersJSONClient) UpdateUser(ctx context.Context, in *UpdateUserReq) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "UpdateUser")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := c.options.DoJSONRequest(ctx, c.client, c.urls[1], req.(*UpdateUserReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// CreateUser sends an User JSON object to the server
+func (c *usersJSONClient) CreateUser(ctx context.Context, in *User) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "CreateUser")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := c.options.DoJSONRequest(ctx, c.client, c.urls[2], req.(*User), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// Ping sends an User JSON object to the server
+func (c *usersJSONClient) Ping(ctx context.Context, in *User) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "Ping")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := c.options.DoJSONRequest(ctx, c.client, c.urls[3], req.(*User), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// usersProtobufferClient wraps an http.client and sends Protobuffer objects
+type usersProtobufferClient struct {
+	client  transport.HTTPClient
+	urls    [4]string
+	options *transport.ClientOptions
+}
+
+// GetUser sends an GetUserReq Protobuffer object to the server
+func (c *usersProtobufferClient) GetUser(ctx context.Context, in *GetUserReq) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "GetUser")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := c.options.DoProtobufferRequest(ctx, c.client, c.urls[0], req.(*GetUserReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// UpdateUser sends an UpdateUserReq Protobuffer object to the server
+func (c *usersProtobufferClient) UpdateUser(ctx context.Context, in *UpdateUserReq) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "UpdateUser")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := c.options.DoProtobufferRequest(ctx, c.client, c.urls[1], req.(*UpdateUserReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// CreateUser sends an User Protobuffer object to the server
+func (c *usersProtobufferClient) CreateUser(ctx context.Context, in *User) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "CreateUser")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := c.options.DoProtobufferRequest(ctx, c.client, c.urls[2], req.(*User), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// Ping sends an User Protobuffer object to the server
+func (c *usersProtobufferClient) Ping(ctx context.Context, in *User) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "Ping")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := c.options.DoProtobufferRequest(ctx, c.client, c.urls[3], req.(*User), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// usersLocalClient calls an implementation of Users in-process, with the hooks and interceptors of a server
+type usersLocalClient struct {
+	svc     Users
+	options *transport.ServerOptions
+}
+
+// GetUser calls GetUser of the service in-process
+func (c *usersLocalClient) GetUser(ctx context.Context, in *GetUserReq) (*User, error) {
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out, err := c.svc.GetUser(ctx, req.(*GetUserReq))
+		return out, err
+	}
+	resp, err := c.options.CallLocal(ctx, "example.users", "Users", "GetUser", in, call)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// UpdateUser calls UpdateUser of the service in-process
+func (c *usersLocalClient) UpdateUser(ctx context.Context, in *UpdateUserReq) (*User, error) {
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out, err := c.svc.UpdateUser(ctx, req.(*UpdateUserReq))
+		return out, err
+	}
+	resp, err := c.options.CallLocal(ctx, "example.users", "Users", "UpdateUser", in, call)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// CreateUser calls CreateUser of the service in-process
+func (c *usersLocalClient) CreateUser(ctx context.Context, in *User) (*User, error) {
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out, err := c.svc.CreateUser(ctx, req.(*User))
+		return out, err
+	}
+	resp, err := c.options.CallLocal(ctx, "example.users", "Users", "CreateUser", in, call)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// Ping calls Ping of the service in-process
+func (c *usersLocalClient) Ping(ctx context.Context, in *User) (*User, error) {
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out, err := c.svc.Ping(ctx, req.(*User))
+		return out, err
+	}
+	resp, err := c.options.CallLocal(ctx, "example.users", "Users", "Ping", in, call)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// usersRESTClient wraps an http.client and calls the RESTful routes of the server
+type usersRESTClient struct {
+	client  transport.HTTPClient
+	urls    [4]string
+	options *transport.ClientOptions
+	addr    string
+}
+
+// GetUser sends an GetUserReq object to GET /v1/users/{id}
+func (c *usersRESTClient) GetUser(ctx context.Context, in *GetUserReq) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "GetUser")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := rest.DoRequest(ctx, c.options, c.client, c.addr, rest.Rule{Method: "GET", Pattern: "/v1/users/{id}"}, req.(*GetUserReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// UpdateUser sends an UpdateUserReq object to PATCH /v1/users/{id}
+func (c *usersRESTClient) UpdateUser(ctx context.Context, in *UpdateUserReq) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "UpdateUser")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := rest.DoRequest(ctx, c.options, c.client, c.addr, rest.Rule{Method: "PATCH", Pattern: "/v1/users/{id}", Body: "user"}, req.(*UpdateUserReq), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// CreateUser sends an User object to POST /v1/users
+func (c *usersRESTClient) CreateUser(ctx context.Context, in *User) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "CreateUser")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := rest.DoRequest(ctx, c.options, c.client, c.addr, rest.Rule{Method: "POST", Pattern: "/v1/users", Body: "*"}, req.(*User), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// Ping sends an User object to the server
+func (c *usersRESTClient) Ping(ctx context.Context, in *User) (*User, error) {
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithMethodName(ctx, "Ping")
+	call := func(ctx context.Context, req interface{}) (interface{}, error) {
+		out := new(User)
+		err := c.options.DoJSONRequest(ctx, c.client, c.urls[3], req.(*User), out)
+		return out, err
+	}
+	resp, err := c.options.Intercept(call)(ctx, in)
+	out, _ := resp.(*User)
+	return out, err
+}
+
+// usersServer wraps an endpoint and implements http.Handler.
+type usersServer struct {
+	Users
+	hooks        *hooks.ServerHooks
+	logErrorFunc transport.LogErrorFunc
+	options      *transport.ServerOptions
+	prefix       string
+}
+
+func (s *usersServer) writeError(ctx context.Context, resp http.ResponseWriter, err error) {
+	s.options.WriteError(ctx, resp, err, s.hooks)
+}
+
+// ServeHTTP implements http.Handler.
+func (s *usersServer) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	resp = transport.NewResponseWriter(resp)
+	ctx := req.Context()
+	ctx = xcontext.WithPackageName(ctx, "example.users")
+	ctx = xcontext.WithServiceName(ctx, "Users")
+	ctx = xcontext.WithResponseWriter(ctx, resp)
+	ctx = xcontext.WithHTTPRequest(ctx, req)
+	s.options.LimitRequestBody(req)
+	recoverWrapper := func() {
+		if r := recover(); r != nil {
+			s.options.HandlePanic(ctx, resp, r, s.hooks)
+		}
+	}
+	defer recoverWrapper()
+
+	var err error
+	ctx, err = transport.CallRequestReceived(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	err = s.options.CheckVersion(resp, req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if route, values, ok := usersRESTRouter.Match(req); ok == true {
+		s.serveREST(ctx, resp, req, route, values)
+		return
+	}
+	err = s.options.CheckMethod(req, false)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+
+	switch req.URL.Path {
+	case s.prefix + "GetUser":
+		s.serveGetUser(ctx, resp, req)
+		return
+	case s.prefix + "UpdateUser":
+		s.serveUpdateUser(ctx, resp, req)
+		return
+	case s.prefix + "CreateUser":
+		s.serveCreateUser(ctx, resp, req)
+		return
+	case s.prefix + "Ping":
+		s.servePing(ctx, resp, req)
+		return
+
+	default:
+		msg := fmt.Sprintf("no handler for path %q", req.URL.Path)
+		terr := errors.BadRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+
+}
+
+// serveREST dispatches a request matched by a RESTful route to its method
+func (s *usersServer) serveREST(ctx context.Context, resp http.ResponseWriter, req *http.Request, route *rest.Route, values map[string]string) {
+
+	switch route.Name {
+	case "GetUser":
+		s.serveGetUserContent(ctx, resp, req, rest.NewRequestDecoder(route, values), s.options.EncodeJSONResponse)
+		return
+	case "UpdateUser":
+		s.serveUpdateUserContent(ctx, resp, req, rest.NewRequestDecoder(route, values), s.options.EncodeJSONResponse)
+		return
+	case "CreateUser":
+		s.serveCreateUserContent(ctx, resp, req, rest.NewRequestDecoder(route, values), s.options.EncodeJSONResponse)
+		return
+
+	default:
+		msg := fmt.Sprintf("no handler for route %q", route.Name)
+		terr := errors.BadRouteError(msg, req.Method, req.URL.Path)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+
+}
+
+// serveGetUser is used to set an decoder and encoder for a given content type
+func (s *usersServer) serveGetUser(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	codec, err := s.options.RequestCodec(req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	respCodec, err := s.options.ResponseCodec(req, codec)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	ctx = xcontext.WithResponseContentType(ctx, respCodec.ContentType())
+	s.serveGetUserContent(ctx, resp, req, transport.DecodeRequestWith(codec), transport.EncodeResponseWith(respCodec))
+}
+
+// serveGetUserContent sends object to requester
+func (s *usersServer) serveGetUserContent(ctx context.Context, resp http.ResponseWriter, req *http.Request, decodeRequest transport.DecodeRequestFunc, encodeResponse transport.EncodeResponseFunc) {
+	var err error
+	ctx = xcontext.WithMethodName(ctx, "GetUser")
+	ctx, err = transport.CallRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	defer transport.Closebody(req.Body, s.logErrorFunc)
+
+	reqContent := new(GetUserReq)
+	if err := decodeRequest(ctx, req, reqContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	endpointWrapper := func() (respContent *User, err error) {
+		deferWrapper := func() {
+			if r := recover(); r != nil {
+				err = s.options.RecoverPanic(ctx, r)
+			}
+		}
+		defer deferWrapper()
+
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			out, err := s.GetUser(ctx, req.(*GetUserReq))
+			return out, err
+		}
+		out, callErr := s.options.Intercept(call)(ctx, reqContent)
+		content, _ := out.(*User)
+		return content, callErr
+	}
+	respContent, err := endpointWrapper()
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		terr := errors.InternalError("received a nil * User, and nil error while calling GetUser. nil responses are not supported")
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+	ctx = transport.CallResponsePrepared(ctx, s.hooks)
+	if err := encodeResponse(ctx, resp, respContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	transport.CallResponseSent(ctx, s.hooks)
+}
+
+// serveUpdateUser is used to set an decoder and encoder for a given content type
+func (s *usersServer) serveUpdateUser(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	codec, err := s.options.RequestCodec(req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	respCodec, err := s.options.ResponseCodec(req, codec)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	ctx = xcontext.WithResponseContentType(ctx, respCodec.ContentType())
+	s.serveUpdateUserContent(ctx, resp, req, transport.DecodeRequestWith(codec), transport.EncodeResponseWith(respCodec))
+}
+
+// serveUpdateUserContent sends object to requester
+func (s *usersServer) serveUpdateUserContent(ctx context.Context, resp http.ResponseWriter, req *http.Request, decodeRequest transport.DecodeRequestFunc, encodeResponse transport.EncodeResponseFunc) {
+	var err error
+	ctx = xcontext.WithMethodName(ctx, "UpdateUser")
+	ctx, err = transport.CallRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	defer transport.Closebody(req.Body, s.logErrorFunc)
+
+	reqContent := new(UpdateUserReq)
+	if err := decodeRequest(ctx, req, reqContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	endpointWrapper := func() (respContent *User, err error) {
+		deferWrapper := func() {
+			if r := recover(); r != nil {
+				err = s.options.RecoverPanic(ctx, r)
+			}
+		}
+		defer deferWrapper()
+
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			out, err := s.UpdateUser(ctx, req.(*UpdateUserReq))
+			return out, err
+		}
+		out, callErr := s.options.Intercept(call)(ctx, reqContent)
+		content, _ := out.(*User)
+		return content, callErr
+	}
+	respContent, err := endpointWrapper()
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		terr := errors.InternalError("received a nil * User, and nil error while calling UpdateUser. nil responses are not supported")
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+	ctx = transport.CallResponsePrepared(ctx, s.hooks)
+	if err := encodeResponse(ctx, resp, respContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	transport.CallResponseSent(ctx, s.hooks)
+}
+
+// serveCreateUser is used to set an decoder and encoder for a given content type
+func (s *usersServer) serveCreateUser(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	codec, err := s.options.RequestCodec(req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	respCodec, err := s.options.ResponseCodec(req, codec)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	ctx = xcontext.WithResponseContentType(ctx, respCodec.ContentType())
+	s.serveCreateUserContent(ctx, resp, req, transport.DecodeRequestWith(codec), transport.EncodeResponseWith(respCodec))
+}
+
+// serveCreateUserContent sends object to requester
+func (s *usersServer) serveCreateUserContent(ctx context.Context, resp http.ResponseWriter, req *http.Request, decodeRequest transport.DecodeRequestFunc, encodeResponse transport.EncodeResponseFunc) {
+	var err error
+	ctx = xcontext.WithMethodName(ctx, "CreateUser")
+	ctx, err = transport.CallRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	defer transport.Closebody(req.Body, s.logErrorFunc)
+
+	reqContent := new(User)
+	if err := decodeRequest(ctx, req, reqContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	endpointWrapper := func() (respContent *User, err error) {
+		deferWrapper := func() {
+			if r := recover(); r != nil {
+				err = s.options.RecoverPanic(ctx, r)
+			}
+		}
+		defer deferWrapper()
+
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			out, err := s.CreateUser(ctx, req.(*User))
+			return out, err
+		}
+		out, callErr := s.options.Intercept(call)(ctx, reqContent)
+		content, _ := out.(*User)
+		return content, callErr
+	}
+	respContent, err := endpointWrapper()
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		terr := errors.InternalError("received a nil * User, and nil error while calling CreateUser. nil responses are not supported")
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+	ctx = transport.CallResponsePrepared(ctx, s.hooks)
+	if err := encodeResponse(ctx, resp, respContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	transport.CallResponseSent(ctx, s.hooks)
+}
+
+// servePing is used to set an decoder and encoder for a given content type
+func (s *usersServer) servePing(ctx context.Context, resp http.ResponseWriter, req *http.Request) {
+	codec, err := s.options.RequestCodec(req)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	respCodec, err := s.options.ResponseCodec(req, codec)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	ctx = xcontext.WithResponseContentType(ctx, respCodec.ContentType())
+	s.servePingContent(ctx, resp, req, transport.DecodeRequestWith(codec), transport.EncodeResponseWith(respCodec))
+}
+
+// servePingContent sends object to requester
+func (s *usersServer) servePingContent(ctx context.Context, resp http.ResponseWriter, req *http.Request, decodeRequest transport.DecodeRequestFunc, encodeResponse transport.EncodeResponseFunc) {
+	var err error
+	ctx = xcontext.WithMethodName(ctx, "Ping")
+	ctx, err = transport.CallRequestRouted(ctx, s.hooks)
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	defer transport.Closebody(req.Body, s.logErrorFunc)
+
+	reqContent := new(User)
+	if err := decodeRequest(ctx, req, reqContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	endpointWrapper := func() (respContent *User, err error) {
+		deferWrapper := func() {
+			if r := recover(); r != nil {
+				err = s.options.RecoverPanic(ctx, r)
+			}
+		}
+		defer deferWrapper()
+
+		call := func(ctx context.Context, req interface{}) (interface{}, error) {
+			out, err := s.Ping(ctx, req.(*User))
+			return out, err
+		}
+		out, callErr := s.options.Intercept(call)(ctx, reqContent)
+		content, _ := out.(*User)
+		return content, callErr
+	}
+	respContent, err := endpointWrapper()
+	if err != nil {
+		s.writeError(ctx, resp, err)
+		return
+	}
+	if respContent == nil {
+		terr := errors.InternalError("received a nil * User, and nil error while calling Ping. nil responses are not supported")
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, terr)
+		return
+	}
+	ctx = transport.CallResponsePrepared(ctx, s.hooks)
+	if err := encodeResponse(ctx, resp, respContent); err != nil {
+		s.logErrorFunc("%v", err)
+		s.writeError(ctx, resp, err)
+		return
+	}
+	transport.CallResponseSent(ctx, s.hooks)
+}
+
+// ServiceDescriptor describes an service.
+func (s *usersServer) ServiceDescriptor() ([]uint8, int) {
+	return xserviceFileDescriptor_030765f334c86cea, 0
+}
+
+// ProtocGenXServiceVersion returns which xservice version was used to generate that service
+func (s *usersServer) ProtocGenXServiceVersion() string {
+	return "v0.1.0"
+}
+
+// NewUsersJSONClient constructs a new client, which wraps the http.client and implements Users
+func NewUsersJSONClient(addr string, client transport.HTTPClient) Users {
+	return NewUsersJSONClientWithOptions(addr, client)
+}
+
+// NewUsersJSONClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements Users
+func NewUsersJSONClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) Users {
+	options := transport.NewClientOptions(opts...)
+	URLBase := transport.UrlBase(addr)
+	prefix := URLBase + options.ServicePathPrefix(UsersPathPrefix, "example.users.Users")
+	urls := [4]string{
+		prefix + "GetUser",
+		prefix + "UpdateUser",
+		prefix + "CreateUser",
+		prefix + "Ping",
+	}
+	return &usersJSONClient{
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
+	}
+}
+
+// NewUsersProtobufferClient constructs a new client, which wraps the http.client and implements Users
+func NewUsersProtobufferClient(addr string, client transport.HTTPClient) Users {
+	return NewUsersProtobufferClientWithOptions(addr, client)
+}
+
+// NewUsersProtobufferClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements Users
+func NewUsersProtobufferClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) Users {
+	options := transport.NewClientOptions(opts...)
+	URLBase := transport.UrlBase(addr)
+	prefix := URLBase + options.ServicePathPrefix(UsersPathPrefix, "example.users.Users")
+	urls := [4]string{
+		prefix + "GetUser",
+		prefix + "UpdateUser",
+		prefix + "CreateUser",
+		prefix + "Ping",
+	}
+	return &usersProtobufferClient{
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
+	}
+}
+
+// NewUsersLocalClient constructs a client which implements Users by calling svc in-process. It calls the hooks
+// and populates the context like NewUsersServer.
+func NewUsersLocalClient(svc Users, hooks *hooks.ServerHooks) Users {
+	return NewUsersLocalClientWithOptions(svc, transport.WithServerHooks(hooks))
+}
+
+// NewUsersLocalClientWithOptions constructs a client like NewUsersLocalClient, configured by server options. With transport.WithLocalRoundTrip svc receives and
+// returns copies of the messages.
+func NewUsersLocalClientWithOptions(svc Users, opts ...transport.ServerOption) Users {
+	return &usersLocalClient{
+		svc:     svc,
+		options: transport.NewServerOptions(opts...),
+	}
+}
+
+// NewUsersRESTClient constructs a new client, which wraps the http.client and implements Users
+func NewUsersRESTClient(addr string, client transport.HTTPClient) Users {
+	return NewUsersRESTClientWithOptions(addr, client)
+}
+
+// NewUsersRESTClientWithOptions constructs a new client configured by opts, which wraps the http.client and implements Users
+func NewUsersRESTClientWithOptions(addr string, client transport.HTTPClient, opts ...transport.ClientOption) Users {
+	options := transport.NewClientOptions(opts...)
+	URLBase := transport.UrlBase(addr)
+	prefix := URLBase + options.ServicePathPrefix(UsersPathPrefix, "example.users.Users")
+	urls := [4]string{
+		prefix + "GetUser",
+		prefix + "UpdateUser",
+		prefix + "CreateUser",
+		prefix + "Ping",
+	}
+	return &usersRESTClient{
+		client:  options.HTTPClient(client),
+		urls:    urls,
+		options: options,
+		addr:    URLBase,
+	}
+}
+
+// NewUsersServer constructs a new server, and implements Users
+func NewUsersServer(svc Users, hooks *hooks.ServerHooks, errorFunc ...transport.LogErrorFunc) server.Server {
+	opts := make([]transport.ServerOption, 0, 2)
+	opts = append(opts, transport.WithServerHooks(hooks))
+	if len(errorFunc) == 1 {
+		opts = append(opts, transport.WithServerLogger(errorFunc[0]))
+	}
+	return NewUsersServerWithOptions(svc, opts...)
+}
+
+// NewUsersServerWithOptions constructs a new server configured by opts, and implements Users
+func NewUsersServerWithOptions(svc Users, opts ...transport.ServerOption) server.Server {
+	options := transport.NewServerOptions(opts...)
+	return &usersServer{
+		Users:        svc,
+		hooks:        options.Hooks(),
+		logErrorFunc: options.Logger(),
+		options:      options,
+		prefix:       options.ServicePathPrefix(UsersPathPrefix, "example.users.Users"),
+	}
+}
+func init() {
+	server.RegisterServiceDescriptor("example.users.Users", xserviceFileDescriptor_030765f334c86cea, 0)
+	proto.RegisterType((*User)(nil), "example.users.User")
+	proto.RegisterType((*GetUserReq)(nil), "example.users.GetUserReq")
+	proto.RegisterType((*UpdateUserReq)(nil), "example.users.UpdateUserReq")
+}
@@ -19,7 +19,7 @@ import "fmt"
 const commentTpl string = `
 {{if .Comment }}
 	{{range $i, $line := .Comment}}
-             // {{- $line -}}
+             //{{if $line}} {{$line}}{{end -}}
 	{{end}}
 {{end}}
 `
@@ -48,7 +48,7 @@ func (gen *CommentGenerator) P(s string) {
 }
 
 func (gen *CommentGenerator) Pf(format string, a ...interface{}) {
-	gen.CommentMetaData.Comment = append(gen.CommentMetaData.Comment, fmt.Sprintf(format, a))
+	gen.CommentMetaData.Comment = append(gen.CommentMetaData.Comment, fmt.Sprintf(format, a...))
 }
 
 func (gen *CommentGenerator) Render() (string, error) {
@@ -15,7 +15,7 @@ package types
 
 // Template of const
 const constTpl string = `
-{{ if .Comment }} // {{ .Comment }} {{ end }}
+{{ if .Comment }}{{ .Comment }}{{ end }}
 const {{ .Name }} {{ .Typ }} = {{ .Value }}`
 const constTplName string = "const"
 
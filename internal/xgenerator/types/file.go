@@ -167,6 +167,13 @@ func (gen *FileGenerator) Import(alias string, Import string) error {
 		Alias:      alias,
 	}
 
+	// Imports are only declared once.
+	for _, imported := range gen.FileMetaData.Imports {
+		if imported == i {
+			return nil
+		}
+	}
+
 	gen.FileMetaData.Imports = append(gen.FileMetaData.Imports, i)
 	return nil
 }
@@ -297,7 +304,7 @@ func (gen *FileGenerator) prepareFileName(fileName string) string {
 	if len(filePathParts) > 1 {
 		fileName = GoFileName(filePathParts[len(filePathParts)-1])
 		filePathParts[len(filePathParts)-1] = fileName
-		return string(os.PathSeparator) + filepath.Join(filePathParts...)
+		return filepath.Join(filePathParts...)
 	}
 	fileName = GoFileName(fileName)
 	return fileName
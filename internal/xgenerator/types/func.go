@@ -21,7 +21,7 @@ type FuncGeneratorMetaData struct {
 	MethodOfTyp string
 	TypShortcut string
 	Fnc         string
-	Comment     string
+	Comment     []string
 }
 
 type FuncGenerator struct {
@@ -32,7 +32,11 @@ type FuncGenerator struct {
 
 const funcTplName = "func"
 const funcTpl string = `
-{{ if .Comment }} // {{ .Comment }} {{ end }}
+{{if .Comment }}
+{{range $i, $line := .Comment}}
+//{{if $line}} {{$line}}{{end -}}
+{{end}}
+{{- end}}
 func {{ .Name }} ({{ .Params }}) {{if .Returns }} ({{- .Returns }}) {{end}} {
 {{range $i, $line := .Lines}}
 	{{- $line | safe }}
@@ -56,15 +60,18 @@ func NewGoFunc(name string, parameters []*Parameter, returns []TypeReference, co
 	}
 
 	gen.TypeFuncMetadata = FuncGeneratorMetaData{
-		Name:    Identifier(name),
-		Params:  paramList(parameters),
-		Comment: comment,
+		Name:   Identifier(name),
+		Params: paramList(parameters),
 	}
 
 	if len(returns) > 0 {
 		gen.TypeFuncMetadata.Returns = typeList(returns)
 	}
 
+	if comment != "" {
+		gen.TypeFuncMetadata.Comment = gen.PrepareComment(comment)
+	}
+
 	gen.TplName = funcTplName
 	gen.InitTemplate(funcTpl)
 	return gen, nil
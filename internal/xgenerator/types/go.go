@@ -120,24 +120,16 @@ func (gen *GoGenerator) populateBuffer(metaData interface{}) (*bytes.Buffer, err
 	return buff, nil
 }
 
+// PrepareComment splits a comment into lines of at most 15 words. The line
+// breaks of the comment are kept, so blank lines separate paragraphs.
 func (gen *GoGenerator) PrepareComment(comment string) []string {
 	lines := make([]string, 0)
-	words := strings.Fields(comment)
-	tmp := words
-	if len(tmp) > 15 {
-		for {
-			if len(tmp) == 0 {
-				break
-			}
-			if len(tmp) > 15 {
-				lines = append(lines, strings.Join(tmp[:15], " "))
-				tmp = tmp[15:]
-			} else {
-				lines = append(lines, strings.Join(tmp, " "))
-				tmp = make([]string, 0)
-			}
+	for _, line := range strings.Split(strings.TrimSpace(comment), "\n") {
+		words := strings.Fields(line)
+		for len(words) > 15 {
+			lines = append(lines, strings.Join(words[:15], " "))
+			words = words[15:]
 		}
-	} else {
 		lines = append(lines, strings.Join(words, " "))
 	}
 	return lines
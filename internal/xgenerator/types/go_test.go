@@ -102,3 +102,37 @@ func TestGoGenerator_RenderAllPanic(t *testing.T) {
 		})
 	}
 }
+
+func TestGoGenerator_PrepareComment(t *testing.T) {
+	gen := &GoGenerator{}
+
+	tests := []struct {
+		name   string
+		input  string
+		output []string
+	}{
+		{
+			name:   "one line",
+			input:  "Get returns an order.",
+			output: []string{"Get returns an order."},
+		},
+		{
+			name:   "long line",
+			input:  "one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen sixteen",
+			output: []string{"one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen", "sixteen"},
+		},
+		{
+			name:   "paragraphs",
+			input:  " Find returns an order.\n\n Deprecated: Do not use.\n",
+			output: []string{"Find returns an order.", "", "Deprecated: Do not use."},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lines := gen.PrepareComment(test.input)
+			if strings.Join(lines, "\n") != strings.Join(test.output, "\n") {
+				t.Errorf(`unexpected lines (actual: %q, expected: %q)`, lines, test.output)
+			}
+		})
+	}
+}
@@ -16,13 +16,13 @@ package types
 const interfaceNameTpl string = "interface"
 
 const interfaceTpl string = `
-{{ if .HeaderComment }} // {{.HeaderComment }} {{end}}
-
+{{ if .HeaderComment }}{{.HeaderComment }}
+{{end -}}
 type {{ .Name }} interface {
 {{range $i, $Prototype := .Prototypes}}
 			{{if index $Prototype.Comment }}
 				{{range $i, $line := index $Prototype.Comment }}
-				// {{ index $line }}
+				//{{if $line}} {{$line}}{{end}}
 				{{- end}}
 			{{- end}}
             {{ index $Prototype.Name }}({{- index $Prototype.Params }}) {{if index $Prototype.Returns }} ({{- index $Prototype.Returns }}) {{end}}
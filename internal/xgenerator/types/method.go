@@ -34,7 +34,7 @@ const methodTplName string = "method"
 const methodTpl string = `
 {{if .Comment }}
 {{range $i, $line := .Comment}}
-// {{index $line -}}
+//{{if $line}} {{$line}}{{end -}}
 {{end}}
 {{- end}}
 func ({{ .TypShortcut }} {{ .MethodOfTyp }}) {{ .Name }}({{ .Params }}) {{if .Returns }} ({{- .Returns }}) {{end}} {
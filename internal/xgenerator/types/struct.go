@@ -138,6 +138,16 @@ func (gen *StructGenerator) AddExportedField(name string, typ TypeReference, com
 	return nil
 }
 
+// AddExportedFieldWithTag adds an exported field with a struct tag, e.g.
+// `json:"name,omitempty"`. The tag is rendered as is.
+func (gen *StructGenerator) AddExportedFieldWithTag(name string, typ TypeReference, tag string, comment string) error {
+	if err := gen.AddExportedField(name, typ, comment); err != nil {
+		return err
+	}
+	gen.StructMetaData.Fields[len(gen.StructMetaData.Fields)-1].TagMeta = tag
+	return nil
+}
+
 func (gen *StructGenerator) Composition(typ TypeReference) error {
 
 	if typ == nil {
@@ -42,3 +42,26 @@ func TestNewGoStructAndRender(t *testing.T) {
 		return
 	}
 }
+
+func TestGoStructWithTags(t *testing.T) {
+
+	structGenerator, err := types.NewGoStruct("HelloReq", true, true)
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	structGenerator.AddExportedFieldWithTag("Subject", types.String, "`json:\"subject,omitempty\"`", "")
+
+	renderedStruct, err := structGenerator.Render()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+
+	expectedStruct := "type HelloReq struct {\n\tSubject string `json:\"subject,omitempty\"`\n}"
+	if expectedStruct != renderedStruct {
+		t.Errorf(`Unexpected struct definition (Actual: "%s", Expected: "%s")`, renderedStruct, expectedStruct)
+		return
+	}
+}
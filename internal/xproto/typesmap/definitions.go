@@ -0,0 +1,179 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package typemap
+
+import (
+	"strings"
+
+	"github.com/donutloop/xservice/internal/xgenerator/types"
+	"github.com/golang/protobuf/protoc-gen-go/descriptor"
+)
+
+// EnumDefinition is an enum defined at the top level of a file or nested in
+// a message.
+type EnumDefinition struct {
+	// Descriptor is the EnumDescriptorProto defining the enum.
+	Descriptor *descriptor.EnumDescriptorProto
+	// File is the File that the enum was defined in. Or, if it has been
+	// publicly imported, what File was that import performed in?
+	File *descriptor.FileDescriptorProto
+	// Parent is the message the enum is nested in, or nil for top-level enums.
+	Parent *MessageDefinition
+	// Comments describes the comments surrounding the enum's definition.
+	Comments DefinitionComments
+	// Values are the values of the enum, in the order of their declaration.
+	Values []*EnumValueDefinition
+
+	path   []int32
+	source *descriptor.FileDescriptorProto
+}
+
+// ProtoName returns the dot-delimited, fully-qualified protobuf name of the
+// enum.
+func (e *EnumDefinition) ProtoName() string {
+	return scopeName(e.File, e.Parent) + "." + e.Descriptor.GetName()
+}
+
+// GoName returns the name of the enum's Go type like protoc-gen-go, e.g.
+// "Item_Kind", without a package prefix.
+func (e *EnumDefinition) GoName() string {
+	return goName(lineage(e.Parent), e.Descriptor.GetName())
+}
+
+// EnumValueDefinition is a value of an enum.
+type EnumValueDefinition struct {
+	// Descriptor is the EnumValueDescriptorProto defining the value.
+	Descriptor *descriptor.EnumValueDescriptorProto
+	// Enum is the enum the value belongs to.
+	Enum *EnumDefinition
+	// Comments describes the comments surrounding the value's definition.
+	Comments DefinitionComments
+
+	path []int32
+}
+
+// ProtoName returns the dot-delimited, fully-qualified protobuf name of the
+// value. Enum values are siblings of their enum, so the name of the enum
+// isn't part of it.
+func (v *EnumValueDefinition) ProtoName() string {
+	return scopeName(v.Enum.File, v.Enum.Parent) + "." + v.Descriptor.GetName()
+}
+
+// GoName returns the name of the value's Go constant like protoc-gen-go. The
+// constants of nested enums are prefixed by the name of the parent message,
+// those of top-level enums by the name of the enum, e.g. "Item_SMALL" or
+// "Color_RED".
+func (v *EnumValueDefinition) GoName() string {
+	if v.Enum.Parent != nil {
+		return v.Enum.Parent.GoName() + "_" + v.Descriptor.GetName()
+	}
+	return v.Enum.GoName() + "_" + v.Descriptor.GetName()
+}
+
+// FieldDefinition is a field of a message, or an extension.
+type FieldDefinition struct {
+	// Descriptor is the FieldDescriptorProto defining the field.
+	Descriptor *descriptor.FieldDescriptorProto
+	// File is the File that the field was defined in. Or, if it has been
+	// publicly imported, what File was that import performed in?
+	File *descriptor.FileDescriptorProto
+	// Message is the message the field belongs to. For extensions it's the
+	// message the extension was declared in, or nil if it was declared at
+	// the top level.
+	Message *MessageDefinition
+	// Oneof is the oneof the field is a member of, if any.
+	Oneof *OneofDefinition
+	// Comments describes the comments surrounding the field's definition.
+	Comments DefinitionComments
+
+	path   []int32
+	source *descriptor.FileDescriptorProto
+}
+
+// ProtoName returns the dot-delimited, fully-qualified protobuf name of the
+// field.
+func (f *FieldDefinition) ProtoName() string {
+	return scopeName(f.File, f.Message) + "." + f.Descriptor.GetName()
+}
+
+// IsExtension reports whether the field extends another message.
+func (f *FieldDefinition) IsExtension() bool {
+	return f.Descriptor.Extendee != nil
+}
+
+// GoName returns the name of the field's Go struct field like protoc-gen-go,
+// e.g. "CreatedAt". Extensions are named like their Go variable, e.g.
+// "E_ItemNote".
+func (f *FieldDefinition) GoName() string {
+	if f.IsExtension() {
+		return "E_" + goName(lineage(f.Message), f.Descriptor.GetName())
+	}
+	return types.CamelCase(f.Descriptor.GetName())
+}
+
+// OneofDefinition is a oneof of a message.
+type OneofDefinition struct {
+	// Descriptor is the OneofDescriptorProto defining the oneof.
+	Descriptor *descriptor.OneofDescriptorProto
+	// Message is the message the oneof belongs to.
+	Message *MessageDefinition
+	// Fields are the members of the oneof.
+	Fields []*FieldDefinition
+	// Comments describes the comments surrounding the oneof's definition.
+	Comments DefinitionComments
+
+	path []int32
+}
+
+// ProtoName returns the dot-delimited, fully-qualified protobuf name of the
+// oneof.
+func (o *OneofDefinition) ProtoName() string {
+	return o.Message.ProtoName() + "." + o.Descriptor.GetName()
+}
+
+// GoName returns the name of the oneof's Go struct field like protoc-gen-go,
+// e.g. "Price".
+func (o *OneofDefinition) GoName() string {
+	return types.CamelCase(o.Descriptor.GetName())
+}
+
+// scopeName returns the fully-qualified protobuf name of the scope of a
+// definition: its parent message, or the package of its file.
+func scopeName(file *descriptor.FileDescriptorProto, parent *MessageDefinition) string {
+	if parent != nil {
+		return parent.ProtoName()
+	}
+	if pkg := file.GetPackage(); pkg != "" {
+		return "." + pkg
+	}
+	return ""
+}
+
+// lineage returns m and its parents, the highest-level parent first.
+func lineage(m *MessageDefinition) []*MessageDefinition {
+	if m == nil {
+		return nil
+	}
+	return append(m.Lineage(), m)
+}
+
+// goName joins the names of nested definitions like protoc-gen-go, e.g.
+// "Outer_Inner".
+func goName(parents []*MessageDefinition, name string) string {
+	names := make([]string, 0, len(parents)+1)
+	for _, p := range parents {
+		names = append(names, p.Descriptor.GetName())
+	}
+	return types.CamelCase(strings.Join(append(names, name), "_"))
+}
@@ -27,14 +27,24 @@ type Registry struct {
 	filesByName map[string]*descriptor.FileDescriptorProto
 
 	// Mapping of fully-qualified names to their definitions
-	messagesByProtoName map[string]*MessageDefinition
+	messagesByProtoName   map[string]*MessageDefinition
+	enumsByProtoName      map[string]*EnumDefinition
+	enumValuesByProtoName map[string]*EnumValueDefinition
+	fieldsByProtoName     map[string]*FieldDefinition
+	oneofsByProtoName     map[string]*OneofDefinition
+	extensionsByProtoName map[string]*FieldDefinition
 }
 
 func New(files []*descriptor.FileDescriptorProto) *Registry {
 	r := &Registry{
-		allFiles:            files,
-		filesByName:         make(map[string]*descriptor.FileDescriptorProto),
-		messagesByProtoName: make(map[string]*MessageDefinition),
+		allFiles:              files,
+		filesByName:           make(map[string]*descriptor.FileDescriptorProto),
+		messagesByProtoName:   make(map[string]*MessageDefinition),
+		enumsByProtoName:      make(map[string]*EnumDefinition),
+		enumValuesByProtoName: make(map[string]*EnumValueDefinition),
+		fieldsByProtoName:     make(map[string]*FieldDefinition),
+		oneofsByProtoName:     make(map[string]*OneofDefinition),
+		extensionsByProtoName: make(map[string]*FieldDefinition),
 	}
 
 	// First, index the file descriptors by name. We need this so
@@ -51,6 +61,15 @@ func New(files []*descriptor.FileDescriptorProto) *Registry {
 			r.messagesByProtoName[name] = def
 		}
 	}
+
+	// Finally, index the definitions within the messages and the top-level
+	// enums and extensions of the files.
+	for _, def := range r.messagesByProtoName {
+		r.indexMessage(def)
+	}
+	for _, f := range files {
+		r.indexFile(f, f)
+	}
 	return r
 }
 
@@ -95,6 +114,138 @@ func (r *Registry) MessageDefinition(name string) *MessageDefinition {
 	return r.messagesByProtoName[name]
 }
 
+// EnumDefinition returns the enum of a fully-qualified proto name, e.g.
+// ".example.Item.Kind".
+func (r *Registry) EnumDefinition(name string) *EnumDefinition {
+	return r.enumsByProtoName[name]
+}
+
+// EnumValueDefinition returns the enum value of a fully-qualified proto name.
+// Like in protobuf, enum values are scoped by the parent of their enum, e.g.
+// ".example.Item.SMALL".
+func (r *Registry) EnumValueDefinition(name string) *EnumValueDefinition {
+	return r.enumValuesByProtoName[name]
+}
+
+// FieldDefinition returns the field of a fully-qualified proto name, e.g.
+// ".example.Item.name".
+func (r *Registry) FieldDefinition(name string) *FieldDefinition {
+	return r.fieldsByProtoName[name]
+}
+
+// OneofDefinition returns the oneof of a fully-qualified proto name, e.g.
+// ".example.Item.price".
+func (r *Registry) OneofDefinition(name string) *OneofDefinition {
+	return r.oneofsByProtoName[name]
+}
+
+// ExtensionDefinition returns the extension of a fully-qualified proto name,
+// which is scoped by the message or package it was declared in.
+func (r *Registry) ExtensionDefinition(name string) *FieldDefinition {
+	return r.extensionsByProtoName[name]
+}
+
+// indexFile indexes the top-level enums and extensions of source, and of
+// the files it imports publicly, as definitions of f.
+func (r *Registry) indexFile(f, source *descriptor.FileDescriptorProto) {
+	for i, e := range source.EnumType {
+		r.addEnum(&EnumDefinition{
+			Descriptor: e,
+			File:       f,
+			path:       []int32{enumPath, int32(i)},
+			source:     source,
+		})
+	}
+	for i, ext := range source.Extension {
+		r.addExtension(&FieldDefinition{
+			Descriptor: ext,
+			File:       f,
+			path:       []int32{extensionPath, int32(i)},
+			source:     source,
+		})
+	}
+	for _, depIdx := range source.PublicDependency {
+		if dep := r.filesByName[source.Dependency[depIdx]]; dep != nil {
+			r.indexFile(f, dep)
+		}
+	}
+}
+
+// indexMessage indexes the oneofs, fields, enums and extensions defined in m.
+func (r *Registry) indexMessage(m *MessageDefinition) {
+	m.Oneofs, m.Fields, m.Enums = nil, nil, nil
+	for i, o := range m.Descriptor.OneofDecl {
+		path := appendPath(m.path, messageOneofPath, i)
+		def := &OneofDefinition{
+			Descriptor: o,
+			Message:    m,
+			Comments:   commentsAtPath(path, m.source),
+			path:       path,
+		}
+		m.Oneofs = append(m.Oneofs, def)
+		r.oneofsByProtoName[def.ProtoName()] = def
+	}
+	for i, field := range m.Descriptor.Field {
+		path := appendPath(m.path, messageFieldPath, i)
+		def := &FieldDefinition{
+			Descriptor: field,
+			File:       m.File,
+			Message:    m,
+			Comments:   commentsAtPath(path, m.source),
+			path:       path,
+			source:     m.source,
+		}
+		if field.OneofIndex != nil && int(field.GetOneofIndex()) < len(m.Oneofs) {
+			def.Oneof = m.Oneofs[field.GetOneofIndex()]
+			def.Oneof.Fields = append(def.Oneof.Fields, def)
+		}
+		m.Fields = append(m.Fields, def)
+		r.fieldsByProtoName[def.ProtoName()] = def
+	}
+	for i, e := range m.Descriptor.EnumType {
+		def := &EnumDefinition{
+			Descriptor: e,
+			File:       m.File,
+			Parent:     m,
+			path:       appendPath(m.path, messageEnumPath, i),
+			source:     m.source,
+		}
+		m.Enums = append(m.Enums, def)
+		r.addEnum(def)
+	}
+	for i, ext := range m.Descriptor.Extension {
+		r.addExtension(&FieldDefinition{
+			Descriptor: ext,
+			File:       m.File,
+			Message:    m,
+			path:       appendPath(m.path, messageExtensionPath, i),
+			source:     m.source,
+		})
+	}
+}
+
+func (r *Registry) addEnum(e *EnumDefinition) {
+	e.Comments = commentsAtPath(e.path, e.source)
+	e.Values = nil
+	for i, v := range e.Descriptor.Value {
+		path := appendPath(e.path, enumValuePath, i)
+		def := &EnumValueDefinition{
+			Descriptor: v,
+			Enum:       e,
+			Comments:   commentsAtPath(path, e.source),
+			path:       path,
+		}
+		e.Values = append(e.Values, def)
+		r.enumValuesByProtoName[def.ProtoName()] = def
+	}
+	r.enumsByProtoName[e.ProtoName()] = e
+}
+
+func (r *Registry) addExtension(ext *FieldDefinition) {
+	ext.Comments = commentsAtPath(ext.path, ext.source)
+	r.extensionsByProtoName[ext.ProtoName()] = ext
+}
+
 type MessageDefinition struct {
 	// Descriptor is is the DescriptorProto defining the message.
 	Descriptor *descriptor.DescriptorProto
@@ -108,11 +259,18 @@ type MessageDefinition struct {
 	// was publicly imported, then these comments are from the actual source file,
 	// not the file that the import was performed in.
 	Comments DefinitionComments
+	// Fields, Oneofs and Enums are the fields, oneofs and nested enums of the
+	// message, in the order of their declaration.
+	Fields []*FieldDefinition
+	Oneofs []*OneofDefinition
+	Enums  []*EnumDefinition
 
 	// path is the 'SourceCodeInfo' path. See the documentation for
 	// github.com/golang/protobuf/protoc-gen-go/descriptor.SourceCodeInfo for an
 	// explanation of its format.
 	path []int32
+	// source is the file the message is declared in.
+	source *descriptor.FileDescriptorProto
 }
 
 // ProtoName returns the dot-delimited, fully-qualified protobuf name of the
@@ -132,6 +290,12 @@ func (m *MessageDefinition) ProtoName() string {
 	return prefix + m.Descriptor.GetName()
 }
 
+// GoName returns the name of the message's Go type like protoc-gen-go, e.g.
+// "Outer_Inner", without a package prefix.
+func (m *MessageDefinition) GoName() string {
+	return goName(m.Lineage(), m.Descriptor.GetName())
+}
+
 // Lineage returns m's parental chain all the way back up to a top-level message
 // definition. The first element of the returned slice is the highest-level
 // parent.
@@ -148,13 +312,14 @@ func (m *MessageDefinition) Lineage() []*MessageDefinition {
 func (m *MessageDefinition) descendants() []*MessageDefinition {
 	descendants := make([]*MessageDefinition, 0)
 	for i, child := range m.Descriptor.NestedType {
-		path := append(m.path, []int32{messageMessagePath, int32(i)}...)
+		path := appendPath(m.path, messageMessagePath, i)
 		childDef := &MessageDefinition{
 			Descriptor: child,
 			File:       m.File,
 			Parent:     m,
-			Comments:   commentsAtPath(path, m.File),
+			Comments:   commentsAtPath(path, m.source),
 			path:       path,
+			source:     m.source,
 		}
 		descendants = append(descendants, childDef)
 		descendants = append(descendants, childDef.descendants()...)
@@ -178,6 +343,7 @@ func messageDefsForFile(f *descriptor.FileDescriptorProto, filesByName map[strin
 			Parent:     nil,
 			Comments:   commentsAtPath(path, f),
 			path:       path,
+			source:     f,
 		}
 
 		byProtoName[def.ProtoName()] = def
@@ -197,8 +363,9 @@ func messageDefsForFile(f *descriptor.FileDescriptorProto, filesByName map[strin
 				Descriptor: def.Descriptor,
 				File:       f,
 				Parent:     def.Parent,
-				Comments:   commentsAtPath(def.path, depFile),
+				Comments:   commentsAtPath(def.path, def.source),
 				path:       def.path,
+				source:     def.source,
 			}
 			byProtoName[imported.ProtoName()] = imported
 		}
@@ -279,6 +446,12 @@ func commentsAtPath(path []int32, sourceFile *descriptor.FileDescriptorProto) De
 	return DefinitionComments{}
 }
 
+// appendPath returns a copy of path extended by the tag number of a repeated
+// field and an index.
+func appendPath(path []int32, tag int32, index int) []int32 {
+	return append(append(make([]int32, 0, len(path)+2), path...), tag, int32(index))
+}
+
 func pathEqual(path1, path2 []int32) bool {
 	if len(path1) != len(path2) {
 		return false
@@ -293,15 +466,19 @@ func pathEqual(path1, path2 []int32) bool {
 
 const (
 	// tag numbers in FileDescriptorProto
-	packagePath = 2 // package
-	messagePath = 4 // message_type
-	enumPath    = 5 // enum_type
-	servicePath = 6 // service
+	packagePath   = 2 // package
+	messagePath   = 4 // message_type
+	enumPath      = 5 // enum_type
+	servicePath   = 6 // service
+	extensionPath = 7 // extension
 	// tag numbers in DescriptorProto
-	messageFieldPath   = 2 // field
-	messageMessagePath = 3 // nested_type
-	messageEnumPath    = 4 // enum_type
-	messageOneofPath   = 8 // oneof_decl
+	messageFieldPath     = 2 // field
+	messageMessagePath   = 3 // nested_type
+	messageEnumPath      = 4 // enum_type
+	messageExtensionPath = 6 // extension
+	messageOneofPath     = 8 // oneof_decl
+	// tag numbers in EnumDescriptorProto
+	enumValuePath = 2 // value
 	// tag numbers in ServiceDescriptorProto
 	serviceNamePath    = 1 // name
 	serviceMethodPath  = 2 // method
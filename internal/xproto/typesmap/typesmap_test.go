@@ -84,3 +84,86 @@ func TestNewRegistry(t *testing.T) {
 	require.NotNil(t, method1Input)
 	assert.Equal(t, "RootMsg", method1Input.Descriptor.GetName())
 }
+
+func TestRegistryEnumsFieldsAndOneofs(t *testing.T) {
+	optional := descriptor.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+	file := &descriptor.FileDescriptorProto{
+		Name:    proto.String("item.proto"),
+		Package: proto.String("example"),
+		EnumType: []*descriptor.EnumDescriptorProto{
+			{Name: proto.String("color"), Value: []*descriptor.EnumValueDescriptorProto{
+				{Name: proto.String("RED"), Number: proto.Int32(0)},
+			}},
+		},
+		MessageType: []*descriptor.DescriptorProto{
+			{
+				Name: proto.String("Item"),
+				Field: []*descriptor.FieldDescriptorProto{
+					{Name: proto.String("created_at"), Number: proto.Int32(1), Label: optional, Type: descriptor.FieldDescriptorProto_TYPE_INT64.Enum()},
+					{Name: proto.String("cents"), Number: proto.Int32(2), Label: optional, Type: descriptor.FieldDescriptorProto_TYPE_INT64.Enum(), OneofIndex: proto.Int32(0)},
+				},
+				EnumType: []*descriptor.EnumDescriptorProto{
+					{Name: proto.String("Kind"), Value: []*descriptor.EnumValueDescriptorProto{
+						{Name: proto.String("SMALL"), Number: proto.Int32(0)},
+					}},
+				},
+				OneofDecl: []*descriptor.OneofDescriptorProto{
+					{Name: proto.String("price")},
+				},
+				Extension: []*descriptor.FieldDescriptorProto{
+					{Name: proto.String("note"), Number: proto.Int32(100), Label: optional, Type: descriptor.FieldDescriptorProto_TYPE_STRING.Enum(), Extendee: proto.String(".example.Item")},
+				},
+			},
+		},
+		SourceCodeInfo: &descriptor.SourceCodeInfo{
+			Location: []*descriptor.SourceCodeInfo_Location{
+				{Path: []int32{5, 0, 2, 0}, LeadingComments: proto.String(" red\n")},
+				{Path: []int32{4, 0, 2, 0}, TrailingComments: proto.String(" created at\n")},
+				{Path: []int32{4, 0, 8, 0}, LeadingComments: proto.String(" price\n")},
+				{Path: []int32{4, 0, 4, 0}, LeadingComments: proto.String(" kind\n")},
+			},
+		},
+	}
+
+	reg := New([]*descriptor.FileDescriptorProto{file})
+
+	color := reg.EnumDefinition(".example.color")
+	require.NotNil(t, color)
+	assert.Equal(t, "Color", color.GoName())
+	require.Len(t, color.Values, 1)
+	assert.Equal(t, "Color_RED", color.Values[0].GoName())
+	assert.Equal(t, " red\n", color.Values[0].Comments.Leading)
+	assert.Equal(t, color.Values[0], reg.EnumValueDefinition(".example.RED"))
+
+	item := reg.MessageDefinition(".example.Item")
+	require.NotNil(t, item)
+	kind := reg.EnumDefinition(".example.Item.Kind")
+	require.NotNil(t, kind)
+	assert.Equal(t, item, kind.Parent)
+	assert.Equal(t, []*EnumDefinition{kind}, item.Enums)
+	assert.Equal(t, "Item_Kind", kind.GoName())
+	assert.Equal(t, " kind\n", kind.Comments.Leading)
+	assert.Equal(t, "Item_SMALL", reg.EnumValueDefinition(".example.Item.SMALL").GoName())
+
+	createdAt := reg.FieldDefinition(".example.Item.created_at")
+	require.NotNil(t, createdAt)
+	assert.Equal(t, item, createdAt.Message)
+	assert.Nil(t, createdAt.Oneof)
+	assert.Equal(t, "CreatedAt", createdAt.GoName())
+	assert.Equal(t, " created at\n", createdAt.Comments.Trailing)
+
+	price := reg.OneofDefinition(".example.Item.price")
+	require.NotNil(t, price)
+	assert.Equal(t, "Price", price.GoName())
+	assert.Equal(t, " price\n", price.Comments.Leading)
+	cents := reg.FieldDefinition(".example.Item.cents")
+	assert.Equal(t, []*FieldDefinition{cents}, price.Fields)
+	assert.Equal(t, price, cents.Oneof)
+	assert.Equal(t, []*FieldDefinition{createdAt, cents}, item.Fields)
+
+	note := reg.ExtensionDefinition(".example.Item.note")
+	require.NotNil(t, note)
+	assert.True(t, note.IsExtension())
+	assert.Equal(t, "E_ItemNote", note.GoName())
+	assert.Nil(t, reg.FieldDefinition(".example.Item.note"))
+}
@@ -13,4 +13,6 @@
 
 package xproto
 
+// Version is the version of the generator. The version of the protocol
+// spoken by generated code is transport.ProtocolVersion.
 const Version = "v0.1.0"
@@ -0,0 +1,151 @@
+// Copyright 2018 XService, All Rights Reserved.
+//
+// Licensed under the Apache License, Version 2.0 (the "License"). You may not
+// use this file except in compliance with the License. A copy of the License is
+// located at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// or in the "license" file accompanying this file. This file is distributed on
+// an "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either
+// express or implied. See the License for the specific language governing
+// permissions and limitations under the License.
+
+package xprotoutil
+
+import (
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+	"reflect"
+)
+
+// Wire types of the protobuf encoding.
+const (
+	WireVarint  = 0
+	WireFixed64 = 1
+	WireBytes   = 2
+	WireFixed32 = 5
+)
+
+// RawField is a single occurrence of a field in the serialized form of a
+// message. Depending on WireType either Varint or Bytes is set, fixed
+// values are stored in Varint.
+type RawField struct {
+	Number   int32
+	WireType int
+	Varint   uint64
+	Bytes    []byte
+}
+
+// String returns the value of a length-delimited field as string.
+func (f RawField) String() string {
+	return string(f.Bytes)
+}
+
+// Bool returns the value of a varint field as bool.
+func (f RawField) Bool() bool {
+	return f.Varint != 0
+}
+
+// RawFields serializes msg and returns all top-level fields of it.
+//
+// The generator uses it to read custom options (extensions of the
+// descriptor option messages), because they are preserved as unknown fields
+// by all protobuf runtimes, without having to register Go types for them.
+func RawFields(msg proto.Message) ([]RawField, error) {
+	// Unset options are typed nil pointers, which can't be marshaled.
+	if msg == nil || reflect.ValueOf(msg).IsNil() {
+		return nil, nil
+	}
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRawFields(b)
+}
+
+// ParseRawFields parses the top-level fields of a serialized message.
+func ParseRawFields(b []byte) ([]RawField, error) {
+	fields := make([]RawField, 0)
+	for len(b) > 0 {
+		key, n := proto.DecodeVarint(b)
+		if n == 0 {
+			return nil, errors.New("malformed field key")
+		}
+		b = b[n:]
+
+		field := RawField{
+			Number:   int32(key >> 3),
+			WireType: int(key & 7),
+		}
+
+		switch field.WireType {
+		case WireVarint:
+			v, n := proto.DecodeVarint(b)
+			if n == 0 {
+				return nil, errors.Errorf("malformed varint of field %d", field.Number)
+			}
+			field.Varint = v
+			b = b[n:]
+		case WireFixed64:
+			if len(b) < 8 {
+				return nil, errors.Errorf("malformed fixed64 of field %d", field.Number)
+			}
+			for i := uint(0); i < 8; i++ {
+				field.Varint |= uint64(b[i]) << (8 * i)
+			}
+			b = b[8:]
+		case WireBytes:
+			l, n := proto.DecodeVarint(b)
+			if n == 0 || uint64(len(b)-n) < l {
+				return nil, errors.Errorf("malformed length-delimited field %d", field.Number)
+			}
+			field.Bytes = b[n : n+int(l)]
+			b = b[n+int(l):]
+		case WireFixed32:
+			if len(b) < 4 {
+				return nil, errors.Errorf("malformed fixed32 of field %d", field.Number)
+			}
+			for i := uint(0); i < 4; i++ {
+				field.Varint |= uint64(b[i]) << (8 * i)
+			}
+			b = b[4:]
+		default:
+			return nil, errors.Errorf("unsupported wire type %d of field %d", field.WireType, field.Number)
+		}
+
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// StringExtension returns all string values of the field with the given
+// number in msg, in the order they appear.
+func StringExtension(msg proto.Message, number int32) ([]string, error) {
+	fields, err := RawFields(msg)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, 0)
+	for _, f := range fields {
+		if f.Number == number && f.WireType == WireBytes {
+			values = append(values, f.String())
+		}
+	}
+	return values, nil
+}
+
+// BoolExtension returns the value of the bool field with the given number in
+// msg, and whether it's set. If it's set more than once, the last value wins.
+func BoolExtension(msg proto.Message, number int32) (value bool, ok bool, err error) {
+	fields, err := RawFields(msg)
+	if err != nil {
+		return false, false, err
+	}
+	for _, f := range fields {
+		if f.Number == number && f.WireType == WireVarint {
+			value, ok = f.Bool(), true
+		}
+	}
+	return value, ok, nil
+}